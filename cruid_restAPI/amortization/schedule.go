@@ -0,0 +1,124 @@
+package amortization
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	Flat      = "FLAT"
+	Effective = "EFFECTIVE"
+)
+
+type Installment struct {
+	Number    int
+	DueDate   time.Time
+	Principal float64
+	Interest  float64
+	Payment   float64
+	Balance   float64
+}
+
+type Schedule struct {
+	Method        string
+	Installments  []Installment
+	TotalInterest float64
+	TotalPayable  float64
+}
+
+func IsValidMethod(method string) bool {
+	return method == Flat || method == Effective
+}
+
+func MonthlyPayment(principal float64, annualInterestRate float64, tenureMonths int, method string) (float64, error) {
+	if err := validate(principal, annualInterestRate, tenureMonths, method); err != nil {
+		return 0, err
+	}
+
+	monthlyRate := annualInterestRate / 100 / 12
+
+	if method == Flat {
+		return Round(principal/float64(tenureMonths) + principal*monthlyRate), nil
+	}
+
+	if monthlyRate == 0 {
+		return Round(principal / float64(tenureMonths)), nil
+	}
+
+	return Round(principal * monthlyRate / (1 - math.Pow(1+monthlyRate, -float64(tenureMonths)))), nil
+}
+
+// Generate builds the month-by-month schedule of a loan. The first installment
+// is due one month after startDate, and the last one absorbs rounding so the
+// remaining balance always ends at zero.
+func Generate(principal float64, annualInterestRate float64, tenureMonths int, method string, startDate time.Time) (Schedule, error) {
+	payment, err := MonthlyPayment(principal, annualInterestRate, tenureMonths, method)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	monthlyRate := annualInterestRate / 100 / 12
+	flatInterest := Round(principal * monthlyRate)
+	balance := principal
+
+	schedule := Schedule{
+		Method:       method,
+		Installments: make([]Installment, 0, tenureMonths),
+	}
+
+	for number := 1; number <= tenureMonths; number++ {
+		var interest float64
+		if method == Flat {
+			interest = flatInterest
+		} else {
+			interest = Round(balance * monthlyRate)
+		}
+
+		principalPortion := Round(payment - interest)
+		if number == tenureMonths || principalPortion > balance {
+			principalPortion = Round(balance)
+		}
+
+		balance = Round(balance - principalPortion)
+
+		schedule.Installments = append(schedule.Installments, Installment{
+			Number:    number,
+			DueDate:   startDate.AddDate(0, number, 0),
+			Principal: principalPortion,
+			Interest:  interest,
+			Payment:   Round(principalPortion + interest),
+			Balance:   balance,
+		})
+
+		schedule.TotalInterest = Round(schedule.TotalInterest + interest)
+		schedule.TotalPayable = Round(schedule.TotalPayable + principalPortion + interest)
+	}
+
+	return schedule, nil
+}
+
+func validate(principal float64, annualInterestRate float64, tenureMonths int, method string) error {
+	if principal <= 0 {
+		return fmt.Errorf("principal must be greater than zero")
+	}
+
+	if annualInterestRate < 0 {
+		return fmt.Errorf("annual interest rate must not be negative")
+	}
+
+	if tenureMonths <= 0 {
+		return fmt.Errorf("tenure must be greater than zero")
+	}
+
+	if !IsValidMethod(method) {
+		return fmt.Errorf("unknown interest method: %s", method)
+	}
+
+	return nil
+}
+
+// Round rounds an amount to two decimal places.
+func Round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,144 @@
+package amortization
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonthlyPayment(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal float64
+		rate      float64
+		tenure    int
+		method    string
+		want      float64
+	}{
+		{"flat", 12000, 12, 12, Flat, 1120},
+		{"effective", 12000, 12, 12, Effective, 1066.19},
+		{"flat without interest", 1000, 0, 3, Flat, 333.33},
+		{"effective without interest", 1000, 0, 3, Effective, 333.33},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MonthlyPayment(tt.principal, tt.rate, tt.tenure, tt.method)
+			if err != nil || got != tt.want {
+				t.Errorf("MonthlyPayment() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMonthlyPaymentValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal float64
+		rate      float64
+		tenure    int
+		method    string
+	}{
+		{"no principal", 0, 12, 12, Flat},
+		{"negative principal", -1, 12, 12, Flat},
+		{"negative rate", 12000, -1, 12, Flat},
+		{"no tenure", 12000, 12, 0, Flat},
+		{"unknown method", 12000, 12, 12, "BALLOON"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := MonthlyPayment(tt.principal, tt.rate, tt.tenure, tt.method); err == nil {
+				t.Error("MonthlyPayment() = nil error, want an error")
+			}
+
+			if _, err := Generate(tt.principal, tt.rate, tt.tenure, tt.method, time.Now()); err == nil {
+				t.Error("Generate() = nil error, want an error")
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	startDate := time.Date(2025, time.January, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		principal     float64
+		rate          float64
+		tenure        int
+		method        string
+		first         Installment
+		last          Installment
+		totalInterest float64
+	}{
+		{
+			name:      "flat",
+			principal: 12000, rate: 12, tenure: 12, method: Flat,
+			first:         Installment{Number: 1, Principal: 1000, Interest: 120, Payment: 1120, Balance: 11000},
+			last:          Installment{Number: 12, Principal: 1000, Interest: 120, Payment: 1120, Balance: 0},
+			totalInterest: 1440,
+		},
+		{
+			name:      "effective",
+			principal: 12000, rate: 12, tenure: 12, method: Effective,
+			first:         Installment{Number: 1, Principal: 946.19, Interest: 120, Payment: 1066.19, Balance: 11053.81},
+			last:          Installment{Number: 12, Principal: 1055.58, Interest: 10.56, Payment: 1066.14, Balance: 0},
+			totalInterest: 794.23,
+		},
+		{
+			// A third of 1000 does not round evenly: the last installment
+			// takes the cent the others leave.
+			name:      "rounding absorbed by the last installment",
+			principal: 1000, rate: 0, tenure: 3, method: Effective,
+			first:         Installment{Number: 1, Principal: 333.33, Interest: 0, Payment: 333.33, Balance: 666.67},
+			last:          Installment{Number: 3, Principal: 333.34, Interest: 0, Payment: 333.34, Balance: 0},
+			totalInterest: 0,
+		},
+		{
+			name:      "flat without interest",
+			principal: 1000, rate: 0, tenure: 3, method: Flat,
+			first:         Installment{Number: 1, Principal: 333.33, Interest: 0, Payment: 333.33, Balance: 666.67},
+			last:          Installment{Number: 3, Principal: 333.34, Interest: 0, Payment: 333.34, Balance: 0},
+			totalInterest: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Generate(tt.principal, tt.rate, tt.tenure, tt.method, startDate)
+			if err != nil {
+				t.Fatalf("Generate() = %v", err)
+			}
+
+			if schedule.Method != tt.method || len(schedule.Installments) != tt.tenure {
+				t.Fatalf("Generate() = %s with %d installments, want %s with %d", schedule.Method, len(schedule.Installments), tt.method, tt.tenure)
+			}
+
+			first, last := schedule.Installments[0], schedule.Installments[tt.tenure-1]
+			tt.first.DueDate = startDate.AddDate(0, 1, 0)
+			tt.last.DueDate = startDate.AddDate(0, tt.tenure, 0)
+
+			if first != tt.first {
+				t.Errorf("first installment = %+v, want %+v", first, tt.first)
+			}
+
+			if last != tt.last {
+				t.Errorf("last installment = %+v, want %+v", last, tt.last)
+			}
+
+			var principal float64
+			for _, installment := range schedule.Installments {
+				principal = Round(principal + installment.Principal)
+			}
+
+			if principal != tt.principal {
+				t.Errorf("installments repay %v of principal, want %v", principal, tt.principal)
+			}
+
+			if schedule.TotalInterest != tt.totalInterest || schedule.TotalPayable != Round(tt.principal+tt.totalInterest) {
+				t.Errorf("totals = interest %v, payable %v, want interest %v, payable %v",
+					schedule.TotalInterest, schedule.TotalPayable, tt.totalInterest, Round(tt.principal+tt.totalInterest))
+			}
+		})
+	}
+}
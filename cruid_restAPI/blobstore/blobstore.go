@@ -0,0 +1,53 @@
+package blobstore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps the content of uploaded files. Keys are slash-separated paths
+// chosen by the caller, such as "<submission_id>/<document_id>".
+type Store interface {
+	Put(key string, content io.Reader) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+type Config struct {
+	Backend        string `yaml:"backend"`
+	LocalDirectory string `yaml:"local_directory"`
+}
+
+type Factory func(config Config) (Store, error)
+
+var factories = map[string]Factory{}
+
+// Register makes a backend available to Open under the given name. Backends
+// register themselves from an init function.
+func Register(backend string, factory Factory) {
+	factories[backend] = factory
+}
+
+func Open(config Config) (Store, error) {
+	backend := config.Backend
+	if backend == "" {
+		backend = LocalBackend
+	}
+
+	factory, ok := factories[backend]
+	if !ok {
+		backends := make([]string, 0, len(factories))
+		for name := range factories {
+			backends = append(backends, name)
+		}
+		sort.Strings(backends)
+
+		return nil, fmt.Errorf("unknown blob store backend %q, expected one of %v", backend, backends)
+	}
+
+	return factory(config)
+}
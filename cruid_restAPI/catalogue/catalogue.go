@@ -0,0 +1,161 @@
+package catalogue
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const maxSuggestions = 3
+
+type Entry struct {
+	VehicleBrand string
+	VehicleModel string
+	VehicleType  string
+}
+
+// MismatchError explains why a vehicle is not in the catalogue, with the
+// closest catalogue values for the field that did not match.
+type MismatchError struct {
+	Field       string
+	Value       string
+	Suggestions []string
+}
+
+func (e *MismatchError) Error() string {
+	message := fmt.Sprintf("unknown %s %q", e.Field, e.Value)
+	if len(e.Suggestions) > 0 {
+		message += ", did you mean " + strings.Join(e.Suggestions, ", ")
+	}
+	return message
+}
+
+// Normalize trims and collapses whitespace, so "Land  Cruiser " is stored and
+// compared as "Land Cruiser".
+func Normalize(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+func NormalizeVehicleType(value string) string {
+	return strings.ToLower(Normalize(value))
+}
+
+// Match looks up a brand, model and vehicle type case-insensitively and
+// returns the catalogue entry with its canonical spelling.
+func Match(entries []Entry, vehicleBrand string, vehicleModel string, vehicleType string) (Entry, error) {
+	vehicleBrand = Normalize(vehicleBrand)
+	vehicleModel = Normalize(vehicleModel)
+	vehicleType = NormalizeVehicleType(vehicleType)
+
+	var brands []string
+	var brandEntries []Entry
+	for _, entry := range entries {
+		if !slicesContainFold(brands, entry.VehicleBrand) {
+			brands = append(brands, entry.VehicleBrand)
+		}
+		if strings.EqualFold(entry.VehicleBrand, vehicleBrand) {
+			brandEntries = append(brandEntries, entry)
+		}
+	}
+
+	if len(brandEntries) == 0 {
+		return Entry{}, &MismatchError{
+			Field:       "vehicle_brand",
+			Value:       vehicleBrand,
+			Suggestions: Suggest(vehicleBrand, brands),
+		}
+	}
+
+	var models []string
+	for _, entry := range brandEntries {
+		if !strings.EqualFold(entry.VehicleModel, vehicleModel) {
+			models = append(models, entry.VehicleModel)
+			continue
+		}
+
+		if entry.VehicleType != vehicleType {
+			return Entry{}, &MismatchError{
+				Field:       "vehicle_type",
+				Value:       vehicleType,
+				Suggestions: []string{entry.VehicleType},
+			}
+		}
+
+		return entry, nil
+	}
+
+	return Entry{}, &MismatchError{
+		Field:       "vehicle_model",
+		Value:       vehicleModel,
+		Suggestions: Suggest(vehicleModel, models),
+	}
+}
+
+// Suggest returns the candidates closest to value by edit distance, ignoring
+// case. Candidates that share nothing with value are left out.
+func Suggest(value string, candidates []string) []string {
+	type scored struct {
+		candidate string
+		distance  int
+	}
+
+	lowerValue := strings.ToLower(value)
+
+	var matches []scored
+	for _, candidate := range candidates {
+		lowerCandidate := strings.ToLower(candidate)
+		distance := levenshtein(lowerValue, lowerCandidate)
+
+		limit := max(len(lowerCandidate)/3, 1)
+		if distance <= limit || strings.HasPrefix(lowerCandidate, lowerValue) || strings.HasPrefix(lowerValue, lowerCandidate) {
+			matches = append(matches, scored{candidate: candidate, distance: distance})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+
+	suggestions := make([]string, 0, maxSuggestions)
+	for _, match := range matches {
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+		suggestions = append(suggestions, match.candidate)
+	}
+
+	return suggestions
+}
+
+func slicesContainFold(values []string, value string) bool {
+	for _, existing := range values {
+		if strings.EqualFold(existing, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func levenshtein(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
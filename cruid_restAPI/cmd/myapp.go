@@ -1,52 +1,212 @@
 package main
 
 import (
-	"database/sql"
 	"log"
 	"net/http"
+	"time"
 
+	"github.com/alphaloan/vehicle/blobstore"
+	"github.com/alphaloan/vehicle/collections"
 	"github.com/alphaloan/vehicle/datastore"
+	"github.com/alphaloan/vehicle/documents"
 	"github.com/alphaloan/vehicle/handler"
+	"github.com/alphaloan/vehicle/licenseplate"
+	"github.com/alphaloan/vehicle/timeouts"
+	"github.com/alphaloan/vehicle/underwriting"
+	"github.com/alphaloan/vehicle/valuation"
 )
 
 func main() {
-	datastore.InitializeDatabase("db/migration", "sqlite3://alphaloan.db")
-
-	db, err := sql.Open("sqlite3", "alphaloan.db")
+	databaseConfig, err := datastore.DatabaseConfigFromEnv()
 
 	if err != nil {
-		log.Fatal(err)
+		log.Fatal("Failed to read database configuration:", err)
 	}
 
-	_, err = db.Exec("PRAGMA foreign_keys = ON;")
+	datastore.InitializeDatabase(databaseConfig.MigrationFolder("db/migration"), databaseConfig.MigrationURL())
+
+	db, err := datastore.OpenDatabase(databaseConfig)
 
 	if err != nil {
-		log.Fatal("Failed to enable foreign key support:", err)
+		log.Fatal(err)
 	}
 
 	defer db.Close()
 
+	timeoutPolicy, err := timeouts.LoadPolicy("policy/timeout_policy.yaml")
+
+	if err != nil {
+		log.Fatal("Failed to load timeout policy:", err)
+	}
+
+	unitOfWork := datastore.NewSQLUnitOfWork(db)
 	loanCustomerStore := datastore.NewLoanCustomerStore(db)
 	loanSubmissionStore := datastore.NewLoanSubmissionStore(db)
+	loanStatusHistoryStore := datastore.NewLoanStatusHistoryStore(db)
+	loanUnderwritingStore := datastore.NewLoanUnderwritingStore(db)
+	loanInstallmentStore := datastore.NewLoanInstallmentStore(db)
+	loanPaymentStore := datastore.NewLoanPaymentStore(db)
+	loanDelinquencyStore := datastore.NewLoanDelinquencyStore(db)
+	loanProductStore := datastore.NewLoanProductStore(db)
+	vehicleReferencePriceStore := datastore.NewVehicleReferencePriceStore(db)
+	vehicleCatalogueStore := datastore.NewVehicleCatalogueStore(db)
+	collateralLienStore := datastore.NewCollateralLienStore(db)
+	loanSubmissionPartyStore := datastore.NewLoanSubmissionPartyStore(db)
+	loanDocumentStore := datastore.NewLoanDocumentStore(db)
+	customerSearchStore := datastore.NewCustomerSearchStore(db, databaseConfig.Driver)
+
+	underwritingPolicy, err := underwriting.NewPolicyWatcher("policy/underwriting_policy.yaml")
+
+	if err != nil {
+		log.Fatal("Failed to load underwriting policy:", err)
+	}
+
+	go underwritingPolicy.Watch(30*time.Second, nil)
+
+	underwritingEngine := underwriting.NewEngine(underwritingPolicy, underwriting.DefaultRules()...)
+
+	depreciationModel, err := valuation.LoadDepreciationModel("policy/valuation_policy.yaml")
+
+	if err != nil {
+		log.Fatal("Failed to load depreciation model:", err)
+	}
+
+	plateFormats, err := licenseplate.LoadRegistry("policy/license_plate_formats.yaml")
+
+	if err != nil {
+		log.Fatal("Failed to load license plate formats:", err)
+	}
+
+	loanSubmitHandler := handler.NewLoanSubmitHandler(unitOfWork, loanCustomerStore, loanSubmissionStore, *loanSubmissionPartyStore, *loanStatusHistoryStore, *loanUnderwritingStore, *loanProductStore, *vehicleCatalogueStore, *collateralLienStore, *vehicleReferencePriceStore, depreciationModel, plateFormats, underwritingEngine)
+
+	http.HandleFunc("/api/loan/submit", handler.WithTimeout(timeoutPolicy, "submit_loan", loanSubmitHandler.HandleSubmitLoan))
+
+	loanSimulationHandler := handler.NewLoanSimulationHandler(*loanProductStore, *vehicleReferencePriceStore, depreciationModel, underwritingEngine)
+
+	http.HandleFunc("/api/loan/simulate", handler.WithTimeout(timeoutPolicy, "simulate_loan", loanSimulationHandler.HandleSimulateLoan))
+
+	loanProductHandler := handler.NewLoanProductHandler(*loanProductStore)
+
+	http.HandleFunc("/api/loan/products", handler.WithTimeout(timeoutPolicy, "get_all_products", loanProductHandler.HandleGetAllProducts))
+
+	http.HandleFunc("/api/loan/products/create", handler.WithTimeout(timeoutPolicy, "create_product", loanProductHandler.HandleCreateProduct))
+
+	http.HandleFunc("/api/loan/products/{product_code}/info", handler.WithTimeout(timeoutPolicy, "get_product_info", loanProductHandler.HandleGetProductInfo))
+
+	http.HandleFunc("/api/loan/products/{product_code}/update", handler.WithTimeout(timeoutPolicy, "update_product", loanProductHandler.HandleUpdateProduct))
+
+	http.HandleFunc("/api/loan/products/{product_code}/delete", handler.WithTimeout(timeoutPolicy, "delete_product", loanProductHandler.HandleDeleteProduct))
+
+	vehicleValuationHandler := handler.NewVehicleValuationHandler(*vehicleReferencePriceStore)
+
+	http.HandleFunc("/api/vehicles/reference-prices", handler.WithTimeout(timeoutPolicy, "get_all_reference_prices", vehicleValuationHandler.HandleGetAllReferencePrices))
+
+	http.HandleFunc("/api/vehicles/reference-prices/import", handler.WithTimeout(timeoutPolicy, "import_reference_prices", vehicleValuationHandler.HandleImportReferencePrices))
+
+	vehicleCatalogueHandler := handler.NewVehicleCatalogueHandler(*vehicleCatalogueStore)
+
+	http.HandleFunc("/api/vehicles/catalogue", handler.WithTimeout(timeoutPolicy, "get_all_catalogue_entries", vehicleCatalogueHandler.HandleGetAllCatalogueEntries))
+
+	http.HandleFunc("/api/vehicles/catalogue/create", handler.WithTimeout(timeoutPolicy, "create_catalogue_entry", vehicleCatalogueHandler.HandleCreateCatalogueEntry))
+
+	http.HandleFunc("/api/vehicles/catalogue/{catalogue_id}/info", handler.WithTimeout(timeoutPolicy, "get_catalogue_entry_info", vehicleCatalogueHandler.HandleGetCatalogueEntryInfo))
+
+	http.HandleFunc("/api/vehicles/catalogue/{catalogue_id}/update", handler.WithTimeout(timeoutPolicy, "update_catalogue_entry", vehicleCatalogueHandler.HandleUpdateCatalogueEntry))
+
+	http.HandleFunc("/api/vehicles/catalogue/{catalogue_id}/delete", handler.WithTimeout(timeoutPolicy, "delete_catalogue_entry", vehicleCatalogueHandler.HandleDeleteCatalogueEntry))
+
+	collateralHandler := handler.NewCollateralHandler(*collateralLienStore)
+
+	http.HandleFunc("/api/collateral/{license_number}", handler.WithTimeout(timeoutPolicy, "get_collateral_lien_history", collateralHandler.HandleGetCollateralLienHistory))
+
+	loanSubmissionHandler := handler.NewLoanSubmissionHandler(unitOfWork, loanSubmissionStore, *loanStatusHistoryStore, *loanInstallmentStore, *collateralLienStore, *loanProductStore, *loanDocumentStore)
+
+	http.HandleFunc("/api/loan/submissions", handler.WithTimeout(timeoutPolicy, "get_all_loan_submissions", loanSubmissionHandler.HandleGetAllLoanSubmissions))
+
+	http.HandleFunc("/api/loan/submission/track", handler.WithTimeout(timeoutPolicy, "track_loan_submission", loanSubmissionHandler.HandleTrackLoanSubmission))
+
+	http.HandleFunc("/api/loan/submission/{submission_id}/transition", handler.WithTimeout(timeoutPolicy, "transition_loan_status", loanSubmissionHandler.HandleTransitionLoanStatus))
+
+	http.HandleFunc("/api/loan/submission/{submission_id}/timeline", handler.WithTimeout(timeoutPolicy, "get_loan_submission_timeline", loanSubmissionHandler.HandleGetLoanSubmissionTimeline))
+
+	documentPolicy, err := documents.LoadPolicy("policy/document_policy.yaml")
+
+	if err != nil {
+		log.Fatal("Failed to load document policy:", err)
+	}
+
+	documentBlobStore, err := blobstore.Open(documentPolicy.Storage)
+
+	if err != nil {
+		log.Fatal("Failed to open document storage:", err)
+	}
+
+	loanDocumentHandler := handler.NewLoanDocumentHandler(loanSubmissionStore, *loanProductStore, *loanDocumentStore, documentBlobStore, documentPolicy)
+
+	http.HandleFunc("/api/loan/submission/{submission_id}/documents", handler.WithTimeout(timeoutPolicy, "upload_loan_document", loanDocumentHandler.HandleUploadLoanDocument))
+
+	http.HandleFunc("/api/loan/submission/{submission_id}/documents/list", handler.WithTimeout(timeoutPolicy, "get_all_loan_documents", loanDocumentHandler.HandleGetAllLoanDocuments))
+
+	http.HandleFunc("/api/loan/submission/{submission_id}/documents/{document_id}/download", handler.WithTimeout(timeoutPolicy, "download_loan_document", loanDocumentHandler.HandleDownloadLoanDocument))
+
+	http.HandleFunc("/api/loan/submission/{submission_id}/documents/{document_id}/verify", handler.WithTimeout(timeoutPolicy, "verify_loan_document", loanDocumentHandler.HandleVerifyLoanDocument))
+
+	http.HandleFunc("/api/loan/submission/{submission_id}/documents/{document_id}/delete", handler.WithTimeout(timeoutPolicy, "delete_loan_document", loanDocumentHandler.HandleDeleteLoanDocument))
+
+	http.HandleFunc("/api/loan/submission/{submission_id}/checklist", handler.WithTimeout(timeoutPolicy, "get_loan_document_checklist", loanDocumentHandler.HandleGetLoanDocumentChecklist))
+
+	loanScheduleHandler := handler.NewLoanScheduleHandler(loanSubmissionStore, *loanInstallmentStore)
+
+	http.HandleFunc("/api/loan/submission/{submission_id}/schedule", handler.WithTimeout(timeoutPolicy, "get_loan_schedule", loanScheduleHandler.HandleGetLoanSchedule))
+
+	collectionsPolicy, err := collections.LoadPolicy("policy/collections_policy.yaml")
+
+	if err != nil {
+		log.Fatal("Failed to load collections policy:", err)
+	}
+
+	delinquencyScanner := collections.NewScanner(loanSubmissionStore, *loanInstallmentStore, *loanDelinquencyStore, collectionsPolicy)
+
+	go delinquencyScanner.Run(nil)
+
+	loanLedgerHandler := handler.NewLoanLedgerHandler(unitOfWork, loanSubmissionStore, *loanInstallmentStore, *loanPaymentStore, *loanStatusHistoryStore, *collateralLienStore, collectionsPolicy)
+
+	http.HandleFunc("/api/loan/submission/{submission_id}/payments", handler.WithTimeout(timeoutPolicy, "record_loan_payment", loanLedgerHandler.HandleRecordLoanPayment))
+
+	http.HandleFunc("/api/loan/submission/{submission_id}/ledger", handler.WithTimeout(timeoutPolicy, "get_loan_ledger", loanLedgerHandler.HandleGetLoanLedger))
+
+	http.HandleFunc("/api/loan/submission/{submission_id}/payoff", handler.WithTimeout(timeoutPolicy, "get_loan_payoff", loanLedgerHandler.HandleGetLoanPayoff))
+
+	http.HandleFunc("/api/loan/submission/{submission_id}/settle", handler.WithTimeout(timeoutPolicy, "settle_loan", loanLedgerHandler.HandleSettleLoan))
+
+	delinquencyHandler := handler.NewDelinquencyHandler(*loanDelinquencyStore)
+
+	http.HandleFunc("/api/loan/delinquencies", handler.WithTimeout(timeoutPolicy, "get_delinquencies", delinquencyHandler.HandleGetDelinquencies))
+
+	underwritingHandler := handler.NewUnderwritingHandler(unitOfWork, loanCustomerStore, loanSubmissionStore, *loanSubmissionPartyStore, *loanUnderwritingStore, underwritingEngine)
+
+	http.HandleFunc("/api/loan/submission/{submission_id}/evaluate", handler.WithTimeout(timeoutPolicy, "evaluate_loan_submission", underwritingHandler.HandleEvaluateLoanSubmission))
 
-	loanSubmitHandler := handler.NewLoanSubmitHandler(*loanCustomerStore, *loanSubmissionStore)
+	http.HandleFunc("/api/loan/submission/{submission_id}/underwriting", handler.WithTimeout(timeoutPolicy, "get_loan_submission_underwriting", underwritingHandler.HandleGetLoanSubmissionUnderwriting))
 
-	http.HandleFunc("/api/loan/submit", loanSubmitHandler.HandleSubmitLoan)
+	loanCustomerHandler := handler.NewLoanCustomerHandler(loanCustomerStore, loanSubmissionStore, *loanSubmissionPartyStore, *customerSearchStore)
+	http.HandleFunc("/api/loan/customers", handler.WithTimeout(timeoutPolicy, "get_all_customers", loanCustomerHandler.HandleGetAllCustomers))
 
-	loanSubmissionHandler := handler.NewLoanSubmissionHandler(*loanSubmissionStore)
+	http.HandleFunc("/api/loan/customers/search", handler.WithTimeout(timeoutPolicy, "search_customers", loanCustomerHandler.HandleSearchCustomers))
 
-	http.HandleFunc("/api/loan/submissions", loanSubmissionHandler.HandleGetAllLoanSubmissions)
+	http.HandleFunc("/api/loan/customer/{customer_id}/info", handler.WithTimeout(timeoutPolicy, "get_customer_info", loanCustomerHandler.HandleGetCustomerInfo))
 
-	http.HandleFunc("/api/loan/submission/track", loanSubmissionHandler.HandleTrackLoanSubmission)
+	http.HandleFunc("/api/loan/customer/{customer_id}/update", handler.WithTimeout(timeoutPolicy, "update_customer", loanCustomerHandler.HandleUpdateCustomer))
 
-	loanCustomerHandler := handler.NewLoanCustomerHandler(*loanCustomerStore, *loanSubmissionStore)
-	http.HandleFunc("/api/loan/customers", loanCustomerHandler.HandleGetAllCustomers)
+	http.HandleFunc("/api/loan/customer/{customer_id}/delete", handler.WithTimeout(timeoutPolicy, "delete_customer", loanCustomerHandler.HandleDeleteCustomer))
 
-	http.HandleFunc("/api/loan/customer/{customer_id}/info", loanCustomerHandler.HandleGetCustomerInfo)
+	exportHandler := handler.NewExportHandler(loanSubmissionStore, loanCustomerStore)
 
-	http.HandleFunc("/api/loan/customer/{customer_id}/update", loanCustomerHandler.HandleUpdateCustomer)
+	// Exports stream for as long as there are rows to send, so they get no
+	// deadline and only stop when the client disconnects.
+	http.HandleFunc("/api/export/submissions", exportHandler.HandleExportSubmissions)
 
-	http.HandleFunc("/api/loan/customer/{customer_id}/delete", loanCustomerHandler.HandleDeleteCustomer)
+	http.HandleFunc("/api/export/customers", exportHandler.HandleExportCustomers)
 
 	log.Println("Server is running on port 8080")
 	log.Fatal(http.ListenAndServe(":8080", nil))
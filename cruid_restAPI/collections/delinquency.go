@@ -0,0 +1,103 @@
+package collections
+
+import (
+	"time"
+
+	"github.com/alphaloan/vehicle/amortization"
+	"github.com/alphaloan/vehicle/datastore"
+)
+
+const (
+	BucketCurrent   = "CURRENT"
+	Bucket1To30     = "DPD_1_30"
+	Bucket31To60    = "DPD_31_60"
+	Bucket61To90    = "DPD_61_90"
+	BucketOver90    = "DPD_90_PLUS"
+	secondsInOneDay = 24 * 60 * 60
+)
+
+func IsValidBucket(bucket string) bool {
+	switch bucket {
+	case BucketCurrent, Bucket1To30, Bucket31To60, Bucket61To90, BucketOver90:
+		return true
+	}
+
+	return false
+}
+
+func BucketFor(daysPastDue int) string {
+	switch {
+	case daysPastDue <= 0:
+		return BucketCurrent
+	case daysPastDue <= 30:
+		return Bucket1To30
+	case daysPastDue <= 60:
+		return Bucket31To60
+	case daysPastDue <= 90:
+		return Bucket61To90
+	default:
+		return BucketOver90
+	}
+}
+
+func overdueAmount(installment *datastore.LoanInstallmentRow) float64 {
+	return amortization.Round(installment.PrincipalAmount - installment.PaidPrincipal + installment.InterestAmount - installment.PaidInterest)
+}
+
+// LateFee returns the fee to add to an installment and the time the accrual
+// now runs through. The flat fee is charged once, when the installment first
+// runs past its grace period, and the daily rate is charged on the overdue
+// principal and interest for every whole day since the previous accrual.
+func LateFee(policy Policy, installment *datastore.LoanInstallmentRow, now time.Time) (float64, int64, bool) {
+	overdue := overdueAmount(installment)
+	lateFrom := installment.DueDate + int64(policy.GracePeriodDays)*secondsInOneDay
+
+	if overdue <= 0 || now.Unix() <= lateFrom {
+		return 0, 0, false
+	}
+
+	var fee float64
+	accruedFrom := lateFrom
+
+	if installment.LateFeeAccruedAt.Valid {
+		accruedFrom = installment.LateFeeAccruedAt.Int64
+	} else {
+		fee += policy.LateFeeFlat
+	}
+
+	days := (now.Unix() - accruedFrom) / secondsInOneDay
+	if days == 0 && installment.LateFeeAccruedAt.Valid {
+		return 0, 0, false
+	}
+
+	fee += float64(days) * policy.LateFeeDailyRate * overdue
+
+	return amortization.Round(fee), accruedFrom + days*secondsInOneDay, true
+}
+
+// Classify returns the days past due of the oldest installment that is still
+// unpaid and the principal and interest overdue across all installments.
+func Classify(installments []*datastore.LoanInstallmentRow, now time.Time) (int, float64) {
+	daysPastDue := 0
+	var overdue float64
+
+	for _, installment := range installments {
+		if installment.DueDate >= now.Unix() {
+			continue
+		}
+
+		amount := overdueAmount(installment)
+		if amount <= 0 {
+			continue
+		}
+
+		overdue = amortization.Round(overdue + amount)
+
+		days := int((now.Unix() - installment.DueDate) / secondsInOneDay)
+		if days > daysPastDue {
+			daysPastDue = days
+		}
+	}
+
+	return daysPastDue, overdue
+}
@@ -0,0 +1,183 @@
+package collections
+
+import (
+	"database/sql"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/alphaloan/vehicle/datastore"
+)
+
+var testNow = time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+func testPolicy() Policy {
+	return Policy{
+		GracePeriodDays:   5,
+		LateFeeFlat:       50,
+		LateFeeDailyRate:  0.001,
+		ScanIntervalHours: 24,
+	}
+}
+
+// testInstallment owes 1000 of principal and 100 of interest and falls due
+// the given number of days before testNow.
+func testInstallment(daysAgo int) *datastore.LoanInstallmentRow {
+	return &datastore.LoanInstallmentRow{
+		InstallmentNumber: 1,
+		DueDate:           testNow.Unix() - int64(daysAgo)*secondsInOneDay,
+		PrincipalAmount:   1000,
+		InterestAmount:    100,
+		TotalAmount:       1100,
+	}
+}
+
+func TestBucketFor(t *testing.T) {
+	tests := []struct {
+		daysPastDue int
+		want        string
+	}{
+		{-1, BucketCurrent},
+		{0, BucketCurrent},
+		{1, Bucket1To30},
+		{30, Bucket1To30},
+		{31, Bucket31To60},
+		{60, Bucket31To60},
+		{61, Bucket61To90},
+		{90, Bucket61To90},
+		{91, BucketOver90},
+	}
+
+	for _, tt := range tests {
+		if got := BucketFor(tt.daysPastDue); got != tt.want {
+			t.Errorf("BucketFor(%d) = %s, want %s", tt.daysPastDue, got, tt.want)
+		}
+	}
+}
+
+func TestLateFee(t *testing.T) {
+	// The installment fell due 10 days ago, so it has been late for the 5
+	// days after its grace period.
+	lateFrom := testNow.Unix() - 5*secondsInOneDay
+	accruedAt := func(at int64) sql.NullInt64 { return sql.NullInt64{Int64: at, Valid: true} }
+
+	tests := []struct {
+		name        string
+		installment func() *datastore.LoanInstallmentRow
+		now         time.Time
+		wantFee     float64
+		wantThrough int64
+		wantOK      bool
+	}{
+		{
+			name:        "within the grace period",
+			installment: func() *datastore.LoanInstallmentRow { return testInstallment(3) },
+			now:         testNow,
+		},
+		{
+			name:        "at the end of the grace period",
+			installment: func() *datastore.LoanInstallmentRow { return testInstallment(5) },
+			now:         testNow,
+		},
+		{
+			name:        "flat fee in the first day after the grace period",
+			installment: func() *datastore.LoanInstallmentRow { return testInstallment(5) },
+			now:         testNow.Add(time.Second),
+			wantFee:     50,
+			wantThrough: testNow.Unix(),
+			wantOK:      true,
+		},
+		{
+			name:        "flat fee and the daily fee of every whole day",
+			installment: func() *datastore.LoanInstallmentRow { return testInstallment(10) },
+			now:         testNow.Add(12 * time.Hour),
+			wantFee:     50 + 5*0.001*1100,
+			wantThrough: lateFrom + 5*secondsInOneDay,
+			wantOK:      true,
+		},
+		{
+			name: "flat fee charged once",
+			installment: func() *datastore.LoanInstallmentRow {
+				installment := testInstallment(10)
+				installment.LateFeeAccruedAt = accruedAt(lateFrom + 2*secondsInOneDay)
+				return installment
+			},
+			now:         testNow,
+			wantFee:     3 * 0.001 * 1100,
+			wantThrough: testNow.Unix(),
+			wantOK:      true,
+		},
+		{
+			name: "less than a day since the previous accrual",
+			installment: func() *datastore.LoanInstallmentRow {
+				installment := testInstallment(10)
+				installment.LateFeeAccruedAt = accruedAt(testNow.Unix())
+				return installment
+			},
+			now: testNow.Add(23 * time.Hour),
+		},
+		{
+			name: "partially paid installment",
+			installment: func() *datastore.LoanInstallmentRow {
+				installment := testInstallment(10)
+				installment.PaidInterest, installment.PaidPrincipal = 100, 600
+				installment.LateFeeAccruedAt = accruedAt(lateFrom)
+				return installment
+			},
+			now:         testNow,
+			wantFee:     5 * 0.001 * 400,
+			wantThrough: testNow.Unix(),
+			wantOK:      true,
+		},
+		{
+			name: "paid installment",
+			installment: func() *datastore.LoanInstallmentRow {
+				installment := testInstallment(10)
+				installment.PaidInterest, installment.PaidPrincipal = 100, 1000
+				return installment
+			},
+			now: testNow,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fee, through, ok := LateFee(testPolicy(), tt.installment(), tt.now)
+
+			if ok != tt.wantOK || math.Abs(fee-tt.wantFee) > 0.005 || through != tt.wantThrough {
+				t.Errorf("LateFee() = %v, %d, %v, want %v, %d, %v", fee, through, ok, tt.wantFee, tt.wantThrough, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	paid := testInstallment(70)
+	paid.PaidInterest, paid.PaidPrincipal = 100, 1000
+
+	partiallyPaid := testInstallment(40)
+	partiallyPaid.InstallmentNumber = 2
+	partiallyPaid.PaidInterest, partiallyPaid.PaidPrincipal = 100, 250
+
+	overdue := testInstallment(10)
+	overdue.InstallmentNumber = 3
+
+	dueNow := testInstallment(0)
+	dueNow.InstallmentNumber = 4
+
+	future := testInstallment(-20)
+	future.InstallmentNumber = 5
+
+	installments := []*datastore.LoanInstallmentRow{paid, partiallyPaid, overdue, dueNow, future}
+
+	// The paid installment is older, but the days are counted from the
+	// oldest one still owing anything.
+	daysPastDue, amount := Classify(installments, testNow)
+	if daysPastDue != 40 || amount != 750+1100 {
+		t.Errorf("Classify() = %d, %v, want 40, 1850", daysPastDue, amount)
+	}
+
+	if daysPastDue, amount := Classify([]*datastore.LoanInstallmentRow{paid, future}, testNow); daysPastDue != 0 || amount != 0 {
+		t.Errorf("Classify() of a loan without arrears = %d, %v, want 0, 0", daysPastDue, amount)
+	}
+}
@@ -0,0 +1,39 @@
+package collections
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Policy struct {
+	GracePeriodDays   int     `yaml:"grace_period_days"`
+	LateFeeFlat       float64 `yaml:"late_fee_flat"`
+	LateFeeDailyRate  float64 `yaml:"late_fee_daily_rate"`
+	ScanIntervalHours int     `yaml:"scan_interval_hours"`
+
+	EarlyTerminationFeeRate float64 `yaml:"early_termination_fee_rate"`
+}
+
+func LoadPolicy(path string) (Policy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	var policy Policy
+	if err := yaml.Unmarshal(content, &policy); err != nil {
+		return Policy{}, fmt.Errorf("failed to parse collections policy %s: %w", path, err)
+	}
+
+	if policy.GracePeriodDays < 0 || policy.LateFeeFlat < 0 || policy.LateFeeDailyRate < 0 || policy.EarlyTerminationFeeRate < 0 {
+		return Policy{}, fmt.Errorf("invalid collections policy %s: grace period and fees must not be negative", path)
+	}
+
+	if policy.ScanIntervalHours <= 0 {
+		return Policy{}, fmt.Errorf("invalid collections policy %s: scan_interval_hours must be greater than zero", path)
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,105 @@
+package collections
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/alphaloan/vehicle/datastore"
+	"github.com/alphaloan/vehicle/loanstatus"
+)
+
+type Scanner struct {
+	SubmissionStore  datastore.SubmissionRepository
+	InstallmentStore datastore.LoanInstallmentStore
+	DelinquencyStore datastore.LoanDelinquencyStore
+	Policy           Policy
+}
+
+func NewScanner(
+	submissionStore datastore.SubmissionRepository,
+	installmentStore datastore.LoanInstallmentStore,
+	delinquencyStore datastore.LoanDelinquencyStore,
+	policy Policy) *Scanner {
+	return &Scanner{
+		SubmissionStore:  submissionStore,
+		InstallmentStore: installmentStore,
+		DelinquencyStore: delinquencyStore,
+		Policy:           policy,
+	}
+}
+
+// Run scans once right away and then on every scan interval until stop is closed.
+func (s *Scanner) Run(stop <-chan struct{}) {
+	interval := time.Duration(s.Policy.ScanIntervalHours) * time.Hour
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Scan(context.Background(), time.Now()); err != nil {
+			log.Printf("Delinquency scan failed: %v", err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scanner) Scan(ctx context.Context, now time.Time) error {
+	submissionIDs, err := s.SubmissionStore.GetLoanSubmissionIDsByStatus(ctx, loanstatus.Disbursed)
+	if err != nil {
+		return err
+	}
+
+	for _, submissionID := range submissionIDs {
+		if err := s.scanSubmission(ctx, submissionID, now); err != nil {
+			log.Printf("Delinquency scan failed for submission %s: %v", submissionID, err)
+		}
+	}
+
+	if err := s.DelinquencyStore.DeleteDelinquenciesNotInStatus(ctx, loanstatus.Disbursed); err != nil {
+		return err
+	}
+
+	log.Printf("Delinquency scan finished for %d disbursed loans", len(submissionIDs))
+
+	return nil
+}
+
+func (s *Scanner) scanSubmission(ctx context.Context, submissionID string, now time.Time) error {
+	installments, err := s.InstallmentStore.GetInstallmentsBySubmissionID(ctx, submissionID)
+	if err != nil {
+		return err
+	}
+
+	for _, installment := range installments {
+		fee, accruedThrough, ok := LateFee(s.Policy, installment, now)
+		if !ok {
+			continue
+		}
+
+		// A scan running at the same time may have accrued the fee since the
+		// installments were read, and then the fee is not charged twice.
+		err := s.InstallmentStore.AccrueLateFee(ctx, submissionID, installment.InstallmentNumber, fee, installment.LateFeeAccruedAt, accruedThrough)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+	}
+
+	daysPastDue, overdue := Classify(installments, now)
+
+	_, err = s.DelinquencyStore.UpsertDelinquency(ctx, &datastore.LoanDelinquencyRow{
+		SubmissionID:  submissionID,
+		DaysPastDue:   daysPastDue,
+		Bucket:        BucketFor(daysPastDue),
+		OverdueAmount: overdue,
+		EvaluatedAt:   now.Unix(),
+	})
+
+	return err
+}
@@ -0,0 +1,155 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+)
+
+type CollateralLienRow struct {
+	SubmissionID         string
+	VehicleLicenseNumber string
+	PledgedAt            int64
+	ReleasedAt           sql.NullInt64
+	ReleaseReason        sql.NullString
+}
+
+type CollateralLienWithStatusRow struct {
+	CollateralLienRow
+	LoanStatus string
+	CustomerID string
+}
+
+type CollateralLienStore struct {
+	db DBTX
+}
+
+func NewCollateralLienStore(db *sql.DB) *CollateralLienStore {
+	return &CollateralLienStore{
+		db: db,
+	}
+}
+
+// WithTx returns a copy of the store that runs its statements in tx. A tx
+// from a MemoryUnitOfWork leaves the statements outside any transaction.
+func (s *CollateralLienStore) WithTx(tx Tx) *CollateralLienStore {
+	return &CollateralLienStore{
+		db: txDB(s.db, tx),
+	}
+}
+
+const sqlPledgeCollateral = `
+INSERT INTO collateral_liens (
+	submission_id,
+	vehicle_license_number,
+	pledged_at
+) VALUES (
+	$1, $2, $3
+)
+ON CONFLICT DO NOTHING
+RETURNING submission_id;
+`
+
+// PledgeCollateral records the vehicle as securing the submission. It returns
+// false without an error when the plate is already pledged to another loan.
+func (s *CollateralLienStore) PledgeCollateral(ctx context.Context, lien *CollateralLienRow) (bool, error) {
+	var submissionID string
+	err := s.db.QueryRowContext(ctx, sqlPledgeCollateral,
+		lien.SubmissionID,
+		lien.VehicleLicenseNumber,
+		lien.PledgedAt,
+	).Scan(&submissionID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+const sqlReleaseCollateral = `
+UPDATE collateral_liens
+SET
+	released_at = $1,
+	release_reason = $2
+WHERE submission_id = $3 AND released_at IS NULL;
+`
+
+func (s *CollateralLienStore) ReleaseCollateral(ctx context.Context, submissionID string, releasedAt int64, releaseReason string) error {
+	_, err := s.db.ExecContext(ctx, sqlReleaseCollateral, releasedAt, releaseReason, submissionID)
+	return err
+}
+
+const sqlGetActiveLienByLicenseNumber = `
+SELECT
+	submission_id, vehicle_license_number,
+	pledged_at, released_at,
+	release_reason
+FROM collateral_liens
+WHERE vehicle_license_number = $1 AND released_at IS NULL;
+`
+
+func (s *CollateralLienStore) GetActiveLienByLicenseNumber(ctx context.Context, vehicleLicenseNumber string) (*CollateralLienRow, error) {
+	lien := &CollateralLienRow{}
+	err := s.db.QueryRowContext(ctx, sqlGetActiveLienByLicenseNumber, vehicleLicenseNumber).Scan(
+		&lien.SubmissionID,
+		&lien.VehicleLicenseNumber,
+		&lien.PledgedAt,
+		&lien.ReleasedAt,
+		&lien.ReleaseReason,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return lien, nil
+}
+
+const sqlGetLiensByLicenseNumber = `
+SELECT
+	l.submission_id, l.vehicle_license_number,
+	l.pledged_at, l.released_at,
+	l.release_reason, s.loan_status,
+	s.customer_id
+FROM collateral_liens l
+INNER JOIN loan_submissions s
+ON l.submission_id = s.submission_id
+WHERE l.vehicle_license_number = $1
+ORDER BY l.pledged_at DESC, l.released_at IS NULL DESC;
+`
+
+func (s *CollateralLienStore) GetLiensByLicenseNumber(ctx context.Context, vehicleLicenseNumber string) ([]*CollateralLienWithStatusRow, error) {
+	rows, err := s.db.QueryContext(ctx, sqlGetLiensByLicenseNumber, vehicleLicenseNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var liens []*CollateralLienWithStatusRow
+	for rows.Next() {
+		lien := &CollateralLienWithStatusRow{}
+		err := rows.Scan(
+			&lien.SubmissionID,
+			&lien.VehicleLicenseNumber,
+			&lien.PledgedAt,
+			&lien.ReleasedAt,
+			&lien.ReleaseReason,
+			&lien.LoanStatus,
+			&lien.CustomerID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		liens = append(liens, lien)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return liens, nil
+}
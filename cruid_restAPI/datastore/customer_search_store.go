@@ -0,0 +1,131 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// CustomerSearchStore finds customers by part of their ID card number, phone
+// number, email or name. On SQLite it reads the loan_customers_fts trigram
+// index, which triggers keep in sync with loan_customers; on PostgreSQL it
+// uses the pg_trgm indexes of the table itself.
+type CustomerSearchStore struct {
+	db     *sql.DB
+	driver string
+}
+
+func NewCustomerSearchStore(db *sql.DB, driver string) *CustomerSearchStore {
+	return &CustomerSearchStore{
+		db:     db,
+		driver: driver,
+	}
+}
+
+const sqlSearchCustomersSQLite = `
+SELECT
+	c.customer_id, c.id_card_number,
+	c.full_name, c.birth_date,
+	c.phone_number, c.email,
+	c.monthly_income, c.address_street,
+	c.address_city
+FROM loan_customers_fts f
+JOIN loan_customers c ON c.customer_id = f.customer_id
+WHERE loan_customers_fts MATCH $1
+ORDER BY f.rank
+LIMIT $2;
+`
+
+const sqlSearchCustomersPostgres = `
+SELECT
+	customer_id, id_card_number,
+	full_name, birth_date,
+	phone_number, email,
+	monthly_income, address_street,
+	address_city
+FROM loan_customers
+WHERE id_card_number ILIKE $1
+	OR phone_number ILIKE $1
+	OR email ILIKE $1
+	OR full_name ILIKE $1
+	OR full_name % $2
+ORDER BY similarity(full_name, $2) DESC
+LIMIT $3;
+`
+
+// SearchCustomers returns up to limit candidates for query: customers with
+// query in one of the searched fields, and customers whose name shares some
+// three-letter sequences with it. Candidates are not ranked by how well they
+// match. The index only finds substrings of at least three characters.
+func (s *CustomerSearchStore) SearchCustomers(ctx context.Context, query string, limit int) ([]*LoanCustomerRow, error) {
+	var rows *sql.Rows
+	var err error
+
+	if s.driver == PostgresDriver {
+		rows, err = s.db.QueryContext(ctx, sqlSearchCustomersPostgres, "%"+escapeLike(query)+"%", query, limit)
+	} else {
+		rows, err = s.db.QueryContext(ctx, sqlSearchCustomersSQLite, customerMatchExpression(query), limit)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var customers []*LoanCustomerRow
+	for rows.Next() {
+		var customer LoanCustomerRow
+		err := rows.Scan(
+			&customer.CustomerID,
+			&customer.IDCardNumber,
+			&customer.FullName,
+			&customer.BirthDate,
+			&customer.PhoneNumber,
+			&customer.Email,
+			&customer.MonthlyIncome,
+			&customer.AddressStreet,
+			&customer.AddressCity,
+		)
+		if err != nil {
+			return nil, err
+		}
+		customers = append(customers, &customer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return customers, nil
+}
+
+// customerMatchExpression builds the FTS5 query for the trigram index: query
+// as a substring of any column, or any three-letter sequence of it in the name.
+func customerMatchExpression(query string) string {
+	lowered := []rune(strings.ToLower(query))
+
+	seen := map[string]bool{}
+	var nameTrigrams []string
+	for i := 0; i+3 <= len(lowered); i++ {
+		trigram := string(lowered[i : i+3])
+		if !seen[trigram] {
+			seen[trigram] = true
+			nameTrigrams = append(nameTrigrams, quoteMatchString(trigram))
+		}
+	}
+
+	expression := "{id_card_number full_name phone_number email} : " + quoteMatchString(query)
+	if len(nameTrigrams) > 0 {
+		expression += " OR full_name : (" + strings.Join(nameTrigrams, " OR ") + ")"
+	}
+
+	return expression
+}
+
+func quoteMatchString(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
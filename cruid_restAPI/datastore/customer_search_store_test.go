@@ -0,0 +1,119 @@
+package datastore
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCustomerMatchExpression(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "name",
+			query: "Budi",
+			want:  `{id_card_number full_name phone_number email} : "Budi" OR full_name : ("bud" OR "udi")`,
+		},
+		{
+			name:  "repeated trigrams once",
+			query: "aaaa",
+			want:  `{id_card_number full_name phone_number email} : "aaaa" OR full_name : ("aaa")`,
+		},
+		{
+			name:  "too short for a trigram",
+			query: "ab",
+			want:  `{id_card_number full_name phone_number email} : "ab"`,
+		},
+		{
+			name:  "quotes are escaped",
+			query: `O"Neil`,
+			want:  `{id_card_number full_name phone_number email} : "O""Neil" OR full_name : ("o""n" OR """ne" OR "nei" OR "eil")`,
+		},
+		{
+			name:  "operators are quoted",
+			query: "a OR b*",
+			want:  `{id_card_number full_name phone_number email} : "a OR b*" OR full_name : ("a o" OR " or" OR "or " OR "r b" OR " b*")`,
+		},
+		{
+			name:  "trigrams of runes",
+			query: "Zoë",
+			want:  `{id_card_number full_name phone_number email} : "Zoë" OR full_name : ("zoë")`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := customerMatchExpression(tt.query); got != tt.want {
+				t.Errorf("customerMatchExpression(%q) = %s, want %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	if got, want := escapeLike(`50%_off\`), `50\%\_off\\`; got != want {
+		t.Errorf("escapeLike() = %s, want %s", got, want)
+	}
+}
+
+func TestSearchCustomersSQLite(t *testing.T) {
+	if !sqliteFTS5Enabled {
+		t.Skip("the SQLite migrations need FTS5: run with -tags sqlite_fts5")
+	}
+
+	db := openMigratedDatabase(t, DatabaseConfig{
+		Driver: SQLiteDriver,
+		DSN:    filepath.Join(t.TempDir(), "alphaloan.db"),
+	})
+
+	ctx := context.Background()
+	customerStore := NewLoanCustomerStore(db)
+	for _, customer := range []*LoanCustomerRow{
+		testCustomer("customer-1", "3171234567890001", "Budi Santoso", "Jakarta"),
+		testCustomer("customer-2", "3171234567890002", "Siti Rahayu", "Bandung"),
+		testCustomer("customer-3", "3275987654320003", "Agus O\"Neil", "Bekasi"),
+	} {
+		customer.PhoneNumber = "0812" + customer.IDCardNumber[12:]
+		if _, err := customerStore.UpsertCustomer(ctx, customer); err != nil {
+			t.Fatalf("UpsertCustomer(%s): %v", customer.CustomerID, err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"id card number substring", "31712345", []string{"customer-1", "customer-2"}},
+		{"phone number", "08120003", []string{"customer-3"}},
+		{"name substring in another case", "SANTO", []string{"customer-1"}},
+		{"misspelt name", "Sitti", []string{"customer-2"}},
+		{"quote", `O"Neil`, []string{"customer-3"}},
+		{"operators", "Budi OR NOT", []string{"customer-1"}},
+		{"nothing in common", "xyz", nil},
+	}
+
+	searchStore := NewCustomerSearchStore(db, SQLiteDriver)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			customers, err := searchStore.SearchCustomers(ctx, tt.query, 10)
+			if err != nil {
+				t.Fatalf("SearchCustomers(%q): %v", tt.query, err)
+			}
+
+			var got []string
+			for _, customer := range customers {
+				got = append(got, customer.CustomerID)
+			}
+			slices.Sort(got)
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SearchCustomers(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package datastore
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	// The drivers OpenDatabase hands to sql.Open.
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const (
+	SQLiteDriver   = "sqlite3"
+	PostgresDriver = "postgres"
+)
+
+const defaultSQLitePath = "alphaloan.db"
+
+// DatabaseConfig selects the database engine. The SQL in the stores uses $N
+// placeholders and ON CONFLICT ... RETURNING, which both engines accept, so
+// only the schema migrations differ between them.
+type DatabaseConfig struct {
+	Driver string
+	// DSN is the file path for SQLite and a postgres:// URL for PostgreSQL.
+	DSN string
+}
+
+// DatabaseConfigFromEnv reads DATABASE_DRIVER and DATABASE_URL, falling back
+// to the alphaloan.db SQLite file.
+func DatabaseConfigFromEnv() (DatabaseConfig, error) {
+	config := DatabaseConfig{
+		Driver: strings.TrimSpace(os.Getenv("DATABASE_DRIVER")),
+		DSN:    strings.TrimSpace(os.Getenv("DATABASE_URL")),
+	}
+
+	switch config.Driver {
+	case "", SQLiteDriver:
+		config.Driver = SQLiteDriver
+		if config.DSN == "" {
+			config.DSN = defaultSQLitePath
+		}
+		if !sqliteFTS5Enabled {
+			return DatabaseConfig{}, fmt.Errorf("the %s driver needs FTS5: build with -tags sqlite_fts5", SQLiteDriver)
+		}
+	case PostgresDriver:
+		if config.DSN == "" {
+			return DatabaseConfig{}, fmt.Errorf("DATABASE_URL is required for the %s driver", PostgresDriver)
+		}
+	default:
+		return DatabaseConfig{}, fmt.Errorf("unknown DATABASE_DRIVER %q, expected %s or %s", config.Driver, SQLiteDriver, PostgresDriver)
+	}
+
+	return config, nil
+}
+
+// MigrationFolder returns where the migrations of the configured engine live:
+// the SQLite ones directly under baseFolder and the PostgreSQL ones in its
+// postgres subfolder.
+func (c DatabaseConfig) MigrationFolder(baseFolder string) string {
+	if c.Driver == PostgresDriver {
+		return filepath.Join(baseFolder, "postgres")
+	}
+
+	return baseFolder
+}
+
+// MigrationURL returns the database URL in the form golang-migrate expects.
+func (c DatabaseConfig) MigrationURL() string {
+	if c.Driver == PostgresDriver {
+		return c.DSN
+	}
+
+	return "sqlite3://" + c.DSN
+}
+
+// OpenDatabase opens the database of config. SQLite enforces foreign keys per
+// connection, so they are switched on in the DSN, which the driver applies to
+// every connection the pool opens, rather than once on whichever connection
+// happens to run a PRAGMA.
+func OpenDatabase(config DatabaseConfig) (*sql.DB, error) {
+	dsn := config.DSN
+	if config.Driver == SQLiteDriver {
+		dsn = sqliteDSNWithForeignKeys(dsn)
+	}
+
+	db, err := sql.Open(config.Driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Driver == SQLiteDriver {
+		var foreignKeys int
+		if err := db.QueryRow("PRAGMA foreign_keys;").Scan(&foreignKeys); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to check foreign key support: %w", err)
+		}
+
+		if foreignKeys != 1 {
+			db.Close()
+			return nil, fmt.Errorf("failed to enable foreign key support: DSN %q turns it off", config.DSN)
+		}
+	}
+
+	return db, nil
+}
+
+// sqliteDSNWithForeignKeys adds _foreign_keys=on to a SQLite DSN that does not
+// set foreign key support itself.
+func sqliteDSNWithForeignKeys(dsn string) string {
+	if strings.Contains(dsn, "_foreign_keys=") || strings.Contains(dsn, "_fk=") {
+		return dsn
+	}
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+
+	return dsn + separator + "_foreign_keys=on"
+}
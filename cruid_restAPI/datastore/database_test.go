@@ -0,0 +1,65 @@
+package datastore
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteDSNWithForeignKeys(t *testing.T) {
+	tests := []struct {
+		dsn  string
+		want string
+	}{
+		{"alphaloan.db", "alphaloan.db?_foreign_keys=on"},
+		{"file:alphaloan.db?cache=shared", "file:alphaloan.db?cache=shared&_foreign_keys=on"},
+		{"alphaloan.db?_foreign_keys=off", "alphaloan.db?_foreign_keys=off"},
+		{"alphaloan.db?_fk=1", "alphaloan.db?_fk=1"},
+	}
+
+	for _, tt := range tests {
+		if got := sqliteDSNWithForeignKeys(tt.dsn); got != tt.want {
+			t.Errorf("sqliteDSNWithForeignKeys(%q) = %q, want %q", tt.dsn, got, tt.want)
+		}
+	}
+}
+
+func TestOpenDatabaseEnablesForeignKeysOnEveryConnection(t *testing.T) {
+	db, err := OpenDatabase(DatabaseConfig{
+		Driver: SQLiteDriver,
+		DSN:    filepath.Join(t.TempDir(), "alphaloan.db"),
+	})
+	if err != nil {
+		t.Fatalf("OpenDatabase: %v", err)
+	}
+	defer db.Close()
+
+	// Holding every connection open makes the pool open a new one each time.
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("Conn: %v", err)
+		}
+		defer conn.Close()
+
+		var foreignKeys int
+		if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys;").Scan(&foreignKeys); err != nil {
+			t.Fatalf("PRAGMA foreign_keys: %v", err)
+		}
+
+		if foreignKeys != 1 {
+			t.Errorf("connection %d has foreign_keys = %d, want 1", i+1, foreignKeys)
+		}
+	}
+}
+
+func TestOpenDatabaseRejectsDSNWithoutForeignKeys(t *testing.T) {
+	_, err := OpenDatabase(DatabaseConfig{
+		Driver: SQLiteDriver,
+		DSN:    filepath.Join(t.TempDir(), "alphaloan.db") + "?_foreign_keys=off",
+	})
+	if err == nil {
+		t.Error("OpenDatabase with foreign keys turned off = nil error, want an error")
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !sqlite_fts5 && !fts5
+
+package datastore
+
+// The customer search index uses FTS5, which go-sqlite3 only compiles in with
+// the sqlite_fts5 build tag.
+const sqliteFTS5Enabled = false
@@ -0,0 +1,101 @@
+package datastore
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LoanCustomerCursor points at the last customer of a page. Customers are
+// ordered by name, and by ID among customers with the same name.
+type LoanCustomerCursor struct {
+	FullName   string
+	CustomerID string
+}
+
+// LoanCustomerQuery selects a page of customers. An empty city and nil income
+// bounds leave a filter out; the city is compared ignoring case and the income
+// range is inclusive.
+type LoanCustomerQuery struct {
+	City             string
+	MinMonthlyIncome *float64
+	MaxMonthlyIncome *float64
+
+	After *LoanCustomerCursor
+	Limit int
+}
+
+// Matches reports whether customer passes the filters of the query, ignoring
+// the cursor.
+func (q LoanCustomerQuery) Matches(customer *LoanCustomerRow) bool {
+	switch {
+	case q.City != "" && !strings.EqualFold(customer.AddressCity, q.City):
+		return false
+	case q.MinMonthlyIncome != nil && customer.MonthlyIncome < *q.MinMonthlyIncome:
+		return false
+	case q.MaxMonthlyIncome != nil && customer.MonthlyIncome > *q.MaxMonthlyIncome:
+		return false
+	}
+
+	return true
+}
+
+// isAfterCursor reports whether customer comes after the cursor of the query.
+func (q LoanCustomerQuery) isAfterCursor(customer *LoanCustomerRow) bool {
+	if q.After == nil {
+		return true
+	}
+
+	if customer.FullName == q.After.FullName {
+		return customer.CustomerID > q.After.CustomerID
+	}
+
+	return customer.FullName > q.After.FullName
+}
+
+// whereClause returns the WHERE, ORDER BY and LIMIT clauses of the query with
+// their arguments, numbered from $1.
+func (q LoanCustomerQuery) whereClause() (string, []any) {
+	var conditions []string
+	var args []any
+
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if q.City != "" {
+		addCondition("LOWER(address_city) = LOWER($%d)", q.City)
+	}
+
+	if q.MinMonthlyIncome != nil {
+		addCondition("monthly_income >= $%d", *q.MinMonthlyIncome)
+	}
+
+	if q.MaxMonthlyIncome != nil {
+		addCondition("monthly_income <= $%d", *q.MaxMonthlyIncome)
+	}
+
+	if q.After != nil {
+		args = append(args, q.After.FullName, q.After.CustomerID)
+		conditions = append(conditions, fmt.Sprintf(
+			"(full_name > $%[1]d OR (full_name = $%[1]d AND customer_id > $%[2]d))",
+			len(args)-1, len(args),
+		))
+	}
+
+	var clause strings.Builder
+	if len(conditions) > 0 {
+		clause.WriteString("WHERE ")
+		clause.WriteString(strings.Join(conditions, " AND "))
+		clause.WriteString("\n")
+	}
+
+	clause.WriteString("ORDER BY full_name, customer_id")
+
+	if q.Limit > 0 {
+		args = append(args, q.Limit)
+		fmt.Fprintf(&clause, "\nLIMIT $%d", len(args))
+	}
+
+	return clause.String(), args
+}
@@ -1,6 +1,7 @@
 package datastore
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 )
@@ -18,7 +19,7 @@ type LoanCustomerRow struct {
 }
 
 type LoanCustomerStore struct {
-	db *sql.DB
+	db DBTX
 }
 
 func NewLoanCustomerStore(db *sql.DB) *LoanCustomerStore {
@@ -27,6 +28,14 @@ func NewLoanCustomerStore(db *sql.DB) *LoanCustomerStore {
 	}
 }
 
+// WithTx returns a copy of the store that runs its statements in tx. A tx
+// from a MemoryUnitOfWork leaves the statements outside any transaction.
+func (s *LoanCustomerStore) WithTx(tx Tx) CustomerRepository {
+	return &LoanCustomerStore{
+		db: txDB(s.db, tx),
+	}
+}
+
 const sqlUpsertCustomer = `
 	INSERT INTO loan_customers (
 		customer_id,	
@@ -51,9 +60,9 @@ const sqlUpsertCustomer = `
 	RETURNING customer_id;
 `
 
-func (s *LoanCustomerStore) UpsertCustomer(customer *LoanCustomerRow) (string, error) {
+func (s *LoanCustomerStore) UpsertCustomer(ctx context.Context, customer *LoanCustomerRow) (string, error) {
 	var customerID string
-	err := s.db.QueryRow(sqlUpsertCustomer,
+	err := s.db.QueryRowContext(ctx, sqlUpsertCustomer,
 		customer.CustomerID,
 		customer.IDCardNumber,
 		customer.FullName,
@@ -80,17 +89,35 @@ SELECT
 	monthly_income, address_street,
 	address_city
 FROM loan_customers
-ORDER BY full_name;
 `
 
-func (s *LoanCustomerStore) GetAllCustomers() ([]*LoanCustomerRow, error) {
-	rows, err := s.db.Query(sqlGetAllCustomers)
+// GetAllCustomers returns the customers matching query ordered by name, at
+// most query.Limit of them when a limit is set.
+func (s *LoanCustomerStore) GetAllCustomers(ctx context.Context, query LoanCustomerQuery) ([]*LoanCustomerRow, error) {
+	var customers []*LoanCustomerRow
+	err := s.StreamCustomers(ctx, query, func(customer *LoanCustomerRow) error {
+		customers = append(customers, customer)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+
+	return customers, nil
+}
+
+// StreamCustomers calls fn with every customer matching query, ordered by
+// name, as it is read from the database. It stops at the first error fn
+// returns.
+func (s *LoanCustomerStore) StreamCustomers(ctx context.Context, query LoanCustomerQuery, fn func(*LoanCustomerRow) error) error {
+	whereClause, args := query.whereClause()
+
+	rows, err := s.db.QueryContext(ctx, sqlGetAllCustomers+whereClause, args...)
+	if err != nil {
+		return err
+	}
 	defer rows.Close()
 
-	var customers []*LoanCustomerRow
 	for rows.Next() {
 		var customer LoanCustomerRow
 		err := rows.Scan(
@@ -105,16 +132,49 @@ func (s *LoanCustomerStore) GetAllCustomers() ([]*LoanCustomerRow, error) {
 			&customer.AddressCity,
 		)
 		if err != nil {
-			return nil, err
+			return err
+		}
+
+		if err := fn(&customer); err != nil {
+			return err
 		}
-		customers = append(customers, &customer)
 	}
 
-	if err = rows.Err(); err != nil {
+	return rows.Err()
+}
+
+const sqlGetLoanCustomerRowByID = `
+SELECT
+	customer_id, id_card_number,
+	full_name, birth_date,
+	phone_number, email,
+	monthly_income, address_street,
+	address_city
+FROM loan_customers
+WHERE customer_id = $1;
+`
+
+func (s *LoanCustomerStore) GetLoanCustomerRowByID(ctx context.Context, customerID string) (*LoanCustomerRow, error) {
+	row := s.db.QueryRowContext(ctx, sqlGetLoanCustomerRowByID, customerID)
+	customer := &LoanCustomerRow{}
+	err := row.Scan(
+		&customer.CustomerID,
+		&customer.IDCardNumber,
+		&customer.FullName,
+		&customer.BirthDate,
+		&customer.PhoneNumber,
+		&customer.Email,
+		&customer.MonthlyIncome,
+		&customer.AddressStreet,
+		&customer.AddressCity,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
 		return nil, err
 	}
-
-	return customers, nil
+	return customer, nil
 }
 
 const sqlGetCustomerByID = `
@@ -128,8 +188,12 @@ SELECT
 	s.vehicle_model, s.vehicle_license_number,
 	s.vehicle_odometer, s.manufacturing_year,
 	s.proposed_loan_amount, s.proposed_loan_tenure_month,
-	s.is_commercial_vehicle, s.created_at,
-	s.updated_at
+	s.loan_status, s.is_commercial_vehicle,
+	s.created_at, s.updated_at,
+	s.policy_version, s.annual_interest_rate,
+	s.interest_method, s.product_code,
+	s.collateral_value, s.loan_to_value,
+	s.vehicle_region_code, s.underwriting_recommendation
 FROM loan_customers c
 INNER JOIN loan_submissions s
 ON c.customer_id = s.customer_id
@@ -142,8 +206,8 @@ type LoanCustomerWithAllSubmissionsRow struct {
 	LoanSubmissions []*LoanSubmissionRow
 }
 
-func (s *LoanCustomerStore) GetCustomerByID(customerID string) (*LoanCustomerWithAllSubmissionsRow, error) {
-	rows, err := s.db.Query(sqlGetCustomerByID, customerID)
+func (s *LoanCustomerStore) GetCustomerByID(ctx context.Context, customerID string) (*LoanCustomerWithAllSubmissionsRow, error) {
+	rows, err := s.db.QueryContext(ctx, sqlGetCustomerByID, customerID)
 
 	if err != nil {
 		return nil, err
@@ -176,9 +240,18 @@ func (s *LoanCustomerStore) GetCustomerByID(customerID string) (*LoanCustomerWit
 				&submission.ManufacturingYear,
 				&submission.ProposedLoanAmount,
 				&submission.ProposedLoanTenure,
+				&submission.LoanStatus,
 				&submission.IsCommercialVehicle,
 				&submission.CreatedAt,
 				&submission.UpdatedAt,
+				&submission.PolicyVersion,
+				&submission.AnnualInterestRate,
+				&submission.InterestMethod,
+				&submission.ProductCode,
+				&submission.CollateralValue,
+				&submission.LoanToValue,
+				&submission.VehicleRegionCode,
+				&submission.UnderwritingRecommendation,
 			)
 			if err != nil {
 				return nil, err
@@ -195,9 +268,18 @@ func (s *LoanCustomerStore) GetCustomerByID(customerID string) (*LoanCustomerWit
 				&submission.ManufacturingYear,
 				&submission.ProposedLoanAmount,
 				&submission.ProposedLoanTenure,
+				&submission.LoanStatus,
 				&submission.IsCommercialVehicle,
 				&submission.CreatedAt,
 				&submission.UpdatedAt,
+				&submission.PolicyVersion,
+				&submission.AnnualInterestRate,
+				&submission.InterestMethod,
+				&submission.ProductCode,
+				&submission.CollateralValue,
+				&submission.LoanToValue,
+				&submission.VehicleRegionCode,
+				&submission.UnderwritingRecommendation,
 			)
 			if err != nil {
 				return nil, err
@@ -235,9 +317,9 @@ WHERE customer_id = $9
 RETURNING customer_id;
 `
 
-func (s *LoanCustomerStore) UpdateCustomerByID(customer *LoanCustomerRow, customerIDToUpdate string) (string, error) {
+func (s *LoanCustomerStore) UpdateCustomerByID(ctx context.Context, customer *LoanCustomerRow, customerIDToUpdate string) (string, error) {
 	var customerID string
-	err := s.db.QueryRow(sqlUpdateCustomerByID,
+	err := s.db.QueryRowContext(ctx, sqlUpdateCustomerByID,
 		customer.FullName,
 		customer.BirthDate,
 		customer.PhoneNumber,
@@ -262,9 +344,9 @@ WHERE customer_id = $1
 RETURNING customer_id;
 `
 
-func (s *LoanCustomerStore) DeleteCustomerByID(customerIDToDelete string) (string, error) {
+func (s *LoanCustomerStore) DeleteCustomerByID(ctx context.Context, customerIDToDelete string) (string, error) {
 	var customerID string
-	err := s.db.QueryRow(sqlDeleteCustomerByCustomerID, customerIDToDelete).Scan(&customerID)
+	err := s.db.QueryRowContext(ctx, sqlDeleteCustomerByCustomerID, customerIDToDelete).Scan(&customerID)
 
 	if err != nil {
 		return "", err
@@ -0,0 +1,136 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+)
+
+type LoanDelinquencyRow struct {
+	SubmissionID  string
+	DaysPastDue   int
+	Bucket        string
+	OverdueAmount float64
+	EvaluatedAt   int64
+}
+
+type LoanDelinquencyWithCustomerRow struct {
+	LoanDelinquencyRow *LoanDelinquencyRow
+	CustomerID         string
+	FullName           string
+	PhoneNumber        string
+	Email              sql.NullString
+}
+
+type LoanDelinquencyStore struct {
+	db *sql.DB
+}
+
+func NewLoanDelinquencyStore(db *sql.DB) *LoanDelinquencyStore {
+	return &LoanDelinquencyStore{
+		db: db,
+	}
+}
+
+const sqlUpsertDelinquency = `
+INSERT INTO loan_delinquencies (
+	submission_id,
+	days_past_due,
+	bucket,
+	overdue_amount,
+	evaluated_at
+) VALUES (
+	$1, $2, $3, $4, $5
+) ON CONFLICT (submission_id) DO UPDATE SET
+	days_past_due = EXCLUDED.days_past_due,
+	bucket = EXCLUDED.bucket,
+	overdue_amount = EXCLUDED.overdue_amount,
+	evaluated_at = EXCLUDED.evaluated_at
+RETURNING submission_id;
+`
+
+func (s *LoanDelinquencyStore) UpsertDelinquency(ctx context.Context, delinquency *LoanDelinquencyRow) (string, error) {
+	var submissionID string
+	err := s.db.QueryRowContext(ctx, sqlUpsertDelinquency,
+		delinquency.SubmissionID,
+		delinquency.DaysPastDue,
+		delinquency.Bucket,
+		delinquency.OverdueAmount,
+		delinquency.EvaluatedAt,
+	).Scan(&submissionID)
+
+	if err != nil {
+		return "", err
+	}
+
+	return submissionID, nil
+}
+
+const sqlDeleteDelinquenciesNotInStatus = `
+DELETE FROM loan_delinquencies
+WHERE submission_id IN (
+	SELECT submission_id
+	FROM loan_submissions
+	WHERE loan_status <> $1
+);
+`
+
+// DeleteDelinquenciesNotInStatus drops the classification of every submission
+// that has left the given status, e.g. loans that were closed since the last scan.
+func (s *LoanDelinquencyStore) DeleteDelinquenciesNotInStatus(ctx context.Context, loanStatus string) error {
+	_, err := s.db.ExecContext(ctx, sqlDeleteDelinquenciesNotInStatus, loanStatus)
+	return err
+}
+
+const sqlGetDelinquenciesByBucket = `
+SELECT
+	d.submission_id, d.days_past_due,
+	d.bucket, d.overdue_amount,
+	d.evaluated_at, c.customer_id,
+	c.full_name, c.phone_number,
+	c.email
+FROM loan_delinquencies d
+INNER JOIN loan_submissions s
+ON d.submission_id = s.submission_id
+INNER JOIN loan_customers c
+ON s.customer_id = c.customer_id
+WHERE ($1 = '' AND d.bucket <> $2) OR d.bucket = $1
+ORDER BY d.days_past_due DESC, d.submission_id;
+`
+
+// GetDelinquenciesByBucket lists the submissions of one bucket. An empty bucket
+// lists every submission that is not in currentBucket.
+func (s *LoanDelinquencyStore) GetDelinquenciesByBucket(ctx context.Context, bucket string, currentBucket string) ([]*LoanDelinquencyWithCustomerRow, error) {
+	rows, err := s.db.QueryContext(ctx, sqlGetDelinquenciesByBucket, bucket, currentBucket)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var delinquencies []*LoanDelinquencyWithCustomerRow
+	for rows.Next() {
+		delinquency := &LoanDelinquencyWithCustomerRow{
+			LoanDelinquencyRow: &LoanDelinquencyRow{},
+		}
+		err := rows.Scan(
+			&delinquency.LoanDelinquencyRow.SubmissionID,
+			&delinquency.LoanDelinquencyRow.DaysPastDue,
+			&delinquency.LoanDelinquencyRow.Bucket,
+			&delinquency.LoanDelinquencyRow.OverdueAmount,
+			&delinquency.LoanDelinquencyRow.EvaluatedAt,
+			&delinquency.CustomerID,
+			&delinquency.FullName,
+			&delinquency.PhoneNumber,
+			&delinquency.Email,
+		)
+		if err != nil {
+			return nil, err
+		}
+		delinquencies = append(delinquencies, delinquency)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return delinquencies, nil
+}
@@ -0,0 +1,224 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+)
+
+type LoanDocumentRow struct {
+	DocumentID     string
+	SubmissionID   string
+	DocumentType   string
+	FileName       string
+	MimeType       string
+	SizeBytes      int64
+	ChecksumSHA256 string
+	StorageKey     string
+	UploadedBy     string
+	UploadedAt     int64
+	VerifiedBy     sql.NullString
+	VerifiedAt     sql.NullInt64
+}
+
+type LoanDocumentStore struct {
+	db DBTX
+}
+
+func NewLoanDocumentStore(db *sql.DB) *LoanDocumentStore {
+	return &LoanDocumentStore{
+		db: db,
+	}
+}
+
+// WithTx returns a copy of the store that runs its statements in tx. A tx
+// from a MemoryUnitOfWork leaves the statements outside any transaction.
+func (s *LoanDocumentStore) WithTx(tx Tx) *LoanDocumentStore {
+	return &LoanDocumentStore{
+		db: txDB(s.db, tx),
+	}
+}
+
+const sqlInsertLoanDocument = `
+INSERT INTO loan_documents (
+	document_id,
+	submission_id,
+	document_type,
+	file_name,
+	mime_type,
+	size_bytes,
+	checksum_sha256,
+	storage_key,
+	uploaded_by,
+	uploaded_at
+) VALUES (
+	$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+)
+RETURNING document_id;
+`
+
+func (s *LoanDocumentStore) CreateDocument(ctx context.Context, document *LoanDocumentRow) (string, error) {
+	var documentID string
+	err := s.db.QueryRowContext(ctx, sqlInsertLoanDocument,
+		document.DocumentID,
+		document.SubmissionID,
+		document.DocumentType,
+		document.FileName,
+		document.MimeType,
+		document.SizeBytes,
+		document.ChecksumSHA256,
+		document.StorageKey,
+		document.UploadedBy,
+		document.UploadedAt,
+	).Scan(&documentID)
+
+	if err != nil {
+		return "", err
+	}
+
+	return documentID, nil
+}
+
+const sqlGetDocumentsBySubmissionID = `
+SELECT
+	document_id, submission_id,
+	document_type, file_name,
+	mime_type, size_bytes,
+	checksum_sha256, storage_key,
+	uploaded_by, uploaded_at,
+	verified_by, verified_at
+FROM loan_documents
+WHERE submission_id = $1
+ORDER BY uploaded_at, document_id;
+`
+
+func (s *LoanDocumentStore) GetDocumentsBySubmissionID(ctx context.Context, submissionID string) ([]*LoanDocumentRow, error) {
+	rows, err := s.db.QueryContext(ctx, sqlGetDocumentsBySubmissionID, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var documents []*LoanDocumentRow
+	for rows.Next() {
+		document := &LoanDocumentRow{}
+		err := rows.Scan(
+			&document.DocumentID,
+			&document.SubmissionID,
+			&document.DocumentType,
+			&document.FileName,
+			&document.MimeType,
+			&document.SizeBytes,
+			&document.ChecksumSHA256,
+			&document.StorageKey,
+			&document.UploadedBy,
+			&document.UploadedAt,
+			&document.VerifiedBy,
+			&document.VerifiedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+const sqlGetDocumentByID = `
+SELECT
+	document_id, submission_id,
+	document_type, file_name,
+	mime_type, size_bytes,
+	checksum_sha256, storage_key,
+	uploaded_by, uploaded_at,
+	verified_by, verified_at
+FROM loan_documents
+WHERE submission_id = $1 AND document_id = $2;
+`
+
+func (s *LoanDocumentStore) GetDocumentByID(ctx context.Context, submissionID string, documentID string) (*LoanDocumentRow, error) {
+	document := &LoanDocumentRow{}
+	err := s.db.QueryRowContext(ctx, sqlGetDocumentByID, submissionID, documentID).Scan(
+		&document.DocumentID,
+		&document.SubmissionID,
+		&document.DocumentType,
+		&document.FileName,
+		&document.MimeType,
+		&document.SizeBytes,
+		&document.ChecksumSHA256,
+		&document.StorageKey,
+		&document.UploadedBy,
+		&document.UploadedAt,
+		&document.VerifiedBy,
+		&document.VerifiedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return document, nil
+}
+
+const sqlVerifyDocumentByID = `
+UPDATE loan_documents
+SET
+	verified_by = $1,
+	verified_at = $2
+WHERE submission_id = $3 AND document_id = $4
+RETURNING document_id;
+`
+
+func (s *LoanDocumentStore) VerifyDocumentByID(ctx context.Context, submissionID string, documentIDToVerify string, verifiedBy string, verifiedAt int64) (string, error) {
+	var documentID string
+	err := s.db.QueryRowContext(ctx, sqlVerifyDocumentByID, verifiedBy, verifiedAt, submissionID, documentIDToVerify).Scan(&documentID)
+
+	if err != nil {
+		return "", err
+	}
+
+	return documentID, nil
+}
+
+// sqlLockLoanSubmissionInStatus writes the submission row without changing
+// it, which takes the same row lock as a status update. A delete and a
+// transition of the same submission therefore run one after the other.
+const sqlLockLoanSubmissionInStatus = `
+UPDATE loan_submissions
+SET updated_at = updated_at
+WHERE submission_id = $1 AND loan_status = $2
+RETURNING submission_id;
+`
+
+const sqlDeleteDocumentByID = `
+DELETE FROM loan_documents
+WHERE submission_id = $1 AND document_id = $2
+RETURNING document_id;
+`
+
+// DeleteDocumentByID deletes the document only while its submission is still
+// in loanStatus, and returns sql.ErrNoRows when it is not. The submission row
+// stays locked until the delete commits, so an approval either waits for it
+// and sees the document gone, or commits first and makes the delete fail.
+func (s *LoanDocumentStore) DeleteDocumentByID(ctx context.Context, submissionID string, documentIDToDelete string, loanStatus string) (string, error) {
+	var documentID string
+	err := inTransaction(ctx, s.db, func(tx DBTX) error {
+		var lockedSubmissionID string
+		if err := tx.QueryRowContext(ctx, sqlLockLoanSubmissionInStatus, submissionID, loanStatus).Scan(&lockedSubmissionID); err != nil {
+			return err
+		}
+
+		return tx.QueryRowContext(ctx, sqlDeleteDocumentByID, submissionID, documentIDToDelete).Scan(&documentID)
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return documentID, nil
+}
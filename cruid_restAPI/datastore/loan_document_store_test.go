@@ -0,0 +1,70 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeleteDocumentByIDSQLite(t *testing.T) {
+	if !sqliteFTS5Enabled {
+		t.Skip("the SQLite migrations need FTS5: run with -tags sqlite_fts5")
+	}
+
+	db := openMigratedDatabase(t, DatabaseConfig{
+		Driver: SQLiteDriver,
+		DSN:    filepath.Join(t.TempDir(), "alphaloan.db"),
+	})
+
+	ctx := context.Background()
+	repos := newSQLRepositories(db)
+	mustUpsertCustomer(t, ctx, repos, testCustomer("customer-1", "3171234567890001", "Budi Santoso", "Jakarta"))
+	mustUpsertSubmission(t, ctx, repos, testSubmission("submission-1", "customer-1", 100_000_000, 1))
+
+	documentStore := NewLoanDocumentStore(db)
+	_, err := documentStore.CreateDocument(ctx, &LoanDocumentRow{
+		DocumentID:     "document-1",
+		SubmissionID:   "submission-1",
+		DocumentType:   "PAYSLIP",
+		FileName:       "payslip.pdf",
+		MimeType:       "application/pdf",
+		SizeBytes:      1,
+		ChecksumSHA256: "checksum",
+		StorageKey:     "submission-1/document-1",
+		UploadedBy:     "officer",
+		UploadedAt:     1,
+	})
+	if err != nil {
+		t.Fatalf("CreateDocument: %v", err)
+	}
+
+	if _, err := repos.submissions.UpdateLoanStatusByID(ctx, "submission-1", "NEW", "UNDER_REVIEW", 2); err != nil {
+		t.Fatalf("UpdateLoanStatusByID: %v", err)
+	}
+
+	// A delete decided on the status read before the transition must not go
+	// through.
+	if _, err := documentStore.DeleteDocumentByID(ctx, "submission-1", "document-1", "NEW"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("DeleteDocumentByID in a stale status = %v, want sql.ErrNoRows", err)
+	}
+
+	if document, err := documentStore.GetDocumentByID(ctx, "submission-1", "document-1"); err != nil || document == nil {
+		t.Fatalf("GetDocumentByID after the refused delete = %v, %v, want the document", document, err)
+	}
+
+	submission, err := repos.submissions.GetLoanSubmissionByID(ctx, "submission-1")
+	if err != nil || submission.UpdatedAt != 2 {
+		t.Fatalf("GetLoanSubmissionByID = %+v, %v, want the submission left as it was", submission, err)
+	}
+
+	documentID, err := documentStore.DeleteDocumentByID(ctx, "submission-1", "document-1", "UNDER_REVIEW")
+	if err != nil || documentID != "document-1" {
+		t.Fatalf("DeleteDocumentByID = %q, %v, want document-1", documentID, err)
+	}
+
+	if _, err := documentStore.DeleteDocumentByID(ctx, "submission-1", "document-1", "UNDER_REVIEW"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("DeleteDocumentByID of a deleted document = %v, want sql.ErrNoRows", err)
+	}
+}
@@ -0,0 +1,172 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+)
+
+type LoanInstallmentRow struct {
+	SubmissionID      string
+	InstallmentNumber int
+	DueDate           int64
+	PrincipalAmount   float64
+	InterestAmount    float64
+	TotalAmount       float64
+	RemainingBalance  float64
+	FeeAmount         float64
+	PaidFee           float64
+	PaidInterest      float64
+	PaidPrincipal     float64
+	LateFeeAccruedAt  sql.NullInt64
+	WaivedInterest    float64
+}
+
+type LoanInstallmentStore struct {
+	db DBTX
+}
+
+func NewLoanInstallmentStore(db *sql.DB) *LoanInstallmentStore {
+	return &LoanInstallmentStore{
+		db: db,
+	}
+}
+
+// WithTx returns a copy of the store that runs its statements in tx. A tx
+// from a MemoryUnitOfWork leaves the statements outside any transaction.
+func (s *LoanInstallmentStore) WithTx(tx Tx) *LoanInstallmentStore {
+	return &LoanInstallmentStore{
+		db: txDB(s.db, tx),
+	}
+}
+
+const sqlDeleteInstallmentsBySubmissionID = `
+DELETE FROM loan_installments
+WHERE submission_id = $1;
+`
+
+const sqlInsertInstallment = `
+INSERT INTO loan_installments (
+	submission_id,
+	installment_number,
+	due_date,
+	principal_amount,
+	interest_amount,
+	total_amount,
+	remaining_balance
+) VALUES (
+	$1, $2, $3, $4, $5, $6, $7
+);
+`
+
+func (s *LoanInstallmentStore) ReplaceInstallments(ctx context.Context, submissionID string, installments []*LoanInstallmentRow) error {
+	return inTransaction(ctx, s.db, func(tx DBTX) error {
+		if _, err := tx.ExecContext(ctx, sqlDeleteInstallmentsBySubmissionID, submissionID); err != nil {
+			return err
+		}
+
+		for _, installment := range installments {
+			_, err := tx.ExecContext(ctx, sqlInsertInstallment,
+				submissionID,
+				installment.InstallmentNumber,
+				installment.DueDate,
+				installment.PrincipalAmount,
+				installment.InterestAmount,
+				installment.TotalAmount,
+				installment.RemainingBalance,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+const sqlGetInstallmentsBySubmissionID = `
+SELECT
+	submission_id, installment_number,
+	due_date, principal_amount,
+	interest_amount, total_amount,
+	remaining_balance, fee_amount,
+	paid_fee, paid_interest,
+	paid_principal, late_fee_accrued_through,
+	waived_interest
+FROM loan_installments
+WHERE submission_id = $1
+ORDER BY installment_number;
+`
+
+func (s *LoanInstallmentStore) GetInstallmentsBySubmissionID(ctx context.Context, submissionID string) ([]*LoanInstallmentRow, error) {
+	rows, err := s.db.QueryContext(ctx, sqlGetInstallmentsBySubmissionID, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var installments []*LoanInstallmentRow
+	for rows.Next() {
+		installment := &LoanInstallmentRow{}
+		err := rows.Scan(
+			&installment.SubmissionID,
+			&installment.InstallmentNumber,
+			&installment.DueDate,
+			&installment.PrincipalAmount,
+			&installment.InterestAmount,
+			&installment.TotalAmount,
+			&installment.RemainingBalance,
+			&installment.FeeAmount,
+			&installment.PaidFee,
+			&installment.PaidInterest,
+			&installment.PaidPrincipal,
+			&installment.LateFeeAccruedAt,
+			&installment.WaivedInterest,
+		)
+		if err != nil {
+			return nil, err
+		}
+		installments = append(installments, installment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return installments, nil
+}
+
+const sqlAccrueLateFee = `
+UPDATE loan_installments
+SET
+	fee_amount = fee_amount + $1,
+	late_fee_accrued_through = $2
+WHERE submission_id = $3 AND installment_number = $4
+AND late_fee_accrued_through IS NOT DISTINCT FROM $5;
+`
+
+// AccrueLateFee adds fee to the installment only while its accrual still runs
+// through previousAccruedThrough, the value the fee was computed from, and
+// returns sql.ErrNoRows when another scan has accrued it in the meantime.
+func (s *LoanInstallmentStore) AccrueLateFee(ctx context.Context, submissionID string, installmentNumber int, fee float64, previousAccruedThrough sql.NullInt64, accruedThrough int64) error {
+	result, err := s.db.ExecContext(ctx, sqlAccrueLateFee,
+		fee,
+		accruedThrough,
+		submissionID,
+		installmentNumber,
+		previousAccruedThrough,
+	)
+	if err != nil {
+		return err
+	}
+
+	accrued, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if accrued == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestAccrueLateFeeSQLite(t *testing.T) {
+	if !sqliteFTS5Enabled {
+		t.Skip("the SQLite migrations need FTS5: run with -tags sqlite_fts5")
+	}
+
+	db := openMigratedDatabase(t, DatabaseConfig{
+		Driver: SQLiteDriver,
+		DSN:    filepath.Join(t.TempDir(), "alphaloan.db"),
+	})
+
+	ctx := context.Background()
+	repos := newSQLRepositories(db)
+	mustUpsertCustomer(t, ctx, repos, testCustomer("customer-1", "3171234567890001", "Budi Santoso", "Jakarta"))
+	mustUpsertSubmission(t, ctx, repos, testSubmission("submission-1", "customer-1", 100_000_000, 1))
+
+	installmentStore := NewLoanInstallmentStore(db)
+	err := installmentStore.ReplaceInstallments(ctx, "submission-1", []*LoanInstallmentRow{
+		{InstallmentNumber: 1, DueDate: 100, PrincipalAmount: 1000, InterestAmount: 100, TotalAmount: 1100, RemainingBalance: 0},
+	})
+	if err != nil {
+		t.Fatalf("ReplaceInstallments: %v", err)
+	}
+
+	notAccrued := sql.NullInt64{}
+	accruedThrough := sql.NullInt64{Int64: 200, Valid: true}
+
+	if err := installmentStore.AccrueLateFee(ctx, "submission-1", 1, 50, notAccrued, 200); err != nil {
+		t.Fatalf("first AccrueLateFee: %v", err)
+	}
+
+	// Both scans read the installment before either accrued, so the second
+	// one must not charge the flat fee again.
+	if err := installmentStore.AccrueLateFee(ctx, "submission-1", 1, 50, notAccrued, 200); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("AccrueLateFee from a stale accrual = %v, want sql.ErrNoRows", err)
+	}
+
+	if err := installmentStore.AccrueLateFee(ctx, "submission-1", 1, 5, accruedThrough, 300); err != nil {
+		t.Fatalf("AccrueLateFee from the current accrual: %v", err)
+	}
+
+	installments, err := installmentStore.GetInstallmentsBySubmissionID(ctx, "submission-1")
+	if err != nil {
+		t.Fatalf("GetInstallmentsBySubmissionID: %v", err)
+	}
+
+	if got := installments[0]; got.FeeAmount != 55 || got.LateFeeAccruedAt != (sql.NullInt64{Int64: 300, Valid: true}) {
+		t.Errorf("installment = fee %v accrued through %v, want fee 55 accrued through 300", got.FeeAmount, got.LateFeeAccruedAt)
+	}
+}
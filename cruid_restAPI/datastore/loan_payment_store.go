@@ -0,0 +1,196 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+)
+
+type LoanPaymentRow struct {
+	PaymentID           string
+	SubmissionID        string
+	Amount              float64
+	FeeApplied          float64
+	InterestApplied     float64
+	PrincipalApplied    float64
+	UnappliedAmount     float64
+	EarlyTerminationFee float64
+	WaivedInterest      float64
+	Reference           sql.NullString
+	PaidAt              int64
+	CreatedAt           int64
+}
+
+// LoanInstallmentAllocationRow is the part of a payment applied to one
+// installment. The Paid fields are what the installment had been paid and
+// waived when the allocation was calculated.
+type LoanInstallmentAllocationRow struct {
+	InstallmentNumber int
+	Fee               float64
+	Interest          float64
+	Principal         float64
+	WaivedInterest    float64
+
+	PaidFee            float64
+	PaidInterest       float64
+	PaidPrincipal      float64
+	PaidWaivedInterest float64
+}
+
+type LoanPaymentStore struct {
+	db DBTX
+}
+
+func NewLoanPaymentStore(db *sql.DB) *LoanPaymentStore {
+	return &LoanPaymentStore{
+		db: db,
+	}
+}
+
+// WithTx returns a copy of the store that runs its statements in tx. A tx
+// from a MemoryUnitOfWork leaves the statements outside any transaction.
+func (s *LoanPaymentStore) WithTx(tx Tx) *LoanPaymentStore {
+	return &LoanPaymentStore{
+		db: txDB(s.db, tx),
+	}
+}
+
+const sqlApplyInstallmentAllocation = `
+UPDATE loan_installments
+SET
+	paid_fee = paid_fee + $1,
+	paid_interest = paid_interest + $2,
+	paid_principal = paid_principal + $3,
+	waived_interest = waived_interest + $4
+WHERE submission_id = $5 AND installment_number = $6
+	AND paid_fee = $7
+	AND paid_interest = $8
+	AND paid_principal = $9
+	AND waived_interest = $10;
+`
+
+const sqlInsertPayment = `
+INSERT INTO loan_payments (
+	payment_id,
+	submission_id,
+	amount,
+	fee_applied,
+	interest_applied,
+	principal_applied,
+	unapplied_amount,
+	early_termination_fee,
+	waived_interest,
+	reference,
+	paid_at,
+	created_at
+) VALUES (
+	$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+RETURNING payment_id;
+`
+
+// RecordPayment applies the allocations to the installments and records the
+// payment. It returns sql.ErrNoRows and records nothing when an installment
+// was paid or waived by someone else after the allocations were calculated.
+func (s *LoanPaymentStore) RecordPayment(ctx context.Context, payment *LoanPaymentRow, allocations []*LoanInstallmentAllocationRow) (string, error) {
+	var paymentID string
+
+	err := inTransaction(ctx, s.db, func(tx DBTX) error {
+		for _, allocation := range allocations {
+			result, err := tx.ExecContext(ctx, sqlApplyInstallmentAllocation,
+				allocation.Fee,
+				allocation.Interest,
+				allocation.Principal,
+				allocation.WaivedInterest,
+				payment.SubmissionID,
+				allocation.InstallmentNumber,
+				allocation.PaidFee,
+				allocation.PaidInterest,
+				allocation.PaidPrincipal,
+				allocation.PaidWaivedInterest,
+			)
+			if err != nil {
+				return err
+			}
+
+			applied, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+
+			if applied == 0 {
+				return sql.ErrNoRows
+			}
+		}
+
+		return tx.QueryRowContext(ctx, sqlInsertPayment,
+			payment.PaymentID,
+			payment.SubmissionID,
+			payment.Amount,
+			payment.FeeApplied,
+			payment.InterestApplied,
+			payment.PrincipalApplied,
+			payment.UnappliedAmount,
+			payment.EarlyTerminationFee,
+			payment.WaivedInterest,
+			payment.Reference,
+			payment.PaidAt,
+			payment.CreatedAt,
+		).Scan(&paymentID)
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return paymentID, nil
+}
+
+const sqlGetPaymentsBySubmissionID = `
+SELECT
+	payment_id, submission_id,
+	amount, fee_applied,
+	interest_applied, principal_applied,
+	unapplied_amount, early_termination_fee,
+	waived_interest, reference,
+	paid_at, created_at
+FROM loan_payments
+WHERE submission_id = $1
+ORDER BY paid_at ASC, created_at ASC;
+`
+
+func (s *LoanPaymentStore) GetPaymentsBySubmissionID(ctx context.Context, submissionID string) ([]*LoanPaymentRow, error) {
+	rows, err := s.db.QueryContext(ctx, sqlGetPaymentsBySubmissionID, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payments []*LoanPaymentRow
+	for rows.Next() {
+		payment := &LoanPaymentRow{}
+		err := rows.Scan(
+			&payment.PaymentID,
+			&payment.SubmissionID,
+			&payment.Amount,
+			&payment.FeeApplied,
+			&payment.InterestApplied,
+			&payment.PrincipalApplied,
+			&payment.UnappliedAmount,
+			&payment.EarlyTerminationFee,
+			&payment.WaivedInterest,
+			&payment.Reference,
+			&payment.PaidAt,
+			&payment.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
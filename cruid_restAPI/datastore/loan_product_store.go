@@ -0,0 +1,245 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+)
+
+type LoanProductRow struct {
+	ProductCode           string
+	ProductName           string
+	AnnualInterestRate    float64
+	InterestMethod        string
+	MinLoanAmount         int
+	MaxLoanAmount         int
+	MinTenureMonth        int
+	MaxTenureMonth        int
+	EligibleVehicleTypes  string
+	IsCommercialVehicle   bool
+	EffectiveFrom         int64
+	EffectiveUntil        sql.NullInt64
+	RequiredDocumentTypes string
+}
+
+type LoanProductStore struct {
+	db *sql.DB
+}
+
+func NewLoanProductStore(db *sql.DB) *LoanProductStore {
+	return &LoanProductStore{
+		db: db,
+	}
+}
+
+const sqlInsertProduct = `
+INSERT INTO loan_products (
+	product_code,
+	product_name,
+	annual_interest_rate,
+	interest_method,
+	min_loan_amount,
+	max_loan_amount,
+	min_tenure_month,
+	max_tenure_month,
+	eligible_vehicle_types,
+	is_commercial_vehicle,
+	effective_from,
+	effective_until,
+	required_document_types
+) VALUES (
+	$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+)
+RETURNING product_code;
+`
+
+func (s *LoanProductStore) CreateProduct(ctx context.Context, product *LoanProductRow) (string, error) {
+	var productCode string
+	err := s.db.QueryRowContext(ctx, sqlInsertProduct,
+		product.ProductCode,
+		product.ProductName,
+		product.AnnualInterestRate,
+		product.InterestMethod,
+		product.MinLoanAmount,
+		product.MaxLoanAmount,
+		product.MinTenureMonth,
+		product.MaxTenureMonth,
+		product.EligibleVehicleTypes,
+		product.IsCommercialVehicle,
+		product.EffectiveFrom,
+		product.EffectiveUntil,
+		product.RequiredDocumentTypes,
+	).Scan(&productCode)
+
+	if err != nil {
+		return "", err
+	}
+
+	return productCode, nil
+}
+
+const sqlGetAllProducts = `
+SELECT
+	product_code, product_name,
+	annual_interest_rate, interest_method,
+	min_loan_amount, max_loan_amount,
+	min_tenure_month, max_tenure_month,
+	eligible_vehicle_types, is_commercial_vehicle,
+	effective_from, effective_until,
+	required_document_types
+FROM loan_products
+ORDER BY product_code;
+`
+
+func (s *LoanProductStore) GetAllProducts(ctx context.Context) ([]*LoanProductRow, error) {
+	rows, err := s.db.QueryContext(ctx, sqlGetAllProducts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []*LoanProductRow
+	for rows.Next() {
+		product := &LoanProductRow{}
+		err := rows.Scan(
+			&product.ProductCode,
+			&product.ProductName,
+			&product.AnnualInterestRate,
+			&product.InterestMethod,
+			&product.MinLoanAmount,
+			&product.MaxLoanAmount,
+			&product.MinTenureMonth,
+			&product.MaxTenureMonth,
+			&product.EligibleVehicleTypes,
+			&product.IsCommercialVehicle,
+			&product.EffectiveFrom,
+			&product.EffectiveUntil,
+			&product.RequiredDocumentTypes,
+		)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+const sqlGetProductByCode = `
+SELECT
+	product_code, product_name,
+	annual_interest_rate, interest_method,
+	min_loan_amount, max_loan_amount,
+	min_tenure_month, max_tenure_month,
+	eligible_vehicle_types, is_commercial_vehicle,
+	effective_from, effective_until,
+	required_document_types
+FROM loan_products
+WHERE product_code = $1;
+`
+
+func (s *LoanProductStore) GetProductByCode(ctx context.Context, productCode string) (*LoanProductRow, error) {
+	row := s.db.QueryRowContext(ctx, sqlGetProductByCode, productCode)
+	product := &LoanProductRow{}
+	err := row.Scan(
+		&product.ProductCode,
+		&product.ProductName,
+		&product.AnnualInterestRate,
+		&product.InterestMethod,
+		&product.MinLoanAmount,
+		&product.MaxLoanAmount,
+		&product.MinTenureMonth,
+		&product.MaxTenureMonth,
+		&product.EligibleVehicleTypes,
+		&product.IsCommercialVehicle,
+		&product.EffectiveFrom,
+		&product.EffectiveUntil,
+		&product.RequiredDocumentTypes,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return product, nil
+}
+
+const sqlUpdateProductByCode = `
+UPDATE loan_products
+SET
+	product_name = $1,
+	annual_interest_rate = $2,
+	interest_method = $3,
+	min_loan_amount = $4,
+	max_loan_amount = $5,
+	min_tenure_month = $6,
+	max_tenure_month = $7,
+	eligible_vehicle_types = $8,
+	is_commercial_vehicle = $9,
+	effective_from = $10,
+	effective_until = $11,
+	required_document_types = $12
+WHERE product_code = $13
+RETURNING product_code;
+`
+
+func (s *LoanProductStore) UpdateProductByCode(ctx context.Context, product *LoanProductRow, productCodeToUpdate string) (string, error) {
+	var productCode string
+	err := s.db.QueryRowContext(ctx, sqlUpdateProductByCode,
+		product.ProductName,
+		product.AnnualInterestRate,
+		product.InterestMethod,
+		product.MinLoanAmount,
+		product.MaxLoanAmount,
+		product.MinTenureMonth,
+		product.MaxTenureMonth,
+		product.EligibleVehicleTypes,
+		product.IsCommercialVehicle,
+		product.EffectiveFrom,
+		product.EffectiveUntil,
+		product.RequiredDocumentTypes,
+		productCodeToUpdate,
+	).Scan(&productCode)
+
+	if err != nil {
+		return "", err
+	}
+
+	return productCode, nil
+}
+
+const sqlCountSubmissionsByProductCode = `
+SELECT COUNT(*)
+FROM loan_submissions
+WHERE product_code = $1;
+`
+
+func (s *LoanProductStore) IsProductInUse(ctx context.Context, productCode string) (bool, error) {
+	var count int
+	if err := s.db.QueryRowContext(ctx, sqlCountSubmissionsByProductCode, productCode).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+const sqlDeleteProductByCode = `
+DELETE FROM loan_products
+WHERE product_code = $1
+RETURNING product_code;
+`
+
+func (s *LoanProductStore) DeleteProductByCode(ctx context.Context, productCodeToDelete string) (string, error) {
+	var productCode string
+	err := s.db.QueryRowContext(ctx, sqlDeleteProductByCode, productCodeToDelete).Scan(&productCode)
+
+	if err != nil {
+		return "", err
+	}
+
+	return productCode, nil
+}
@@ -0,0 +1,109 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+)
+
+type LoanStatusHistoryRow struct {
+	HistoryID    int64
+	SubmissionID string
+	FromStatus   sql.NullString
+	ToStatus     string
+	Actor        string
+	Reason       sql.NullString
+	ChangedAt    int64
+}
+
+type LoanStatusHistoryStore struct {
+	db DBTX
+}
+
+func NewLoanStatusHistoryStore(db *sql.DB) *LoanStatusHistoryStore {
+	return &LoanStatusHistoryStore{
+		db: db,
+	}
+}
+
+// WithTx returns a copy of the store that runs its statements in tx. A tx
+// from a MemoryUnitOfWork leaves the statements outside any transaction.
+func (s *LoanStatusHistoryStore) WithTx(tx Tx) *LoanStatusHistoryStore {
+	return &LoanStatusHistoryStore{
+		db: txDB(s.db, tx),
+	}
+}
+
+const sqlInsertStatusHistory = `
+INSERT INTO loan_submission_status_history (
+	submission_id,
+	from_status,
+	to_status,
+	actor,
+	reason,
+	changed_at
+) VALUES (
+	$1, $2, $3, $4, $5, $6
+)
+RETURNING history_id;
+`
+
+func (s *LoanStatusHistoryStore) AppendStatusHistory(ctx context.Context, history *LoanStatusHistoryRow) (int64, error) {
+	var historyID int64
+	err := s.db.QueryRowContext(ctx, sqlInsertStatusHistory,
+		history.SubmissionID,
+		history.FromStatus,
+		history.ToStatus,
+		history.Actor,
+		history.Reason,
+		history.ChangedAt,
+	).Scan(&historyID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return historyID, nil
+}
+
+const sqlGetStatusHistoryBySubmissionID = `
+SELECT
+	history_id, submission_id,
+	from_status, to_status,
+	actor, reason,
+	changed_at
+FROM loan_submission_status_history
+WHERE submission_id = $1
+ORDER BY changed_at ASC, history_id ASC;
+`
+
+func (s *LoanStatusHistoryStore) GetStatusHistoryBySubmissionID(ctx context.Context, submissionID string) ([]*LoanStatusHistoryRow, error) {
+	rows, err := s.db.QueryContext(ctx, sqlGetStatusHistoryBySubmissionID, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var histories []*LoanStatusHistoryRow
+	for rows.Next() {
+		history := &LoanStatusHistoryRow{}
+		err := rows.Scan(
+			&history.HistoryID,
+			&history.SubmissionID,
+			&history.FromStatus,
+			&history.ToStatus,
+			&history.Actor,
+			&history.Reason,
+			&history.ChangedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		histories = append(histories, history)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return histories, nil
+}
@@ -0,0 +1,140 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+)
+
+type LoanSubmissionPartyRow struct {
+	SubmissionID string
+	CustomerID   string
+	PartyRole    string
+	CreatedAt    int64
+}
+
+type LoanSubmissionPartyWithCustomerRow struct {
+	SubmissionID string
+	PartyRole    string
+	Customer     LoanCustomerRow
+}
+
+type LoanSubmissionPartyStore struct {
+	db DBTX
+}
+
+func NewLoanSubmissionPartyStore(db *sql.DB) *LoanSubmissionPartyStore {
+	return &LoanSubmissionPartyStore{
+		db: db,
+	}
+}
+
+// WithTx returns a copy of the store that runs its statements in tx. A tx
+// from a MemoryUnitOfWork leaves the statements outside any transaction.
+func (s *LoanSubmissionPartyStore) WithTx(tx Tx) *LoanSubmissionPartyStore {
+	return &LoanSubmissionPartyStore{
+		db: txDB(s.db, tx),
+	}
+}
+
+const sqlInsertSubmissionParty = `
+INSERT INTO loan_submission_parties (
+	submission_id,
+	customer_id,
+	party_role,
+	created_at
+) VALUES (
+	$1, $2, $3, $4
+) ON CONFLICT (submission_id, customer_id) DO UPDATE SET
+	party_role = EXCLUDED.party_role;
+`
+
+func (s *LoanSubmissionPartyStore) AddParties(ctx context.Context, parties []*LoanSubmissionPartyRow) error {
+	return inTransaction(ctx, s.db, func(tx DBTX) error {
+		for _, party := range parties {
+			_, err := tx.ExecContext(ctx, sqlInsertSubmissionParty,
+				party.SubmissionID,
+				party.CustomerID,
+				party.PartyRole,
+				party.CreatedAt,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+const sqlSelectPartiesWithCustomer = `
+SELECT
+	p.submission_id, p.party_role,
+	c.customer_id, c.id_card_number,
+	c.full_name, c.birth_date,
+	c.phone_number, c.email,
+	c.monthly_income, c.address_street,
+	c.address_city
+FROM loan_submission_parties p
+INNER JOIN loan_customers c
+ON p.customer_id = c.customer_id
+`
+
+const sqlGetPartiesBySubmissionID = sqlSelectPartiesWithCustomer + `
+WHERE p.submission_id = $1
+ORDER BY p.created_at, CASE p.party_role WHEN 'PRIMARY' THEN 0 WHEN 'CO_APPLICANT' THEN 1 ELSE 2 END;
+`
+
+func (s *LoanSubmissionPartyStore) GetPartiesBySubmissionID(ctx context.Context, submissionID string) ([]*LoanSubmissionPartyWithCustomerRow, error) {
+	return s.queryPartiesWithCustomer(ctx, sqlGetPartiesBySubmissionID, submissionID)
+}
+
+const sqlGetPartiesByPrimaryCustomerID = sqlSelectPartiesWithCustomer + `
+WHERE p.submission_id IN (
+	SELECT submission_id
+	FROM loan_submissions
+	WHERE customer_id = $1
+)
+ORDER BY p.submission_id, p.created_at, CASE p.party_role WHEN 'PRIMARY' THEN 0 WHEN 'CO_APPLICANT' THEN 1 ELSE 2 END;
+`
+
+// GetPartiesByPrimaryCustomerID returns the parties of every submission the
+// customer applied for, including the customer's own PRIMARY rows.
+func (s *LoanSubmissionPartyStore) GetPartiesByPrimaryCustomerID(ctx context.Context, customerID string) ([]*LoanSubmissionPartyWithCustomerRow, error) {
+	return s.queryPartiesWithCustomer(ctx, sqlGetPartiesByPrimaryCustomerID, customerID)
+}
+
+func (s *LoanSubmissionPartyStore) queryPartiesWithCustomer(ctx context.Context, query string, args ...any) ([]*LoanSubmissionPartyWithCustomerRow, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var parties []*LoanSubmissionPartyWithCustomerRow
+	for rows.Next() {
+		party := &LoanSubmissionPartyWithCustomerRow{}
+		err := rows.Scan(
+			&party.SubmissionID,
+			&party.PartyRole,
+			&party.Customer.CustomerID,
+			&party.Customer.IDCardNumber,
+			&party.Customer.FullName,
+			&party.Customer.BirthDate,
+			&party.Customer.PhoneNumber,
+			&party.Customer.Email,
+			&party.Customer.MonthlyIncome,
+			&party.Customer.AddressStreet,
+			&party.Customer.AddressCity,
+		)
+		if err != nil {
+			return nil, err
+		}
+		parties = append(parties, party)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return parties, nil
+}
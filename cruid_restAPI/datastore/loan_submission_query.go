@@ -0,0 +1,174 @@
+package datastore
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	SubmissionSortCreatedAt  = "created_at"
+	SubmissionSortLoanAmount = "proposed_loan_amount"
+)
+
+// LoanSubmissionCursor points at the last submission of a page. The next page
+// starts right after it in the sort order of the query.
+type LoanSubmissionCursor struct {
+	SortValue    int64
+	SubmissionID string
+}
+
+// LoanSubmissionQuery selects a page of loan submissions. Empty strings and
+// nil pointers leave a filter out; amount and created-at ranges are inclusive.
+type LoanSubmissionQuery struct {
+	LoanStatus          string
+	VehicleType         string
+	IsCommercialVehicle *bool
+	MinLoanAmount       *int
+	MaxLoanAmount       *int
+	CreatedFrom         *int64
+	CreatedTo           *int64
+	CustomerID          string
+
+	SortBy     string
+	Descending bool
+	After      *LoanSubmissionCursor
+	Limit      int
+}
+
+func IsValidSubmissionSort(sortBy string) bool {
+	return sortBy == SubmissionSortCreatedAt || sortBy == SubmissionSortLoanAmount
+}
+
+// SortValue returns the value submission is ordered by in the query.
+func (q LoanSubmissionQuery) SortValue(submission *LoanSubmissionRow) int64 {
+	if q.SortBy == SubmissionSortLoanAmount {
+		return int64(submission.ProposedLoanAmount)
+	}
+
+	return submission.CreatedAt
+}
+
+// Matches reports whether submission passes the filters of the query,
+// ignoring the cursor.
+func (q LoanSubmissionQuery) Matches(submission *LoanSubmissionRow) bool {
+	switch {
+	case q.LoanStatus != "" && submission.LoanStatus != q.LoanStatus:
+		return false
+	case q.VehicleType != "" && submission.VehicleType != q.VehicleType:
+		return false
+	case q.IsCommercialVehicle != nil && submission.IsCommercialVehicle != *q.IsCommercialVehicle:
+		return false
+	case q.MinLoanAmount != nil && submission.ProposedLoanAmount < *q.MinLoanAmount:
+		return false
+	case q.MaxLoanAmount != nil && submission.ProposedLoanAmount > *q.MaxLoanAmount:
+		return false
+	case q.CreatedFrom != nil && submission.CreatedAt < *q.CreatedFrom:
+		return false
+	case q.CreatedTo != nil && submission.CreatedAt > *q.CreatedTo:
+		return false
+	case q.CustomerID != "" && submission.CustomerID != q.CustomerID:
+		return false
+	}
+
+	return true
+}
+
+// isAfterCursor reports whether submission comes after the cursor of the query.
+func (q LoanSubmissionQuery) isAfterCursor(submission *LoanSubmissionRow) bool {
+	if q.After == nil {
+		return true
+	}
+
+	sortValue := q.SortValue(submission)
+	if sortValue == q.After.SortValue {
+		if q.Descending {
+			return submission.SubmissionID < q.After.SubmissionID
+		}
+
+		return submission.SubmissionID > q.After.SubmissionID
+	}
+
+	if q.Descending {
+		return sortValue < q.After.SortValue
+	}
+
+	return sortValue > q.After.SortValue
+}
+
+// whereClause returns the WHERE and ORDER BY clauses of the query with their
+// arguments, numbered from $1.
+func (q LoanSubmissionQuery) whereClause() (string, []any) {
+	var conditions []string
+	var args []any
+
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if q.LoanStatus != "" {
+		addCondition("loan_status = $%d", q.LoanStatus)
+	}
+
+	if q.VehicleType != "" {
+		addCondition("vehicle_type = $%d", q.VehicleType)
+	}
+
+	if q.IsCommercialVehicle != nil {
+		addCondition("is_commercial_vehicle = $%d", *q.IsCommercialVehicle)
+	}
+
+	if q.MinLoanAmount != nil {
+		addCondition("proposed_loan_amount >= $%d", *q.MinLoanAmount)
+	}
+
+	if q.MaxLoanAmount != nil {
+		addCondition("proposed_loan_amount <= $%d", *q.MaxLoanAmount)
+	}
+
+	if q.CreatedFrom != nil {
+		addCondition("created_at >= $%d", *q.CreatedFrom)
+	}
+
+	if q.CreatedTo != nil {
+		addCondition("created_at <= $%d", *q.CreatedTo)
+	}
+
+	if q.CustomerID != "" {
+		addCondition("customer_id = $%d", q.CustomerID)
+	}
+
+	sortColumn := SubmissionSortCreatedAt
+	if q.SortBy == SubmissionSortLoanAmount {
+		sortColumn = SubmissionSortLoanAmount
+	}
+
+	comparison, direction := ">", "ASC"
+	if q.Descending {
+		comparison, direction = "<", "DESC"
+	}
+
+	if q.After != nil {
+		args = append(args, q.After.SortValue, q.After.SubmissionID)
+		conditions = append(conditions, fmt.Sprintf(
+			"(%[1]s %[2]s $%[3]d OR (%[1]s = $%[3]d AND submission_id %[2]s $%[4]d))",
+			sortColumn, comparison, len(args)-1, len(args),
+		))
+	}
+
+	var clause strings.Builder
+	if len(conditions) > 0 {
+		clause.WriteString("WHERE ")
+		clause.WriteString(strings.Join(conditions, " AND "))
+		clause.WriteString("\n")
+	}
+
+	fmt.Fprintf(&clause, "ORDER BY %[1]s %[2]s, submission_id %[2]s", sortColumn, direction)
+
+	if q.Limit > 0 {
+		args = append(args, q.Limit)
+		fmt.Fprintf(&clause, "\nLIMIT $%d", len(args))
+	}
+
+	return clause.String(), args
+}
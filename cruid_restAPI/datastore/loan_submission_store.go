@@ -1,28 +1,37 @@
 package datastore
 
 import (
+	"context"
 	"database/sql"
 )
 
 type LoanSubmissionRow struct {
-	SubmissionID         string
-	VehicleType          string
-	VehicleBrand         string
-	VehicleModel         string
-	VehicleLicenseNumber string
-	VehicleOdometer      int
-	ManufacturingYear    int
-	ProposedLoanAmount   int
-	ProposedLoanTenure   int
-	LoanStatus           string
-	IsCommercialVehicle  bool
-	CreatedAt            int64
-	UpdatedAt            int64
-	CustomerID           string
+	SubmissionID               string
+	VehicleType                string
+	VehicleBrand               string
+	VehicleModel               string
+	VehicleLicenseNumber       string
+	VehicleRegionCode          sql.NullString
+	VehicleOdometer            int
+	ManufacturingYear          int
+	ProposedLoanAmount         int
+	ProposedLoanTenure         int
+	AnnualInterestRate         float64
+	InterestMethod             string
+	ProductCode                sql.NullString
+	CollateralValue            sql.NullFloat64
+	LoanToValue                sql.NullFloat64
+	LoanStatus                 string
+	IsCommercialVehicle        bool
+	CreatedAt                  int64
+	UpdatedAt                  int64
+	CustomerID                 string
+	PolicyVersion              sql.NullInt64
+	UnderwritingRecommendation sql.NullString
 }
 
 type LoanSubmissionStore struct {
-	db *sql.DB
+	db DBTX
 }
 
 func NewLoanSubmissionStore(db *sql.DB) *LoanSubmissionStore {
@@ -31,6 +40,14 @@ func NewLoanSubmissionStore(db *sql.DB) *LoanSubmissionStore {
 	}
 }
 
+// WithTx returns a copy of the store that runs its statements in tx. A tx
+// from a MemoryUnitOfWork leaves the statements outside any transaction.
+func (s *LoanSubmissionStore) WithTx(tx Tx) SubmissionRepository {
+	return &LoanSubmissionStore{
+		db: txDB(s.db, tx),
+	}
+}
+
 const sqlUpsertSubmission = `
 	INSERT INTO loan_submissions (
 		submission_id,		
@@ -46,9 +63,17 @@ const sqlUpsertSubmission = `
 		is_commercial_vehicle,	
 		created_at,			
 		updated_at,			
-		customer_id			
+		customer_id,
+		policy_version,
+		annual_interest_rate,
+		interest_method,
+		product_code,
+		collateral_value,
+		loan_to_value,
+		vehicle_region_code,
+		underwriting_recommendation
 	) VALUES (
-		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+		$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22
 	) ON CONFLICT (submission_id) DO UPDATE SET
 		vehicle_type = EXCLUDED.vehicle_type, 
 		vehicle_brand = EXCLUDED.vehicle_brand,
@@ -62,14 +87,22 @@ const sqlUpsertSubmission = `
 		is_commercial_vehicle = EXCLUDED.is_commercial_vehicle,
 		created_at = EXCLUDED.created_at,	
 	    updated_at = EXCLUDED.updated_at,	
-        customer_id = EXCLUDED.customer_id
+        customer_id = EXCLUDED.customer_id,
+		policy_version = EXCLUDED.policy_version,
+		annual_interest_rate = EXCLUDED.annual_interest_rate,
+		interest_method = EXCLUDED.interest_method,
+		product_code = EXCLUDED.product_code,
+		collateral_value = EXCLUDED.collateral_value,
+		loan_to_value = EXCLUDED.loan_to_value,
+		vehicle_region_code = EXCLUDED.vehicle_region_code,
+		underwriting_recommendation = EXCLUDED.underwriting_recommendation
 	RETURNING submission_id;
 `
 
-func (s *LoanSubmissionStore) UpsertSubmission(submission *LoanSubmissionRow) (string, error) {
+func (s *LoanSubmissionStore) UpsertSubmission(ctx context.Context, submission *LoanSubmissionRow) (string, error) {
 	var submissionID string
 
-	err := s.db.QueryRow(sqlUpsertSubmission,
+	err := s.db.QueryRowContext(ctx, sqlUpsertSubmission,
 		submission.SubmissionID,
 		submission.VehicleType,
 		submission.VehicleBrand,
@@ -84,6 +117,14 @@ func (s *LoanSubmissionStore) UpsertSubmission(submission *LoanSubmissionRow) (s
 		submission.CreatedAt,
 		submission.UpdatedAt,
 		submission.CustomerID,
+		submission.PolicyVersion,
+		submission.AnnualInterestRate,
+		submission.InterestMethod,
+		submission.ProductCode,
+		submission.CollateralValue,
+		submission.LoanToValue,
+		submission.VehicleRegionCode,
+		submission.UnderwritingRecommendation,
 	).Scan(&submissionID)
 
 	if err != nil {
@@ -101,19 +142,41 @@ SELECT
 	manufacturing_year, proposed_loan_amount,
 	proposed_loan_tenure_month, loan_status,
 	is_commercial_vehicle, created_at,
-	updated_at, customer_id
+	updated_at, customer_id,
+	policy_version, annual_interest_rate,
+	interest_method, product_code,
+	collateral_value, loan_to_value,
+	vehicle_region_code, underwriting_recommendation
 FROM loan_submissions
-ORDER BY created_at DESC;
 `
 
-func (s *LoanSubmissionStore) GetAllLoanSubmissions() ([]*LoanSubmissionRow, error) {
-	rows, err := s.db.Query(sqlGetAllLoanSubmissions)
+// GetAllLoanSubmissions returns the submissions matching query in its sort
+// order, at most query.Limit of them when a limit is set.
+func (s *LoanSubmissionStore) GetAllLoanSubmissions(ctx context.Context, query LoanSubmissionQuery) ([]*LoanSubmissionRow, error) {
+	var submissions []*LoanSubmissionRow
+	err := s.StreamLoanSubmissions(ctx, query, func(submission *LoanSubmissionRow) error {
+		submissions = append(submissions, submission)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+
+	return submissions, nil
+}
+
+// StreamLoanSubmissions calls fn with every submission matching query, in its
+// sort order, as it is read from the database, so the rows never have to fit
+// in memory together. It stops at the first error fn returns.
+func (s *LoanSubmissionStore) StreamLoanSubmissions(ctx context.Context, query LoanSubmissionQuery, fn func(*LoanSubmissionRow) error) error {
+	whereClause, args := query.whereClause()
+
+	rows, err := s.db.QueryContext(ctx, sqlGetAllLoanSubmissions+whereClause, args...)
+	if err != nil {
+		return err
+	}
 	defer rows.Close()
 
-	var submissions []*LoanSubmissionRow
 	for rows.Next() {
 		submission := &LoanSubmissionRow{}
 		err := rows.Scan(
@@ -131,18 +194,25 @@ func (s *LoanSubmissionStore) GetAllLoanSubmissions() ([]*LoanSubmissionRow, err
 			&submission.CreatedAt,
 			&submission.UpdatedAt,
 			&submission.CustomerID,
+			&submission.PolicyVersion,
+			&submission.AnnualInterestRate,
+			&submission.InterestMethod,
+			&submission.ProductCode,
+			&submission.CollateralValue,
+			&submission.LoanToValue,
+			&submission.VehicleRegionCode,
+			&submission.UnderwritingRecommendation,
 		)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		submissions = append(submissions, submission)
-	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
+		if err := fn(submission); err != nil {
+			return err
+		}
 	}
 
-	return submissions, nil
+	return rows.Err()
 }
 
 const sqlGetLoanSubmissionByID = `
@@ -153,13 +223,17 @@ SELECT
 	manufacturing_year, proposed_loan_amount,
 	proposed_loan_tenure_month, loan_status,
 	is_commercial_vehicle, created_at,
-	updated_at, customer_id
+	updated_at, customer_id,
+	policy_version, annual_interest_rate,
+	interest_method, product_code,
+	collateral_value, loan_to_value,
+	vehicle_region_code, underwriting_recommendation
 FROM loan_submissions
 WHERE submission_id = $1;
 `
 
-func (s *LoanSubmissionStore) GetLoanSubmissionByID(submissionID string) (*LoanSubmissionRow, error) {
-	row := s.db.QueryRow(sqlGetLoanSubmissionByID, submissionID)
+func (s *LoanSubmissionStore) GetLoanSubmissionByID(ctx context.Context, submissionID string) (*LoanSubmissionRow, error) {
+	row := s.db.QueryRowContext(ctx, sqlGetLoanSubmissionByID, submissionID)
 	submission := &LoanSubmissionRow{}
 	err := row.Scan(
 		&submission.SubmissionID,
@@ -176,6 +250,14 @@ func (s *LoanSubmissionStore) GetLoanSubmissionByID(submissionID string) (*LoanS
 		&submission.CreatedAt,
 		&submission.UpdatedAt,
 		&submission.CustomerID,
+		&submission.PolicyVersion,
+		&submission.AnnualInterestRate,
+		&submission.InterestMethod,
+		&submission.ProductCode,
+		&submission.CollateralValue,
+		&submission.LoanToValue,
+		&submission.VehicleRegionCode,
+		&submission.UnderwritingRecommendation,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -185,3 +267,84 @@ func (s *LoanSubmissionStore) GetLoanSubmissionByID(submissionID string) (*LoanS
 	}
 	return submission, nil
 }
+
+const sqlUpdateLoanStatusByID = `
+UPDATE loan_submissions
+SET
+	loan_status = $1,
+	updated_at = $2
+WHERE submission_id = $3 AND loan_status = $4
+RETURNING submission_id;
+`
+
+func (s *LoanSubmissionStore) UpdateLoanStatusByID(ctx context.Context, submissionIDToUpdate, fromStatus, toStatus string, updatedAt int64) (string, error) {
+	var submissionID string
+	err := s.db.QueryRowContext(ctx, sqlUpdateLoanStatusByID,
+		toStatus,
+		updatedAt,
+		submissionIDToUpdate,
+		fromStatus,
+	).Scan(&submissionID)
+
+	if err != nil {
+		return "", err
+	}
+
+	return submissionID, nil
+}
+
+const sqlUpdateUnderwritingByID = `
+UPDATE loan_submissions
+SET
+	policy_version = $1,
+	underwriting_recommendation = $2,
+	updated_at = $3
+WHERE submission_id = $4
+RETURNING submission_id;
+`
+
+func (s *LoanSubmissionStore) UpdateUnderwritingByID(ctx context.Context, submissionIDToUpdate string, policyVersion int, recommendation string, updatedAt int64) (string, error) {
+	var submissionID string
+	err := s.db.QueryRowContext(ctx, sqlUpdateUnderwritingByID,
+		policyVersion,
+		recommendation,
+		updatedAt,
+		submissionIDToUpdate,
+	).Scan(&submissionID)
+
+	if err != nil {
+		return "", err
+	}
+
+	return submissionID, nil
+}
+
+const sqlGetLoanSubmissionIDsByStatus = `
+SELECT submission_id
+FROM loan_submissions
+WHERE loan_status = $1
+ORDER BY created_at;
+`
+
+func (s *LoanSubmissionStore) GetLoanSubmissionIDsByStatus(ctx context.Context, loanStatus string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, sqlGetLoanSubmissionIDsByStatus, loanStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var submissionIDs []string
+	for rows.Next() {
+		var submissionID string
+		if err := rows.Scan(&submissionID); err != nil {
+			return nil, err
+		}
+		submissionIDs = append(submissionIDs, submissionID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return submissionIDs, nil
+}
@@ -0,0 +1,116 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+)
+
+type LoanUnderwritingResultRow struct {
+	SubmissionID string
+	RuleNumber   int
+	RuleName     string
+	Outcome      string
+	Reason       string
+}
+
+// LoanUnderwritingStore keeps the rule results of the last underwriting
+// evaluation of each submission, whose recommendation is on the submission.
+type LoanUnderwritingStore struct {
+	db DBTX
+}
+
+func NewLoanUnderwritingStore(db *sql.DB) *LoanUnderwritingStore {
+	return &LoanUnderwritingStore{
+		db: db,
+	}
+}
+
+// WithTx returns a copy of the store that runs its statements in tx. A tx
+// from a MemoryUnitOfWork leaves the statements outside any transaction.
+func (s *LoanUnderwritingStore) WithTx(tx Tx) *LoanUnderwritingStore {
+	return &LoanUnderwritingStore{
+		db: txDB(s.db, tx),
+	}
+}
+
+const sqlDeleteUnderwritingResultsBySubmissionID = `
+DELETE FROM loan_underwriting_results
+WHERE submission_id = $1;
+`
+
+const sqlInsertUnderwritingResult = `
+INSERT INTO loan_underwriting_results (
+	submission_id,
+	rule_number,
+	rule_name,
+	outcome,
+	reason
+) VALUES (
+	$1, $2, $3, $4, $5
+);
+`
+
+// ReplaceUnderwritingResults swaps the results of the previous evaluation of
+// the submission for results, numbering them in the order given.
+func (s *LoanUnderwritingStore) ReplaceUnderwritingResults(ctx context.Context, submissionID string, results []*LoanUnderwritingResultRow) error {
+	return inTransaction(ctx, s.db, func(tx DBTX) error {
+		if _, err := tx.ExecContext(ctx, sqlDeleteUnderwritingResultsBySubmissionID, submissionID); err != nil {
+			return err
+		}
+
+		for i, result := range results {
+			_, err := tx.ExecContext(ctx, sqlInsertUnderwritingResult,
+				submissionID,
+				i+1,
+				result.RuleName,
+				result.Outcome,
+				result.Reason,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+const sqlGetUnderwritingResultsBySubmissionID = `
+SELECT
+	submission_id, rule_number,
+	rule_name, outcome,
+	reason
+FROM loan_underwriting_results
+WHERE submission_id = $1
+ORDER BY rule_number;
+`
+
+func (s *LoanUnderwritingStore) GetUnderwritingResultsBySubmissionID(ctx context.Context, submissionID string) ([]*LoanUnderwritingResultRow, error) {
+	rows, err := s.db.QueryContext(ctx, sqlGetUnderwritingResultsBySubmissionID, submissionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*LoanUnderwritingResultRow
+	for rows.Next() {
+		result := &LoanUnderwritingResultRow{}
+		err := rows.Scan(
+			&result.SubmissionID,
+			&result.RuleNumber,
+			&result.RuleName,
+			&result.Outcome,
+			&result.Reason,
+		)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
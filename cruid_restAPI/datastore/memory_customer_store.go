@@ -0,0 +1,259 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// MemoryCustomerStore is an in-memory CustomerRepository. It reads and deletes
+// the submissions of its customers through the given MemorySubmissionStore,
+// and shares its unit of work.
+// A context is only checked before an operation starts.
+type MemoryCustomerStore struct {
+	*memoryCustomers
+	submissions *MemorySubmissionStore
+	tx          Tx
+}
+
+// memoryCustomers is the state shared by a MemoryCustomerStore and the copies
+// WithTx makes of it.
+type memoryCustomers struct {
+	mu          sync.RWMutex
+	customers   map[string]*LoanCustomerRow
+	customerIDs []string
+}
+
+func NewMemoryCustomerStore(submissions *MemorySubmissionStore) *MemoryCustomerStore {
+	return &MemoryCustomerStore{
+		memoryCustomers: &memoryCustomers{
+			customers: map[string]*LoanCustomerRow{},
+		},
+		submissions: submissions,
+	}
+}
+
+// WithTx returns a copy of the store whose writes, including the submissions
+// deleted along with a customer, are undone when the unit of work of tx rolls
+// back. tx must come from a MemoryUnitOfWork for that.
+func (s *MemoryCustomerStore) WithTx(tx Tx) CustomerRepository {
+	return &MemoryCustomerStore{
+		memoryCustomers: s.memoryCustomers,
+		submissions:     s.submissions,
+		tx:              tx,
+	}
+}
+
+// saveForRollback lets the unit of work of tx put the customers back when it
+// rolls back. The caller holds the lock.
+func (s *memoryCustomers) saveForRollback(tx Tx) {
+	if tx == nil {
+		return
+	}
+
+	tx.saveForRollback(s, func() func() {
+		customers := make(map[string]*LoanCustomerRow, len(s.customers))
+		for customerID, customer := range s.customers {
+			copied := *customer
+			customers[customerID] = &copied
+		}
+		customerIDs := slices.Clone(s.customerIDs)
+
+		return func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			s.customers = customers
+			s.customerIDs = customerIDs
+		}
+	})
+}
+
+// customerIDByIDCardNumber stands in for the unique index on id_card_number.
+// The caller holds the lock.
+func (s *MemoryCustomerStore) customerIDByIDCardNumber(idCardNumber string) (string, bool) {
+	for _, customerID := range s.customerIDs {
+		if s.customers[customerID].IDCardNumber == idCardNumber {
+			return customerID, true
+		}
+	}
+
+	return "", false
+}
+
+func (s *MemoryCustomerStore) UpsertCustomer(ctx context.Context, customer *LoanCustomerRow) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	defer s.submissions.unitOfWork.lockWrite(s.tx)()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saveForRollback(s.tx)
+
+	if customerID, ok := s.customerIDByIDCardNumber(customer.IDCardNumber); ok {
+		stored := *customer
+		stored.CustomerID = customerID
+		s.customers[customerID] = &stored
+
+		return customerID, nil
+	}
+
+	if _, ok := s.customers[customer.CustomerID]; ok {
+		return "", fmt.Errorf("customer %s already exists", customer.CustomerID)
+	}
+
+	stored := *customer
+	s.customers[customer.CustomerID] = &stored
+	s.customerIDs = append(s.customerIDs, customer.CustomerID)
+
+	return customer.CustomerID, nil
+}
+
+func (s *MemoryCustomerStore) GetAllCustomers(ctx context.Context, query LoanCustomerQuery) ([]*LoanCustomerRow, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var customers []*LoanCustomerRow
+	for _, customerID := range s.customerIDs {
+		customer := *s.customers[customerID]
+		if query.Matches(&customer) && query.isAfterCursor(&customer) {
+			customers = append(customers, &customer)
+		}
+	}
+
+	sort.Slice(customers, func(i, j int) bool {
+		if customers[i].FullName != customers[j].FullName {
+			return customers[i].FullName < customers[j].FullName
+		}
+		return customers[i].CustomerID < customers[j].CustomerID
+	})
+
+	if query.Limit > 0 && len(customers) > query.Limit {
+		customers = customers[:query.Limit]
+	}
+
+	return customers, nil
+}
+
+// StreamCustomers reads the matching rows up front, so fn may call the store.
+func (s *MemoryCustomerStore) StreamCustomers(ctx context.Context, query LoanCustomerQuery, fn func(*LoanCustomerRow) error) error {
+	customers, err := s.GetAllCustomers(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	for _, customer := range customers {
+		if err := fn(customer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryCustomerStore) GetLoanCustomerRowByID(ctx context.Context, customerID string) (*LoanCustomerRow, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	customer, ok := s.customers[customerID]
+	if !ok {
+		return nil, nil
+	}
+
+	copied := *customer
+	return &copied, nil
+}
+
+// GetCustomerByID behaves like the inner join of the database store: a
+// customer without submissions is reported as not found.
+func (s *MemoryCustomerStore) GetCustomerByID(ctx context.Context, customerID string) (*LoanCustomerWithAllSubmissionsRow, error) {
+	customer, err := s.GetLoanCustomerRowByID(ctx, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var submissions []*LoanSubmissionRow
+	if customer != nil {
+		submissions = s.submissions.getSubmissionsByCustomerID(customerID)
+	}
+
+	if len(submissions) == 0 {
+		return nil, fmt.Errorf("customer not found")
+	}
+
+	return &LoanCustomerWithAllSubmissionsRow{
+		LoanCustomerRow: customer,
+		LoanSubmissions: submissions,
+	}, nil
+}
+
+func (s *MemoryCustomerStore) UpdateCustomerByID(ctx context.Context, customer *LoanCustomerRow, customerIDToUpdate string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	defer s.submissions.unitOfWork.lockWrite(s.tx)()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saveForRollback(s.tx)
+
+	stored, ok := s.customers[customerIDToUpdate]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+
+	if customerID, ok := s.customerIDByIDCardNumber(customer.IDCardNumber); ok && customerID != customerIDToUpdate {
+		return "", fmt.Errorf("id_card_number %s belongs to another customer", customer.IDCardNumber)
+	}
+
+	updated := *customer
+	updated.CustomerID = customerIDToUpdate
+	if !customer.Email.Valid {
+		updated.Email = stored.Email
+	}
+	s.customers[customerIDToUpdate] = &updated
+
+	return customerIDToUpdate, nil
+}
+
+func (s *MemoryCustomerStore) DeleteCustomerByID(ctx context.Context, customerIDToDelete string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	defer s.submissions.unitOfWork.lockWrite(s.tx)()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saveForRollback(s.tx)
+
+	if _, ok := s.customers[customerIDToDelete]; !ok {
+		return "", sql.ErrNoRows
+	}
+
+	delete(s.customers, customerIDToDelete)
+	s.customerIDs = slices.DeleteFunc(s.customerIDs, func(customerID string) bool {
+		return customerID == customerIDToDelete
+	})
+
+	s.submissions.deleteSubmissionsByCustomerID(s.tx, customerIDToDelete)
+
+	return customerIDToDelete, nil
+}
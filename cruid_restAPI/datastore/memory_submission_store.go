@@ -0,0 +1,279 @@
+package datastore
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemorySubmissionStore is an in-memory SubmissionRepository. Unlike the
+// database it does not check that the customer of a submission exists.
+type MemorySubmissionStore struct {
+	*memorySubmissions
+	tx Tx
+}
+
+// memorySubmissions is the state shared by a MemorySubmissionStore and the
+// copies WithTx makes of it. Writes outside a unit of work wait for the one
+// unitOfWork is running.
+type memorySubmissions struct {
+	unitOfWork    *MemoryUnitOfWork
+	mu            sync.RWMutex
+	submissions   map[string]*LoanSubmissionRow
+	submissionIDs []string
+}
+
+func NewMemorySubmissionStore(unitOfWork *MemoryUnitOfWork) *MemorySubmissionStore {
+	return &MemorySubmissionStore{
+		memorySubmissions: &memorySubmissions{
+			unitOfWork:  unitOfWork,
+			submissions: map[string]*LoanSubmissionRow{},
+		},
+	}
+}
+
+// WithTx returns a copy of the store whose writes are undone when the unit of
+// work of tx rolls back. tx must come from a MemoryUnitOfWork for that.
+func (s *MemorySubmissionStore) WithTx(tx Tx) SubmissionRepository {
+	return &MemorySubmissionStore{
+		memorySubmissions: s.memorySubmissions,
+		tx:                tx,
+	}
+}
+
+// saveForRollback lets the unit of work of tx put the submissions back when it
+// rolls back. The caller holds the lock.
+func (s *memorySubmissions) saveForRollback(tx Tx) {
+	if tx == nil {
+		return
+	}
+
+	tx.saveForRollback(s, func() func() {
+		submissions := make(map[string]*LoanSubmissionRow, len(s.submissions))
+		for submissionID, submission := range s.submissions {
+			copied := *submission
+			submissions[submissionID] = &copied
+		}
+		submissionIDs := slices.Clone(s.submissionIDs)
+
+		return func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			s.submissions = submissions
+			s.submissionIDs = submissionIDs
+		}
+	})
+}
+
+func (s *MemorySubmissionStore) UpsertSubmission(ctx context.Context, submission *LoanSubmissionRow) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	defer s.unitOfWork.lockWrite(s.tx)()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saveForRollback(s.tx)
+
+	if _, ok := s.submissions[submission.SubmissionID]; !ok {
+		s.submissionIDs = append(s.submissionIDs, submission.SubmissionID)
+	}
+
+	stored := *submission
+	s.submissions[submission.SubmissionID] = &stored
+
+	return submission.SubmissionID, nil
+}
+
+// sortedSubmissions returns copies of the submissions matching keep, oldest
+// first. The caller holds the lock.
+func (s *MemorySubmissionStore) sortedSubmissions(keep func(*LoanSubmissionRow) bool) []*LoanSubmissionRow {
+	var submissions []*LoanSubmissionRow
+	for _, submissionID := range s.submissionIDs {
+		submission := s.submissions[submissionID]
+		if keep(submission) {
+			copied := *submission
+			submissions = append(submissions, &copied)
+		}
+	}
+
+	sort.SliceStable(submissions, func(i, j int) bool {
+		return submissions[i].CreatedAt < submissions[j].CreatedAt
+	})
+
+	return submissions
+}
+
+func (s *MemorySubmissionStore) GetAllLoanSubmissions(ctx context.Context, query LoanSubmissionQuery) ([]*LoanSubmissionRow, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	submissions := s.sortedSubmissions(func(submission *LoanSubmissionRow) bool {
+		return query.Matches(submission) && query.isAfterCursor(submission)
+	})
+
+	slices.SortFunc(submissions, func(a, b *LoanSubmissionRow) int {
+		order := cmp.Or(
+			cmp.Compare(query.SortValue(a), query.SortValue(b)),
+			strings.Compare(a.SubmissionID, b.SubmissionID),
+		)
+		if query.Descending {
+			return -order
+		}
+
+		return order
+	})
+
+	if query.Limit > 0 && len(submissions) > query.Limit {
+		submissions = submissions[:query.Limit]
+	}
+
+	return submissions, nil
+}
+
+// StreamLoanSubmissions reads the matching rows up front, so fn may call the store.
+func (s *MemorySubmissionStore) StreamLoanSubmissions(ctx context.Context, query LoanSubmissionQuery, fn func(*LoanSubmissionRow) error) error {
+	submissions, err := s.GetAllLoanSubmissions(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	for _, submission := range submissions {
+		if err := fn(submission); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *MemorySubmissionStore) GetLoanSubmissionByID(ctx context.Context, submissionID string) (*LoanSubmissionRow, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	submission, ok := s.submissions[submissionID]
+	if !ok {
+		return nil, nil
+	}
+
+	copied := *submission
+	return &copied, nil
+}
+
+func (s *MemorySubmissionStore) UpdateLoanStatusByID(ctx context.Context, submissionIDToUpdate, fromStatus, toStatus string, updatedAt int64) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	defer s.unitOfWork.lockWrite(s.tx)()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saveForRollback(s.tx)
+
+	submission, ok := s.submissions[submissionIDToUpdate]
+	if !ok || submission.LoanStatus != fromStatus {
+		return "", sql.ErrNoRows
+	}
+
+	submission.LoanStatus = toStatus
+	submission.UpdatedAt = updatedAt
+
+	return submission.SubmissionID, nil
+}
+
+func (s *MemorySubmissionStore) UpdateUnderwritingByID(ctx context.Context, submissionIDToUpdate string, policyVersion int, recommendation string, updatedAt int64) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	defer s.unitOfWork.lockWrite(s.tx)()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saveForRollback(s.tx)
+
+	submission, ok := s.submissions[submissionIDToUpdate]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+
+	submission.PolicyVersion = sql.NullInt64{
+		Int64: int64(policyVersion),
+		Valid: true,
+	}
+	submission.UnderwritingRecommendation = sql.NullString{
+		String: recommendation,
+		Valid:  true,
+	}
+	submission.UpdatedAt = updatedAt
+
+	return submission.SubmissionID, nil
+}
+
+func (s *MemorySubmissionStore) GetLoanSubmissionIDsByStatus(ctx context.Context, loanStatus string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var submissionIDs []string
+	for _, submission := range s.sortedSubmissions(func(submission *LoanSubmissionRow) bool {
+		return submission.LoanStatus == loanStatus
+	}) {
+		submissionIDs = append(submissionIDs, submission.SubmissionID)
+	}
+
+	return submissionIDs, nil
+}
+
+// getSubmissionsByCustomerID returns the customer's submissions, newest first.
+func (s *MemorySubmissionStore) getSubmissionsByCustomerID(customerID string) []*LoanSubmissionRow {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	submissions := s.sortedSubmissions(func(submission *LoanSubmissionRow) bool {
+		return submission.CustomerID == customerID
+	})
+	slices.Reverse(submissions)
+
+	return submissions
+}
+
+// deleteSubmissionsByCustomerID mirrors the ON DELETE CASCADE from customers
+// to their submissions, as part of the unit of work of tx when it is not nil.
+// Outside a unit of work, the caller holds the write lock of the unit of work.
+func (s *MemorySubmissionStore) deleteSubmissionsByCustomerID(tx Tx, customerID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saveForRollback(tx)
+
+	s.submissionIDs = slices.DeleteFunc(s.submissionIDs, func(submissionID string) bool {
+		if s.submissions[submissionID].CustomerID != customerID {
+			return false
+		}
+
+		delete(s.submissions, submissionID)
+		return true
+	})
+}
@@ -0,0 +1,164 @@
+package datastore
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+)
+
+// migrateSQLiteThrough migrates a new SQLite database up to before, runs
+// statements to put the data a migration has to deal with in place, and then
+// migrates up to after. It returns the database and the error of the second
+// migration.
+func migrateSQLiteThrough(t *testing.T, before, after uint, statements ...string) (*sql.DB, error) {
+	t.Helper()
+
+	dsn := filepath.Join(t.TempDir(), "alphaloan.db")
+
+	m, err := migrate.New("file://"+filepath.Join("..", "db", "migration"), "sqlite3://"+dsn)
+	if err != nil {
+		t.Fatalf("failed to initialize migrations: %v", err)
+	}
+	t.Cleanup(func() { m.Close() })
+
+	if err := m.Migrate(before); err != nil {
+		t.Fatalf("migration to version %d failed: %v", before, err)
+	}
+
+	db, err := OpenDatabase(DatabaseConfig{Driver: SQLiteDriver, DSN: dsn})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("failed to run %q: %v", statement, err)
+		}
+	}
+
+	return db, m.Migrate(after)
+}
+
+const sqlInsertMigrationTestCustomer = `
+INSERT INTO loan_customers (customer_id, id_card_number, full_name, birth_date, phone_number, address_street, address_city)
+VALUES ('customer-1', '3171234567890001', 'Budi Santoso', '1990-01-01', '081234567890', 'Jl. Sudirman 1', 'Jakarta');
+`
+
+func insertMigrationTestSubmission(submissionID, plate, status string, createdAt int) string {
+	return fmt.Sprintf(`
+INSERT INTO loan_submissions (submission_id, vehicle_type, vehicle_brand, vehicle_model, vehicle_license_number,
+	manufacturing_year, loan_status, created_at, updated_at, customer_id)
+VALUES ('%s', 'car', 'Toyota', 'Avanza', '%s', 2020, '%s', %d, %d, 'customer-1');
+`, submissionID, plate, status, createdAt, createdAt)
+}
+
+func insertMigrationTestLien(submissionID, plate string, released bool) string {
+	releasedAt, releaseReason := "NULL", "NULL"
+	if released {
+		releasedAt, releaseReason = "2", "'CLOSED'"
+	}
+
+	return fmt.Sprintf(`
+INSERT INTO collateral_liens (submission_id, vehicle_license_number, pledged_at, released_at, release_reason)
+VALUES ('%s', '%s', 1, %s, %s);
+`, submissionID, plate, releasedAt, releaseReason)
+}
+
+func TestMigrationNormalizesPlates(t *testing.T) {
+	plate := "b\u00a01234\t xyz\u3000"
+
+	db, err := migrateSQLiteThrough(t, 11, 12,
+		sqlInsertMigrationTestCustomer,
+		insertMigrationTestSubmission("submission-1", plate, "DISBURSED", 1),
+		insertMigrationTestLien("submission-1", plate, false),
+	)
+	if err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	var submissionPlate, lienPlate string
+	err = db.QueryRow(`
+		SELECT loan_submissions.vehicle_license_number, collateral_liens.vehicle_license_number
+		FROM loan_submissions
+		JOIN collateral_liens ON collateral_liens.submission_id = loan_submissions.submission_id
+	`).Scan(&submissionPlate, &lienPlate)
+	if err != nil {
+		t.Fatalf("failed to read the plates: %v", err)
+	}
+
+	if submissionPlate != "B1234XYZ" || lienPlate != "B1234XYZ" {
+		t.Errorf("plates = %q and %q, want both B1234XYZ", submissionPlate, lienPlate)
+	}
+}
+
+func TestMigrationFailsOnActiveLiensSharingANormalizedPlate(t *testing.T) {
+	_, err := migrateSQLiteThrough(t, 11, 12,
+		sqlInsertMigrationTestCustomer,
+		insertMigrationTestSubmission("submission-1", "B 1234 XYZ", "DISBURSED", 1),
+		insertMigrationTestSubmission("submission-2", "b1234xyz", "APPROVED", 2),
+		insertMigrationTestSubmission("submission-3", "B1234 XYZ", "CLOSED", 3),
+		insertMigrationTestLien("submission-1", "B 1234 XYZ", false),
+		insertMigrationTestLien("submission-2", "b1234xyz", false),
+		insertMigrationTestLien("submission-3", "B1234 XYZ", true),
+	)
+	if err == nil {
+		t.Fatal("migration succeeded, want it to fail on the shared plate")
+	}
+
+	// The released lien of the same vehicle is no conflict.
+	want := "release or correct them first: submission-1, submission-2'"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("migration error = %q, want it to name the conflicting submissions: %s", err, want)
+	}
+}
+
+func TestMigrationReleasesLiensOfDuplicateOpenLoans(t *testing.T) {
+	db, err := migrateSQLiteThrough(t, 10, 11,
+		sqlInsertMigrationTestCustomer,
+		insertMigrationTestSubmission("submission-1", "B1234XYZ", "CLOSED", 1),
+		insertMigrationTestSubmission("submission-2", "B1234XYZ", "DISBURSED", 2),
+		insertMigrationTestSubmission("submission-3", "B1234XYZ", "UNDER_REVIEW", 3),
+		insertMigrationTestSubmission("submission-4", "B5678XYZ", "NEW", 4),
+	)
+	if err != nil {
+		t.Fatalf("migration failed: %v", err)
+	}
+
+	rows, err := db.Query(`
+		SELECT submission_id, released_at IS NOT NULL, release_reason
+		FROM collateral_liens
+		ORDER BY submission_id
+	`)
+	if err != nil {
+		t.Fatalf("failed to read the liens: %v", err)
+	}
+	defer rows.Close()
+
+	var got []string
+	for rows.Next() {
+		var submissionID string
+		var released bool
+		var releaseReason sql.NullString
+		if err := rows.Scan(&submissionID, &released, &releaseReason); err != nil {
+			t.Fatalf("failed to scan a lien: %v", err)
+		}
+		got = append(got, fmt.Sprintf("%s %v %s", submissionID, released, releaseReason.String))
+	}
+
+	// The oldest open loan on the plate keeps it pledged, and the newer one
+	// still gets a lien, released so it can be reviewed.
+	want := []string{
+		"submission-1 true CLOSED",
+		"submission-2 false ",
+		"submission-3 true DUPLICATE_AT_MIGRATION",
+		"submission-4 false ",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("liens =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
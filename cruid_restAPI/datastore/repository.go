@@ -0,0 +1,42 @@
+package datastore
+
+import (
+	"context"
+)
+
+// CustomerRepository is the customer storage the handlers depend on.
+// LoanCustomerStore keeps customers in the database and MemoryCustomerStore
+// keeps them in memory.
+type CustomerRepository interface {
+	// WithTx returns a repository that takes part in tx, see UnitOfWork.
+	WithTx(tx Tx) CustomerRepository
+	UpsertCustomer(ctx context.Context, customer *LoanCustomerRow) (string, error)
+	GetAllCustomers(ctx context.Context, query LoanCustomerQuery) ([]*LoanCustomerRow, error)
+	StreamCustomers(ctx context.Context, query LoanCustomerQuery, fn func(*LoanCustomerRow) error) error
+	GetLoanCustomerRowByID(ctx context.Context, customerID string) (*LoanCustomerRow, error)
+	GetCustomerByID(ctx context.Context, customerID string) (*LoanCustomerWithAllSubmissionsRow, error)
+	UpdateCustomerByID(ctx context.Context, customer *LoanCustomerRow, customerIDToUpdate string) (string, error)
+	DeleteCustomerByID(ctx context.Context, customerIDToDelete string) (string, error)
+}
+
+// SubmissionRepository is the loan submission storage the handlers depend on.
+// LoanSubmissionStore keeps submissions in the database and
+// MemorySubmissionStore keeps them in memory.
+type SubmissionRepository interface {
+	// WithTx returns a repository that takes part in tx, see UnitOfWork.
+	WithTx(tx Tx) SubmissionRepository
+	UpsertSubmission(ctx context.Context, submission *LoanSubmissionRow) (string, error)
+	GetAllLoanSubmissions(ctx context.Context, query LoanSubmissionQuery) ([]*LoanSubmissionRow, error)
+	StreamLoanSubmissions(ctx context.Context, query LoanSubmissionQuery, fn func(*LoanSubmissionRow) error) error
+	GetLoanSubmissionByID(ctx context.Context, submissionID string) (*LoanSubmissionRow, error)
+	UpdateLoanStatusByID(ctx context.Context, submissionIDToUpdate, fromStatus, toStatus string, updatedAt int64) (string, error)
+	UpdateUnderwritingByID(ctx context.Context, submissionIDToUpdate string, policyVersion int, recommendation string, updatedAt int64) (string, error)
+	GetLoanSubmissionIDsByStatus(ctx context.Context, loanStatus string) ([]string, error)
+}
+
+var (
+	_ CustomerRepository   = (*LoanCustomerStore)(nil)
+	_ CustomerRepository   = (*MemoryCustomerStore)(nil)
+	_ SubmissionRepository = (*LoanSubmissionStore)(nil)
+	_ SubmissionRepository = (*MemorySubmissionStore)(nil)
+)
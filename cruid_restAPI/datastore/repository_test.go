@@ -0,0 +1,577 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+)
+
+// repositories is one implementation of the repository interfaces, with the
+// unit of work its stores take part in.
+type repositories struct {
+	unitOfWork  UnitOfWork
+	customers   CustomerRepository
+	submissions SubmissionRepository
+}
+
+func newMemoryRepositories(t *testing.T) repositories {
+	unitOfWork := NewMemoryUnitOfWork()
+	submissions := NewMemorySubmissionStore(unitOfWork)
+
+	return repositories{
+		unitOfWork:  unitOfWork,
+		customers:   NewMemoryCustomerStore(submissions),
+		submissions: submissions,
+	}
+}
+
+func newSQLRepositories(db *sql.DB) repositories {
+	return repositories{
+		unitOfWork:  NewSQLUnitOfWork(db),
+		customers:   NewLoanCustomerStore(db),
+		submissions: NewLoanSubmissionStore(db),
+	}
+}
+
+// openMigratedDatabase opens the database of config and applies the
+// migrations of its engine to it.
+func openMigratedDatabase(t *testing.T, config DatabaseConfig) *sql.DB {
+	t.Helper()
+
+	m, err := migrate.New("file://"+config.MigrationFolder(filepath.Join("..", "db", "migration")), config.MigrationURL())
+	if err != nil {
+		t.Fatalf("failed to initialize migrations: %v", err)
+	}
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		t.Fatalf("migration failed: %v", err)
+	}
+	m.Close()
+
+	db, err := OpenDatabase(config)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func newSQLiteRepositories(t *testing.T) repositories {
+	return newSQLRepositories(openMigratedDatabase(t, DatabaseConfig{
+		Driver: SQLiteDriver,
+		DSN:    filepath.Join(t.TempDir(), "alphaloan.db"),
+	}))
+}
+
+// newPostgresRepositories migrates a schema of its own in the database of
+// TEST_DATABASE_URL and drops it again when the test ends.
+func newPostgresRepositories(t *testing.T) repositories {
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+
+	admin, err := sql.Open(PostgresDriver, databaseURL)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	schema := fmt.Sprintf("alphaloan_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		admin.Close()
+		t.Fatalf("failed to create schema %s: %v", schema, err)
+	}
+
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Errorf("failed to drop schema %s: %v", schema, err)
+		}
+		admin.Close()
+	})
+
+	dsn, err := url.Parse(databaseURL)
+	if err != nil {
+		t.Fatalf("TEST_DATABASE_URL must be a postgres:// URL: %v", err)
+	}
+	params := dsn.Query()
+	params.Set("search_path", schema+",public")
+	dsn.RawQuery = params.Encode()
+
+	return newSQLRepositories(openMigratedDatabase(t, DatabaseConfig{
+		Driver: PostgresDriver,
+		DSN:    dsn.String(),
+	}))
+}
+
+func TestMemoryRepositories(t *testing.T) {
+	testRepositories(t, newMemoryRepositories)
+}
+
+func TestSQLiteRepositories(t *testing.T) {
+	if !sqliteFTS5Enabled {
+		t.Skip("the SQLite migrations need FTS5: run with -tags sqlite_fts5")
+	}
+
+	testRepositories(t, newSQLiteRepositories)
+}
+
+func TestPostgresRepositories(t *testing.T) {
+	if os.Getenv("TEST_DATABASE_URL") == "" {
+		t.Skip("set TEST_DATABASE_URL to a PostgreSQL URL to run the PostgreSQL tests")
+	}
+
+	testRepositories(t, newPostgresRepositories)
+}
+
+// testRepositories checks the behaviour the handlers rely on from every
+// implementation of the repository interfaces. Each test gets empty
+// repositories from newRepositories.
+func testRepositories(t *testing.T, newRepositories func(t *testing.T) repositories) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, ctx context.Context, repos repositories)
+	}{
+		{"UpsertCustomerByIDCardNumber", testUpsertCustomerByIDCardNumber},
+		{"GetMissingCustomer", testGetMissingCustomer},
+		{"UpdateCustomer", testUpdateCustomer},
+		{"DeleteCustomerCascades", testDeleteCustomerCascades},
+		{"ListCustomers", testListCustomers},
+		{"UpsertAndGetSubmission", testUpsertAndGetSubmission},
+		{"UpdateLoanStatusFromStatus", testUpdateLoanStatusFromStatus},
+		{"ListSubmissions", testListSubmissions},
+		{"UnitOfWorkCommits", testUnitOfWorkCommits},
+		{"UnitOfWorkRollsBack", testUnitOfWorkRollsBack},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, context.Background(), newRepositories(t))
+		})
+	}
+}
+
+func testCustomer(customerID, idCardNumber, fullName, city string) *LoanCustomerRow {
+	return &LoanCustomerRow{
+		CustomerID:    customerID,
+		IDCardNumber:  idCardNumber,
+		FullName:      fullName,
+		BirthDate:     "1990-01-01",
+		PhoneNumber:   "081234567890",
+		MonthlyIncome: 10_000_000,
+		AddressStreet: "Jl. Sudirman 1",
+		AddressCity:   city,
+	}
+}
+
+func testSubmission(submissionID, customerID string, loanAmount int, createdAt int64) *LoanSubmissionRow {
+	return &LoanSubmissionRow{
+		SubmissionID:         submissionID,
+		VehicleType:          "car",
+		VehicleBrand:         "Toyota",
+		VehicleModel:         "Avanza",
+		VehicleLicenseNumber: "B 1234 " + submissionID,
+		VehicleOdometer:      10_000,
+		ManufacturingYear:    2020,
+		ProposedLoanAmount:   loanAmount,
+		ProposedLoanTenure:   24,
+		AnnualInterestRate:   9.5,
+		InterestMethod:       "FLAT",
+		LoanStatus:           "NEW",
+		CreatedAt:            createdAt,
+		UpdatedAt:            createdAt,
+		CustomerID:           customerID,
+	}
+}
+
+func mustUpsertCustomer(t *testing.T, ctx context.Context, repos repositories, customer *LoanCustomerRow) {
+	t.Helper()
+
+	if _, err := repos.customers.UpsertCustomer(ctx, customer); err != nil {
+		t.Fatalf("UpsertCustomer(%s): %v", customer.CustomerID, err)
+	}
+}
+
+func mustUpsertSubmission(t *testing.T, ctx context.Context, repos repositories, submission *LoanSubmissionRow) {
+	t.Helper()
+
+	if _, err := repos.submissions.UpsertSubmission(ctx, submission); err != nil {
+		t.Fatalf("UpsertSubmission(%s): %v", submission.SubmissionID, err)
+	}
+}
+
+func submissionIDs(submissions []*LoanSubmissionRow) []string {
+	var ids []string
+	for _, submission := range submissions {
+		ids = append(ids, submission.SubmissionID)
+	}
+	return ids
+}
+
+func customerIDs(customers []*LoanCustomerRow) []string {
+	var ids []string
+	for _, customer := range customers {
+		ids = append(ids, customer.CustomerID)
+	}
+	return ids
+}
+
+func testUpsertCustomerByIDCardNumber(t *testing.T, ctx context.Context, repos repositories) {
+	mustUpsertCustomer(t, ctx, repos, testCustomer("c1", "3171000000000001", "Budi", "Jakarta"))
+
+	customerID, err := repos.customers.UpsertCustomer(ctx, testCustomer("c2", "3171000000000001", "Budi Santoso", "Bandung"))
+	if err != nil {
+		t.Fatalf("UpsertCustomer with a known id card: %v", err)
+	}
+	if customerID != "c1" {
+		t.Errorf("UpsertCustomer with a known id card returned %q, want the existing c1", customerID)
+	}
+
+	customer, err := repos.customers.GetLoanCustomerRowByID(ctx, "c1")
+	if err != nil || customer == nil {
+		t.Fatalf("GetLoanCustomerRowByID(c1) = %v, %v", customer, err)
+	}
+	if customer.FullName != "Budi Santoso" || customer.AddressCity != "Bandung" {
+		t.Errorf("customer after upsert = %s in %s, want Budi Santoso in Bandung", customer.FullName, customer.AddressCity)
+	}
+
+	if customer, _ := repos.customers.GetLoanCustomerRowByID(ctx, "c2"); customer != nil {
+		t.Errorf("upsert with a known id card created customer c2")
+	}
+}
+
+func testGetMissingCustomer(t *testing.T, ctx context.Context, repos repositories) {
+	customer, err := repos.customers.GetLoanCustomerRowByID(ctx, "missing")
+	if customer != nil || err != nil {
+		t.Errorf("GetLoanCustomerRowByID(missing) = %v, %v, want nil, nil", customer, err)
+	}
+
+	// A customer without submissions is not found either.
+	mustUpsertCustomer(t, ctx, repos, testCustomer("c1", "3171000000000001", "Budi", "Jakarta"))
+	if _, err := repos.customers.GetCustomerByID(ctx, "c1"); err == nil {
+		t.Errorf("GetCustomerByID of a customer without submissions succeeded")
+	}
+}
+
+func testUpdateCustomer(t *testing.T, ctx context.Context, repos repositories) {
+	mustUpsertCustomer(t, ctx, repos, testCustomer("c1", "3171000000000001", "Budi", "Jakarta"))
+	mustUpsertCustomer(t, ctx, repos, testCustomer("c2", "3171000000000002", "Siti", "Jakarta"))
+
+	if _, err := repos.customers.UpdateCustomerByID(ctx, testCustomer("", "3171000000000009", "Nobody", "Jakarta"), "missing"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("UpdateCustomerByID(missing) error = %v, want sql.ErrNoRows", err)
+	}
+
+	if _, err := repos.customers.UpdateCustomerByID(ctx, testCustomer("", "3171000000000002", "Budi", "Jakarta"), "c1"); err == nil {
+		t.Errorf("UpdateCustomerByID to the id card of another customer succeeded")
+	}
+
+	customerID, err := repos.customers.UpdateCustomerByID(ctx, testCustomer("", "3171000000000001", "Budi Santoso", "Surabaya"), "c1")
+	if err != nil || customerID != "c1" {
+		t.Fatalf("UpdateCustomerByID(c1) = %q, %v", customerID, err)
+	}
+
+	customer, err := repos.customers.GetLoanCustomerRowByID(ctx, "c1")
+	if err != nil || customer == nil {
+		t.Fatalf("GetLoanCustomerRowByID(c1) = %v, %v", customer, err)
+	}
+	if customer.FullName != "Budi Santoso" || customer.AddressCity != "Surabaya" {
+		t.Errorf("customer after update = %s in %s, want Budi Santoso in Surabaya", customer.FullName, customer.AddressCity)
+	}
+}
+
+func testDeleteCustomerCascades(t *testing.T, ctx context.Context, repos repositories) {
+	mustUpsertCustomer(t, ctx, repos, testCustomer("c1", "3171000000000001", "Budi", "Jakarta"))
+	mustUpsertCustomer(t, ctx, repos, testCustomer("c2", "3171000000000002", "Siti", "Jakarta"))
+	mustUpsertSubmission(t, ctx, repos, testSubmission("s1", "c1", 50_000_000, 100))
+	mustUpsertSubmission(t, ctx, repos, testSubmission("s2", "c2", 50_000_000, 200))
+
+	if _, err := repos.customers.DeleteCustomerByID(ctx, "missing"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("DeleteCustomerByID(missing) error = %v, want sql.ErrNoRows", err)
+	}
+
+	if customerID, err := repos.customers.DeleteCustomerByID(ctx, "c1"); err != nil || customerID != "c1" {
+		t.Fatalf("DeleteCustomerByID(c1) = %q, %v", customerID, err)
+	}
+
+	if submission, err := repos.submissions.GetLoanSubmissionByID(ctx, "s1"); submission != nil || err != nil {
+		t.Errorf("submission s1 of the deleted customer = %v, %v, want it deleted", submission, err)
+	}
+	if submission, err := repos.submissions.GetLoanSubmissionByID(ctx, "s2"); submission == nil || err != nil {
+		t.Errorf("submission s2 of another customer = %v, %v, want it kept", submission, err)
+	}
+}
+
+func testListCustomers(t *testing.T, ctx context.Context, repos repositories) {
+	mustUpsertCustomer(t, ctx, repos, testCustomer("c3", "3171000000000003", "Citra", "Bandung"))
+	mustUpsertCustomer(t, ctx, repos, testCustomer("c2", "3171000000000002", "Budi", "jakarta"))
+	mustUpsertCustomer(t, ctx, repos, testCustomer("c1", "3171000000000001", "Budi", "Jakarta"))
+	mustUpsertCustomer(t, ctx, repos, testCustomer("c4", "3171000000000004", "Andi", "Jakarta"))
+
+	tests := []struct {
+		name  string
+		query LoanCustomerQuery
+		want  []string
+	}{
+		{"by name then id", LoanCustomerQuery{}, []string{"c4", "c1", "c2", "c3"}},
+		{"city ignoring case", LoanCustomerQuery{City: "JAKARTA"}, []string{"c4", "c1", "c2"}},
+		{"limited", LoanCustomerQuery{Limit: 2}, []string{"c4", "c1"}},
+		{"after cursor", LoanCustomerQuery{After: &LoanCustomerCursor{FullName: "Budi", CustomerID: "c1"}}, []string{"c2", "c3"}},
+	}
+
+	for _, tt := range tests {
+		customers, err := repos.customers.GetAllCustomers(ctx, tt.query)
+		if err != nil {
+			t.Fatalf("GetAllCustomers(%s): %v", tt.name, err)
+		}
+		if got := customerIDs(customers); !slices.Equal(got, tt.want) {
+			t.Errorf("GetAllCustomers(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+
+		var streamed []*LoanCustomerRow
+		err = repos.customers.StreamCustomers(ctx, tt.query, func(customer *LoanCustomerRow) error {
+			streamed = append(streamed, customer)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("StreamCustomers(%s): %v", tt.name, err)
+		}
+		if got := customerIDs(streamed); !slices.Equal(got, tt.want) {
+			t.Errorf("StreamCustomers(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func testUpsertAndGetSubmission(t *testing.T, ctx context.Context, repos repositories) {
+	mustUpsertCustomer(t, ctx, repos, testCustomer("c1", "3171000000000001", "Budi", "Jakarta"))
+
+	if submission, err := repos.submissions.GetLoanSubmissionByID(ctx, "missing"); submission != nil || err != nil {
+		t.Errorf("GetLoanSubmissionByID(missing) = %v, %v, want nil, nil", submission, err)
+	}
+
+	want := testSubmission("s1", "c1", 50_000_000, 100)
+	want.ProductCode = sql.NullString{String: "CAR_STANDARD", Valid: true}
+	want.PolicyVersion = sql.NullInt64{Int64: 3, Valid: true}
+	mustUpsertSubmission(t, ctx, repos, want)
+
+	got, err := repos.submissions.GetLoanSubmissionByID(ctx, "s1")
+	if err != nil || got == nil {
+		t.Fatalf("GetLoanSubmissionByID(s1) = %v, %v", got, err)
+	}
+	if *got != *want {
+		t.Errorf("GetLoanSubmissionByID(s1) = %+v, want %+v", *got, *want)
+	}
+
+	want.ProposedLoanAmount = 60_000_000
+	mustUpsertSubmission(t, ctx, repos, want)
+
+	got, err = repos.submissions.GetLoanSubmissionByID(ctx, "s1")
+	if err != nil || got == nil || got.ProposedLoanAmount != 60_000_000 {
+		t.Errorf("GetLoanSubmissionByID(s1) after upsert = %v, %v, want the new loan amount", got, err)
+	}
+
+	customer, err := repos.customers.GetCustomerByID(ctx, "c1")
+	if err != nil {
+		t.Fatalf("GetCustomerByID(c1): %v", err)
+	}
+	if got := submissionIDs(customer.LoanSubmissions); !slices.Equal(got, []string{"s1"}) {
+		t.Errorf("submissions of c1 = %v, want [s1]", got)
+	}
+}
+
+func testUpdateLoanStatusFromStatus(t *testing.T, ctx context.Context, repos repositories) {
+	mustUpsertCustomer(t, ctx, repos, testCustomer("c1", "3171000000000001", "Budi", "Jakarta"))
+	mustUpsertSubmission(t, ctx, repos, testSubmission("s1", "c1", 50_000_000, 100))
+
+	if _, err := repos.submissions.UpdateLoanStatusByID(ctx, "s1", "UNDER_REVIEW", "APPROVED", 200); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("UpdateLoanStatusByID from a status the submission is not in: error = %v, want sql.ErrNoRows", err)
+	}
+
+	if _, err := repos.submissions.UpdateLoanStatusByID(ctx, "missing", "NEW", "UNDER_REVIEW", 200); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("UpdateLoanStatusByID(missing) error = %v, want sql.ErrNoRows", err)
+	}
+
+	if submissionID, err := repos.submissions.UpdateLoanStatusByID(ctx, "s1", "NEW", "UNDER_REVIEW", 200); err != nil || submissionID != "s1" {
+		t.Fatalf("UpdateLoanStatusByID(s1) = %q, %v", submissionID, err)
+	}
+
+	if _, err := repos.submissions.UpdateUnderwritingByID(ctx, "s1", 7, "REFER", 300); err != nil {
+		t.Fatalf("UpdateUnderwritingByID(s1): %v", err)
+	}
+
+	submission, err := repos.submissions.GetLoanSubmissionByID(ctx, "s1")
+	if err != nil || submission == nil {
+		t.Fatalf("GetLoanSubmissionByID(s1) = %v, %v", submission, err)
+	}
+	if submission.LoanStatus != "UNDER_REVIEW" || submission.PolicyVersion.Int64 != 7 ||
+		submission.UnderwritingRecommendation.String != "REFER" || submission.UpdatedAt != 300 {
+		t.Errorf("submission = %s, policy %d, recommendation %q, updated at %d, want UNDER_REVIEW, policy 7, REFER, updated at 300",
+			submission.LoanStatus, submission.PolicyVersion.Int64, submission.UnderwritingRecommendation.String, submission.UpdatedAt)
+	}
+
+	submissionIDs, err := repos.submissions.GetLoanSubmissionIDsByStatus(ctx, "UNDER_REVIEW")
+	if err != nil || !slices.Equal(submissionIDs, []string{"s1"}) {
+		t.Errorf("GetLoanSubmissionIDsByStatus(UNDER_REVIEW) = %v, %v, want [s1]", submissionIDs, err)
+	}
+}
+
+func testListSubmissions(t *testing.T, ctx context.Context, repos repositories) {
+	mustUpsertCustomer(t, ctx, repos, testCustomer("c1", "3171000000000001", "Budi", "Jakarta"))
+	mustUpsertCustomer(t, ctx, repos, testCustomer("c2", "3171000000000002", "Siti", "Jakarta"))
+	mustUpsertSubmission(t, ctx, repos, testSubmission("s1", "c1", 30_000_000, 100))
+	mustUpsertSubmission(t, ctx, repos, testSubmission("s2", "c1", 90_000_000, 200))
+	mustUpsertSubmission(t, ctx, repos, testSubmission("s3", "c2", 60_000_000, 200))
+	mustUpsertSubmission(t, ctx, repos, testSubmission("s4", "c2", 60_000_000, 300))
+
+	minAmount := 50_000_000
+
+	tests := []struct {
+		name  string
+		query LoanSubmissionQuery
+		want  []string
+	}{
+		{"oldest first", LoanSubmissionQuery{}, []string{"s1", "s2", "s3", "s4"}},
+		{"newest first", LoanSubmissionQuery{Descending: true}, []string{"s4", "s3", "s2", "s1"}},
+		{"by loan amount", LoanSubmissionQuery{SortBy: SubmissionSortLoanAmount}, []string{"s1", "s3", "s4", "s2"}},
+		{"customer", LoanSubmissionQuery{CustomerID: "c2"}, []string{"s3", "s4"}},
+		{"minimum amount", LoanSubmissionQuery{MinLoanAmount: &minAmount, Limit: 2}, []string{"s2", "s3"}},
+		{"after cursor", LoanSubmissionQuery{After: &LoanSubmissionCursor{SortValue: 200, SubmissionID: "s2"}}, []string{"s3", "s4"}},
+	}
+
+	for _, tt := range tests {
+		submissions, err := repos.submissions.GetAllLoanSubmissions(ctx, tt.query)
+		if err != nil {
+			t.Fatalf("GetAllLoanSubmissions(%s): %v", tt.name, err)
+		}
+		if got := submissionIDs(submissions); !slices.Equal(got, tt.want) {
+			t.Errorf("GetAllLoanSubmissions(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+
+		var streamed []*LoanSubmissionRow
+		err = repos.submissions.StreamLoanSubmissions(ctx, tt.query, func(submission *LoanSubmissionRow) error {
+			streamed = append(streamed, submission)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("StreamLoanSubmissions(%s): %v", tt.name, err)
+		}
+		if got := submissionIDs(streamed); !slices.Equal(got, tt.want) {
+			t.Errorf("StreamLoanSubmissions(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func testUnitOfWorkCommits(t *testing.T, ctx context.Context, repos repositories) {
+	err := repos.unitOfWork.Do(ctx, func(tx Tx) error {
+		if _, err := repos.customers.WithTx(tx).UpsertCustomer(ctx, testCustomer("c1", "3171000000000001", "Budi", "Jakarta")); err != nil {
+			return err
+		}
+
+		_, err := repos.submissions.WithTx(tx).UpsertSubmission(ctx, testSubmission("s1", "c1", 50_000_000, 100))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	if customer, err := repos.customers.GetLoanCustomerRowByID(ctx, "c1"); customer == nil || err != nil {
+		t.Errorf("committed customer = %v, %v", customer, err)
+	}
+	if submission, err := repos.submissions.GetLoanSubmissionByID(ctx, "s1"); submission == nil || err != nil {
+		t.Errorf("committed submission = %v, %v", submission, err)
+	}
+}
+
+func testUnitOfWorkRollsBack(t *testing.T, ctx context.Context, repos repositories) {
+	mustUpsertCustomer(t, ctx, repos, testCustomer("c1", "3171000000000001", "Budi", "Jakarta"))
+	mustUpsertSubmission(t, ctx, repos, testSubmission("s1", "c1", 50_000_000, 100))
+
+	errRollback := errors.New("roll back")
+
+	err := repos.unitOfWork.Do(ctx, func(tx Tx) error {
+		customers, submissions := repos.customers.WithTx(tx), repos.submissions.WithTx(tx)
+
+		if _, err := customers.UpsertCustomer(ctx, testCustomer("c2", "3171000000000002", "Siti", "Jakarta")); err != nil {
+			return err
+		}
+		if _, err := submissions.UpsertSubmission(ctx, testSubmission("s2", "c2", 50_000_000, 200)); err != nil {
+			return err
+		}
+		if _, err := submissions.UpdateLoanStatusByID(ctx, "s1", "NEW", "UNDER_REVIEW", 200); err != nil {
+			return err
+		}
+		if _, err := customers.DeleteCustomerByID(ctx, "c1"); err != nil {
+			return err
+		}
+
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("Do error = %v, want the error of fn", err)
+	}
+
+	if customer, err := repos.customers.GetLoanCustomerRowByID(ctx, "c2"); customer != nil || err != nil {
+		t.Errorf("customer c2 inserted by the rolled back unit of work = %v, %v, want none", customer, err)
+	}
+	if submission, err := repos.submissions.GetLoanSubmissionByID(ctx, "s2"); submission != nil || err != nil {
+		t.Errorf("submission s2 inserted by the rolled back unit of work = %v, %v, want none", submission, err)
+	}
+
+	if customer, err := repos.customers.GetLoanCustomerRowByID(ctx, "c1"); customer == nil || err != nil {
+		t.Errorf("customer c1 deleted by the rolled back unit of work = %v, %v, want it kept", customer, err)
+	}
+
+	submission, err := repos.submissions.GetLoanSubmissionByID(ctx, "s1")
+	if submission == nil || err != nil {
+		t.Fatalf("submission s1 deleted along with c1 by the rolled back unit of work = %v, %v, want it kept", submission, err)
+	}
+	if submission.LoanStatus != "NEW" {
+		t.Errorf("status of s1 after the rolled back unit of work = %s, want NEW", submission.LoanStatus)
+	}
+}
+
+// A write outside the unit of work waits for it, so rolling the unit of work
+// back does not undo the write.
+func TestMemoryUnitOfWorkRollbackKeepsOtherWrites(t *testing.T) {
+	ctx := context.Background()
+	repos := newMemoryRepositories(t)
+	errRollback := errors.New("roll back")
+	written := make(chan error)
+
+	err := repos.unitOfWork.Do(ctx, func(tx Tx) error {
+		if _, err := repos.customers.WithTx(tx).UpsertCustomer(ctx, testCustomer("c1", "3171000000000001", "Budi", "Jakarta")); err != nil {
+			return err
+		}
+
+		go func() {
+			_, err := repos.customers.UpsertCustomer(ctx, testCustomer("c2", "3171000000000002", "Siti", "Jakarta"))
+			written <- err
+		}()
+
+		select {
+		case err := <-written:
+			t.Errorf("write outside the unit of work returned %v before the unit of work ended", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("Do error = %v, want the error of fn", err)
+	}
+
+	if err := <-written; err != nil {
+		t.Fatalf("UpsertCustomer outside the unit of work: %v", err)
+	}
+
+	if customer, err := repos.customers.GetLoanCustomerRowByID(ctx, "c1"); customer != nil || err != nil {
+		t.Errorf("customer c1 inserted by the rolled back unit of work = %v, %v, want none", customer, err)
+	}
+	if customer, err := repos.customers.GetLoanCustomerRowByID(ctx, "c2"); customer == nil || err != nil {
+		t.Errorf("customer c2 written outside the unit of work = %v, %v, want it kept", customer, err)
+	}
+}
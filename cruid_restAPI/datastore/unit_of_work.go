@@ -0,0 +1,188 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// DBTX is satisfied by both *sql.DB and *sql.Tx, so a store can run its
+// statements on their own or as part of a UnitOfWork.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Tx is the transaction a UnitOfWork hands to fn. Stores take part in it
+// through their WithTx method. Only the unit of work implementations in this
+// package create one.
+type Tx interface {
+	// saveForRollback lets the unit of work put state back the way it was
+	// when it rolls back. Only the first write to a state calls snapshot, which
+	// copies the state and returns the function restoring that copy, so later
+	// writes neither copy it again nor replace the state from before the unit
+	// of work.
+	saveForRollback(state any, snapshot func() (restore func()))
+}
+
+// UnitOfWork groups the writes of several stores into one transaction:
+//
+//	err := unitOfWork.Do(ctx, func(tx datastore.Tx) error {
+//		customerID, err := customerStore.WithTx(tx).UpsertCustomer(ctx, customer)
+//		...
+//	})
+//
+// Do commits when fn returns nil and rolls back on an error, which it returns
+// unchanged so callers can inspect it.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(tx Tx) error) error
+}
+
+var (
+	_ UnitOfWork = (*SQLUnitOfWork)(nil)
+	_ UnitOfWork = (*MemoryUnitOfWork)(nil)
+)
+
+// SQLUnitOfWork runs a unit of work in a database transaction.
+type SQLUnitOfWork struct {
+	db *sql.DB
+}
+
+func NewSQLUnitOfWork(db *sql.DB) *SQLUnitOfWork {
+	return &SQLUnitOfWork{
+		db: db,
+	}
+}
+
+// sqlTx is the Tx of a SQLUnitOfWork. The database undoes the writes of a
+// rolled back transaction itself.
+type sqlTx struct {
+	*sql.Tx
+}
+
+func (tx sqlTx) saveForRollback(state any, snapshot func() (restore func())) {}
+
+// Do also rolls the transaction back when ctx is done before it commits.
+func (u *SQLUnitOfWork) Do(ctx context.Context, fn func(tx Tx) error) error {
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(sqlTx{tx}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MemoryUnitOfWork runs a unit of work against the in-memory stores. Units of
+// work run one at a time, and a rolled back one puts back the stores it wrote
+// to. Writes made outside a unit of work wait for the running one to finish,
+// so a rollback cannot undo them. Inside fn, write through the stores WithTx
+// returns: a write through the store itself would wait for fn forever.
+type MemoryUnitOfWork struct {
+	mu sync.Mutex
+}
+
+func NewMemoryUnitOfWork() *MemoryUnitOfWork {
+	return &MemoryUnitOfWork{}
+}
+
+// memoryTx is the Tx of a MemoryUnitOfWork.
+type memoryTx struct {
+	saved    map[any]bool
+	restores []func()
+}
+
+func (tx *memoryTx) saveForRollback(state any, snapshot func() (restore func())) {
+	if tx.saved[state] {
+		return
+	}
+
+	tx.saved[state] = true
+	tx.restores = append(tx.restores, snapshot())
+}
+
+// lockWrite makes a write of a memory store made outside any unit of work, as
+// when tx is nil, wait for the unit of work running. It returns the function
+// that unlocks again.
+func (u *MemoryUnitOfWork) lockWrite(tx Tx) (unlock func()) {
+	if tx != nil {
+		return func() {}
+	}
+
+	u.mu.Lock()
+	return u.mu.Unlock
+}
+
+// Do rolls back when fn fails or panics, or when ctx is done by the time fn
+// returns.
+func (u *MemoryUnitOfWork) Do(ctx context.Context, fn func(tx Tx) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	tx := &memoryTx{
+		saved: map[any]bool{},
+	}
+
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+
+		for i := len(tx.restores) - 1; i >= 0; i-- {
+			tx.restores[i]()
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	committed = true
+	return nil
+}
+
+// txDB returns what a database store given tx should run its statements on.
+// A Tx that is not a database transaction, such as that of a MemoryUnitOfWork,
+// leaves the store running its statements on their own.
+func txDB(db DBTX, tx Tx) DBTX {
+	if tx, ok := tx.(sqlTx); ok {
+		return tx.Tx
+	}
+
+	return db
+}
+
+// inTransaction runs fn inside the transaction db already belongs to, or in a
+// new one when db is the database itself.
+func inTransaction(ctx context.Context, db DBTX, fn func(tx DBTX) error) error {
+	sqlDB, ok := db.(*sql.DB)
+	if !ok {
+		return fn(db)
+	}
+
+	tx, err := sqlDB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,198 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+)
+
+type VehicleCatalogueRow struct {
+	CatalogueID  string
+	VehicleBrand string
+	VehicleModel string
+	VehicleType  string
+	CreatedAt    int64
+	UpdatedAt    int64
+}
+
+type VehicleCatalogueStore struct {
+	db *sql.DB
+}
+
+func NewVehicleCatalogueStore(db *sql.DB) *VehicleCatalogueStore {
+	return &VehicleCatalogueStore{
+		db: db,
+	}
+}
+
+const sqlInsertCatalogueEntry = `
+INSERT INTO vehicle_catalogue (
+	catalogue_id,
+	vehicle_brand,
+	vehicle_model,
+	vehicle_type,
+	created_at,
+	updated_at
+) VALUES (
+	$1, $2, $3, $4, $5, $6
+)
+RETURNING catalogue_id;
+`
+
+func (s *VehicleCatalogueStore) CreateCatalogueEntry(ctx context.Context, entry *VehicleCatalogueRow) (string, error) {
+	var catalogueID string
+	err := s.db.QueryRowContext(ctx, sqlInsertCatalogueEntry,
+		entry.CatalogueID,
+		entry.VehicleBrand,
+		entry.VehicleModel,
+		entry.VehicleType,
+		entry.CreatedAt,
+		entry.UpdatedAt,
+	).Scan(&catalogueID)
+
+	if err != nil {
+		return "", err
+	}
+
+	return catalogueID, nil
+}
+
+const sqlGetAllCatalogueEntries = `
+SELECT
+	catalogue_id, vehicle_brand,
+	vehicle_model, vehicle_type,
+	created_at, updated_at
+FROM vehicle_catalogue
+ORDER BY lower(vehicle_brand), lower(vehicle_model);
+`
+
+func (s *VehicleCatalogueStore) GetAllCatalogueEntries(ctx context.Context) ([]*VehicleCatalogueRow, error) {
+	rows, err := s.db.QueryContext(ctx, sqlGetAllCatalogueEntries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*VehicleCatalogueRow
+	for rows.Next() {
+		entry := &VehicleCatalogueRow{}
+		err := rows.Scan(
+			&entry.CatalogueID,
+			&entry.VehicleBrand,
+			&entry.VehicleModel,
+			&entry.VehicleType,
+			&entry.CreatedAt,
+			&entry.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+const sqlGetCatalogueEntryByID = `
+SELECT
+	catalogue_id, vehicle_brand,
+	vehicle_model, vehicle_type,
+	created_at, updated_at
+FROM vehicle_catalogue
+WHERE catalogue_id = $1;
+`
+
+func (s *VehicleCatalogueStore) GetCatalogueEntryByID(ctx context.Context, catalogueID string) (*VehicleCatalogueRow, error) {
+	entry := &VehicleCatalogueRow{}
+	err := s.db.QueryRowContext(ctx, sqlGetCatalogueEntryByID, catalogueID).Scan(
+		&entry.CatalogueID,
+		&entry.VehicleBrand,
+		&entry.VehicleModel,
+		&entry.VehicleType,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
+const sqlGetCatalogueEntryByBrandModel = `
+SELECT
+	catalogue_id, vehicle_brand,
+	vehicle_model, vehicle_type,
+	created_at, updated_at
+FROM vehicle_catalogue
+WHERE lower(vehicle_brand) = lower($1) AND lower(vehicle_model) = lower($2);
+`
+
+func (s *VehicleCatalogueStore) GetCatalogueEntryByBrandModel(ctx context.Context, vehicleBrand string, vehicleModel string) (*VehicleCatalogueRow, error) {
+	entry := &VehicleCatalogueRow{}
+	err := s.db.QueryRowContext(ctx, sqlGetCatalogueEntryByBrandModel, vehicleBrand, vehicleModel).Scan(
+		&entry.CatalogueID,
+		&entry.VehicleBrand,
+		&entry.VehicleModel,
+		&entry.VehicleType,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
+const sqlUpdateCatalogueEntryByID = `
+UPDATE vehicle_catalogue
+SET
+	vehicle_brand = $1,
+	vehicle_model = $2,
+	vehicle_type = $3,
+	updated_at = $4
+WHERE catalogue_id = $5
+RETURNING catalogue_id;
+`
+
+func (s *VehicleCatalogueStore) UpdateCatalogueEntryByID(ctx context.Context, entry *VehicleCatalogueRow, catalogueIDToUpdate string) (string, error) {
+	var catalogueID string
+	err := s.db.QueryRowContext(ctx, sqlUpdateCatalogueEntryByID,
+		entry.VehicleBrand,
+		entry.VehicleModel,
+		entry.VehicleType,
+		entry.UpdatedAt,
+		catalogueIDToUpdate,
+	).Scan(&catalogueID)
+
+	if err != nil {
+		return "", err
+	}
+
+	return catalogueID, nil
+}
+
+const sqlDeleteCatalogueEntryByID = `
+DELETE FROM vehicle_catalogue
+WHERE catalogue_id = $1
+RETURNING catalogue_id;
+`
+
+func (s *VehicleCatalogueStore) DeleteCatalogueEntryByID(ctx context.Context, catalogueIDToDelete string) (string, error) {
+	var catalogueID string
+	err := s.db.QueryRowContext(ctx, sqlDeleteCatalogueEntryByID, catalogueIDToDelete).Scan(&catalogueID)
+
+	if err != nil {
+		return "", err
+	}
+
+	return catalogueID, nil
+}
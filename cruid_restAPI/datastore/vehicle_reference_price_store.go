@@ -0,0 +1,130 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+)
+
+type VehicleReferencePriceRow struct {
+	VehicleBrand string
+	VehicleModel string
+	VehicleType  string
+	NewPrice     float64
+	UpdatedAt    int64
+}
+
+type VehicleReferencePriceStore struct {
+	db *sql.DB
+}
+
+func NewVehicleReferencePriceStore(db *sql.DB) *VehicleReferencePriceStore {
+	return &VehicleReferencePriceStore{
+		db: db,
+	}
+}
+
+const sqlUpsertReferencePrice = `
+INSERT INTO vehicle_reference_prices (
+	vehicle_brand,
+	vehicle_model,
+	vehicle_type,
+	new_price,
+	updated_at
+) VALUES (
+	$1, $2, $3, $4, $5
+) ON CONFLICT (vehicle_brand, vehicle_model) DO UPDATE SET
+	vehicle_type = EXCLUDED.vehicle_type,
+	new_price = EXCLUDED.new_price,
+	updated_at = EXCLUDED.updated_at;
+`
+
+// UpsertReferencePrices imports a whole price list in one transaction, so a
+// failed import leaves the previous prices untouched.
+func (s *VehicleReferencePriceStore) UpsertReferencePrices(ctx context.Context, prices []*VehicleReferencePriceRow) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, price := range prices {
+		_, err := tx.ExecContext(ctx, sqlUpsertReferencePrice,
+			price.VehicleBrand,
+			price.VehicleModel,
+			price.VehicleType,
+			price.NewPrice,
+			price.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+const sqlGetAllReferencePrices = `
+SELECT
+	vehicle_brand, vehicle_model,
+	vehicle_type, new_price,
+	updated_at
+FROM vehicle_reference_prices
+ORDER BY vehicle_brand, vehicle_model;
+`
+
+func (s *VehicleReferencePriceStore) GetAllReferencePrices(ctx context.Context) ([]*VehicleReferencePriceRow, error) {
+	rows, err := s.db.QueryContext(ctx, sqlGetAllReferencePrices)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prices []*VehicleReferencePriceRow
+	for rows.Next() {
+		price := &VehicleReferencePriceRow{}
+		err := rows.Scan(
+			&price.VehicleBrand,
+			&price.VehicleModel,
+			&price.VehicleType,
+			&price.NewPrice,
+			&price.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, price)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return prices, nil
+}
+
+const sqlGetReferencePrice = `
+SELECT
+	vehicle_brand, vehicle_model,
+	vehicle_type, new_price,
+	updated_at
+FROM vehicle_reference_prices
+WHERE vehicle_brand = $1 AND vehicle_model = $2;
+`
+
+func (s *VehicleReferencePriceStore) GetReferencePrice(ctx context.Context, vehicleBrand string, vehicleModel string) (*VehicleReferencePriceRow, error) {
+	price := &VehicleReferencePriceRow{}
+	err := s.db.QueryRowContext(ctx, sqlGetReferencePrice, vehicleBrand, vehicleModel).Scan(
+		&price.VehicleBrand,
+		&price.VehicleModel,
+		&price.VehicleType,
+		&price.NewPrice,
+		&price.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return price, nil
+}
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS vehicle_catalogue (
+    catalogue_id TEXT NOT NULL PRIMARY KEY,
+    vehicle_brand TEXT NOT NULL,
+    vehicle_model TEXT NOT NULL,
+    vehicle_type TEXT NOT NULL,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_vehicle_catalogue_brand_model
+ON vehicle_catalogue (lower(vehicle_brand), lower(vehicle_model));
+
+INSERT INTO vehicle_catalogue (catalogue_id, vehicle_brand, vehicle_model, vehicle_type, created_at, updated_at) VALUES
+    ('0b6f8c52-3c1e-4c55-9a0e-1f2d3a4b5c01', 'Toyota', 'Camry', 'car', 0, 0),
+    ('0b6f8c52-3c1e-4c55-9a0e-1f2d3a4b5c02', 'Toyota', 'Avanza', 'car', 0, 0),
+    ('0b6f8c52-3c1e-4c55-9a0e-1f2d3a4b5c03', 'Toyota', 'Hilux', 'pickup', 0, 0),
+    ('0b6f8c52-3c1e-4c55-9a0e-1f2d3a4b5c04', 'Honda', 'Civic', 'car', 0, 0),
+    ('0b6f8c52-3c1e-4c55-9a0e-1f2d3a4b5c05', 'Honda', 'Brio', 'car', 0, 0),
+    ('0b6f8c52-3c1e-4c55-9a0e-1f2d3a4b5c06', 'Honda', 'Beat', 'motorcycle', 0, 0),
+    ('0b6f8c52-3c1e-4c55-9a0e-1f2d3a4b5c07', 'Yamaha', 'NMAX', 'motorcycle', 0, 0),
+    ('0b6f8c52-3c1e-4c55-9a0e-1f2d3a4b5c08', 'Mitsubishi', 'L300', 'pickup', 0, 0),
+    ('0b6f8c52-3c1e-4c55-9a0e-1f2d3a4b5c09', 'Isuzu', 'Elf', 'truck', 0, 0);
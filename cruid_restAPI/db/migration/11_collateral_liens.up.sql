@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS collateral_liens (
+    submission_id TEXT NOT NULL PRIMARY KEY,
+    vehicle_license_number TEXT NOT NULL,
+    pledged_at INTEGER NOT NULL,
+    released_at INTEGER,
+    release_reason TEXT,
+    FOREIGN KEY(submission_id) REFERENCES loan_submissions(submission_id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_collateral_liens_license_number
+ON collateral_liens (vehicle_license_number, pledged_at);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collateral_liens_active_license_number
+ON collateral_liens (vehicle_license_number)
+WHERE released_at IS NULL;
+
+-- Existing loans pledge their vehicles in submission order. When the same plate
+-- secures several open loans only the oldest lien is kept active; the newer
+-- ones are recorded as released at the time of the migration with the reason
+-- DUPLICATE_AT_MIGRATION, so every loan keeps a lien and the duplicates can be
+-- found and reviewed.
+INSERT INTO collateral_liens (submission_id, vehicle_license_number, pledged_at, released_at, release_reason)
+SELECT
+    submission_id,
+    vehicle_license_number,
+    created_at,
+    CASE
+        WHEN loan_status IN ('REJECTED', 'CLOSED', 'CANCELLED') THEN updated_at
+        WHEN open_loan_number > 1 THEN CAST(strftime('%s', 'now') AS INTEGER)
+    END,
+    CASE
+        WHEN loan_status IN ('REJECTED', 'CLOSED', 'CANCELLED') THEN loan_status
+        WHEN open_loan_number > 1 THEN 'DUPLICATE_AT_MIGRATION'
+    END
+FROM (
+    SELECT
+        submission_id,
+        vehicle_license_number,
+        loan_status,
+        created_at,
+        updated_at,
+        ROW_NUMBER() OVER (
+            PARTITION BY vehicle_license_number, loan_status IN ('REJECTED', 'CLOSED', 'CANCELLED')
+            ORDER BY created_at, submission_id
+        ) AS open_loan_number
+    FROM loan_submissions
+    WHERE vehicle_license_number <> ''
+) numbered_loans;
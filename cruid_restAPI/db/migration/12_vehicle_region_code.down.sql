@@ -0,0 +1 @@
+ALTER TABLE loan_submissions DROP COLUMN vehicle_region_code;
@@ -0,0 +1,61 @@
+ALTER TABLE loan_submissions ADD COLUMN vehicle_region_code TEXT;
+
+-- Plates are stored the way licenseplate.Normalize writes them: upper-cased and
+-- without any of the characters unicode.IsSpace reports as whitespace.
+UPDATE loan_submissions
+SET vehicle_license_number = upper(
+    replace(replace(replace(replace(replace(
+    replace(replace(replace(replace(replace(
+    replace(replace(replace(replace(replace(
+    replace(replace(replace(replace(replace(
+    replace(replace(replace(replace(replace(
+        vehicle_license_number,
+        char(9), ''), char(10), ''), char(11), ''), char(12), ''), char(13), ''),
+        char(32), ''), char(133), ''), char(160), ''), char(5760), ''), char(8192), ''),
+        char(8193), ''), char(8194), ''), char(8195), ''), char(8196), ''), char(8197), ''),
+        char(8198), ''), char(8199), ''), char(8200), ''), char(8201), ''), char(8202), ''),
+        char(8232), ''), char(8233), ''), char(8239), ''), char(8287), ''), char(12288), '')
+);
+
+-- The liens are normalized the same way, with the index that keeps a plate to
+-- one active lien dropped until the result has been checked.
+DROP INDEX idx_collateral_liens_active_license_number;
+
+UPDATE collateral_liens
+SET vehicle_license_number = upper(
+    replace(replace(replace(replace(replace(
+    replace(replace(replace(replace(replace(
+    replace(replace(replace(replace(replace(
+    replace(replace(replace(replace(replace(
+    replace(replace(replace(replace(replace(
+        vehicle_license_number,
+        char(9), ''), char(10), ''), char(11), ''), char(12), ''), char(13), ''),
+        char(32), ''), char(133), ''), char(160), ''), char(5760), ''), char(8192), ''),
+        char(8193), ''), char(8194), ''), char(8195), ''), char(8196), ''), char(8197), ''),
+        char(8198), ''), char(8199), ''), char(8200), ''), char(8201), ''), char(8202), ''),
+        char(8232), ''), char(8233), ''), char(8239), ''), char(8287), ''), char(12288), '')
+);
+
+-- Two active liens whose plates only differed in case or whitespace pledge the
+-- same vehicle twice. They need a manual decision, so the migration stops and
+-- names them. SQLite can only raise a fixed message, so the list is reported
+-- through the error of an invalid JSON path instead.
+SELECT json_extract(
+    '{}',
+    'active collateral liens share a plate once normalized, release or correct them first: '
+        || group_concat(submission_id, ', ' ORDER BY submission_id)
+)
+FROM collateral_liens
+WHERE released_at IS NULL
+AND vehicle_license_number IN (
+    SELECT vehicle_license_number
+    FROM collateral_liens
+    WHERE released_at IS NULL
+    GROUP BY vehicle_license_number
+    HAVING count(*) > 1
+)
+HAVING count(*) > 0;
+
+CREATE UNIQUE INDEX idx_collateral_liens_active_license_number
+ON collateral_liens (vehicle_license_number)
+WHERE released_at IS NULL;
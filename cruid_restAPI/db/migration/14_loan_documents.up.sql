@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS loan_documents (
+    document_id TEXT PRIMARY KEY,
+    submission_id TEXT NOT NULL,
+    document_type TEXT NOT NULL,
+    file_name TEXT NOT NULL,
+    mime_type TEXT NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    checksum_sha256 TEXT NOT NULL,
+    storage_key TEXT NOT NULL,
+    uploaded_by TEXT NOT NULL,
+    uploaded_at INTEGER NOT NULL,
+    FOREIGN KEY(submission_id) REFERENCES loan_submissions(submission_id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_loan_documents_submission_id
+ON loan_documents (submission_id);
@@ -0,0 +1,3 @@
+ALTER TABLE loan_documents DROP COLUMN verified_at;
+ALTER TABLE loan_documents DROP COLUMN verified_by;
+ALTER TABLE loan_products DROP COLUMN required_document_types;
@@ -0,0 +1,12 @@
+ALTER TABLE loan_products ADD COLUMN required_document_types TEXT NOT NULL DEFAULT '';
+
+UPDATE loan_products
+SET required_document_types = 'ID_CARD,PAYSLIP,VEHICLE_REGISTRATION'
+WHERE product_code IN ('CAR_STANDARD', 'MOTORCYCLE_STANDARD');
+
+UPDATE loan_products
+SET required_document_types = 'ID_CARD,PAYSLIP,VEHICLE_REGISTRATION,BUSINESS_LICENCE'
+WHERE product_code = 'COMMERCIAL_VEHICLE';
+
+ALTER TABLE loan_documents ADD COLUMN verified_by TEXT;
+ALTER TABLE loan_documents ADD COLUMN verified_at INTEGER;
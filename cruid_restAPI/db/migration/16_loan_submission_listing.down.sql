@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_loan_submissions_customer_id;
+DROP INDEX IF EXISTS idx_loan_submissions_loan_status;
+DROP INDEX IF EXISTS idx_loan_submissions_proposed_loan_amount;
+DROP INDEX IF EXISTS idx_loan_submissions_created_at;
@@ -0,0 +1,11 @@
+CREATE INDEX IF NOT EXISTS idx_loan_submissions_created_at
+ON loan_submissions (created_at, submission_id);
+
+CREATE INDEX IF NOT EXISTS idx_loan_submissions_proposed_loan_amount
+ON loan_submissions (proposed_loan_amount, submission_id);
+
+CREATE INDEX IF NOT EXISTS idx_loan_submissions_loan_status
+ON loan_submissions (loan_status, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_loan_submissions_customer_id
+ON loan_submissions (customer_id, created_at);
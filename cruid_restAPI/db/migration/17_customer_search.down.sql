@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trg_loan_customers_fts_delete;
+DROP TRIGGER IF EXISTS trg_loan_customers_fts_update;
+DROP TRIGGER IF EXISTS trg_loan_customers_fts_insert;
+DROP TABLE IF EXISTS loan_customers_fts;
@@ -0,0 +1,33 @@
+CREATE VIRTUAL TABLE IF NOT EXISTS loan_customers_fts USING fts5(
+    customer_id UNINDEXED,
+    id_card_number,
+    full_name,
+    phone_number,
+    email,
+    tokenize = 'trigram'
+);
+
+INSERT INTO loan_customers_fts (customer_id, id_card_number, full_name, phone_number, email)
+SELECT customer_id, id_card_number, full_name, phone_number, email
+FROM loan_customers;
+
+CREATE TRIGGER IF NOT EXISTS trg_loan_customers_fts_insert
+AFTER INSERT ON loan_customers
+BEGIN
+    INSERT INTO loan_customers_fts (customer_id, id_card_number, full_name, phone_number, email)
+    VALUES (NEW.customer_id, NEW.id_card_number, NEW.full_name, NEW.phone_number, NEW.email);
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_loan_customers_fts_update
+AFTER UPDATE OF id_card_number, full_name, phone_number, email ON loan_customers
+BEGIN
+    DELETE FROM loan_customers_fts WHERE customer_id = OLD.customer_id;
+    INSERT INTO loan_customers_fts (customer_id, id_card_number, full_name, phone_number, email)
+    VALUES (NEW.customer_id, NEW.id_card_number, NEW.full_name, NEW.phone_number, NEW.email);
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_loan_customers_fts_delete
+AFTER DELETE ON loan_customers
+BEGIN
+    DELETE FROM loan_customers_fts WHERE customer_id = OLD.customer_id;
+END;
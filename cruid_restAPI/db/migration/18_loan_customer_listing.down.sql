@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_loan_customers_address_city;
+DROP INDEX IF EXISTS idx_loan_customers_full_name;
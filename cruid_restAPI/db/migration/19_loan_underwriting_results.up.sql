@@ -0,0 +1,12 @@
+ALTER TABLE loan_submissions ADD COLUMN underwriting_recommendation TEXT;
+
+CREATE TABLE IF NOT EXISTS loan_underwriting_results (
+    submission_id TEXT NOT NULL,
+    rule_number INTEGER NOT NULL,
+    rule_name TEXT NOT NULL,
+    outcome TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    PRIMARY KEY (submission_id, rule_number),
+    FOREIGN KEY(submission_id) REFERENCES loan_submissions(submission_id)
+    ON DELETE CASCADE
+);
@@ -0,0 +1,15 @@
+ALTER TABLE loan_submissions ADD COLUMN annual_interest_rate REAL NOT NULL DEFAULT 0;
+ALTER TABLE loan_submissions ADD COLUMN interest_method TEXT NOT NULL DEFAULT 'EFFECTIVE';
+
+CREATE TABLE IF NOT EXISTS loan_installments (
+    submission_id TEXT NOT NULL,
+    installment_number INTEGER NOT NULL,
+    due_date INTEGER NOT NULL,
+    principal_amount REAL NOT NULL,
+    interest_amount REAL NOT NULL,
+    total_amount REAL NOT NULL,
+    remaining_balance REAL NOT NULL,
+    PRIMARY KEY (submission_id, installment_number),
+    FOREIGN KEY(submission_id) REFERENCES loan_submissions(submission_id)
+    ON DELETE CASCADE
+);
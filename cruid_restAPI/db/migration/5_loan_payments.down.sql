@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_loan_payments_submission_id;
+DROP TABLE IF EXISTS loan_payments;
+ALTER TABLE loan_installments DROP COLUMN paid_principal;
+ALTER TABLE loan_installments DROP COLUMN paid_interest;
+ALTER TABLE loan_installments DROP COLUMN paid_fee;
+ALTER TABLE loan_installments DROP COLUMN fee_amount;
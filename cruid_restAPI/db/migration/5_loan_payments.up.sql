@@ -0,0 +1,22 @@
+ALTER TABLE loan_installments ADD COLUMN fee_amount REAL NOT NULL DEFAULT 0;
+ALTER TABLE loan_installments ADD COLUMN paid_fee REAL NOT NULL DEFAULT 0;
+ALTER TABLE loan_installments ADD COLUMN paid_interest REAL NOT NULL DEFAULT 0;
+ALTER TABLE loan_installments ADD COLUMN paid_principal REAL NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS loan_payments (
+    payment_id TEXT NOT NULL PRIMARY KEY,
+    submission_id TEXT NOT NULL,
+    amount REAL NOT NULL,
+    fee_applied REAL NOT NULL DEFAULT 0,
+    interest_applied REAL NOT NULL DEFAULT 0,
+    principal_applied REAL NOT NULL DEFAULT 0,
+    unapplied_amount REAL NOT NULL DEFAULT 0,
+    reference TEXT,
+    paid_at INTEGER NOT NULL,
+    created_at INTEGER NOT NULL,
+    FOREIGN KEY(submission_id) REFERENCES loan_submissions(submission_id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_loan_payments_submission_id
+ON loan_payments (submission_id, paid_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_loan_delinquencies_bucket;
+DROP TABLE IF EXISTS loan_delinquencies;
+ALTER TABLE loan_installments DROP COLUMN late_fee_accrued_through;
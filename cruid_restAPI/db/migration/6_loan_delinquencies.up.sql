@@ -0,0 +1,14 @@
+ALTER TABLE loan_installments ADD COLUMN late_fee_accrued_through INTEGER;
+
+CREATE TABLE IF NOT EXISTS loan_delinquencies (
+    submission_id TEXT NOT NULL PRIMARY KEY,
+    days_past_due INTEGER NOT NULL DEFAULT 0,
+    bucket TEXT NOT NULL,
+    overdue_amount REAL NOT NULL DEFAULT 0,
+    evaluated_at INTEGER NOT NULL,
+    FOREIGN KEY(submission_id) REFERENCES loan_submissions(submission_id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_loan_delinquencies_bucket
+ON loan_delinquencies (bucket, days_past_due);
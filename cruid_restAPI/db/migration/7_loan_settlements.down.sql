@@ -0,0 +1,3 @@
+ALTER TABLE loan_payments DROP COLUMN waived_interest;
+ALTER TABLE loan_payments DROP COLUMN early_termination_fee;
+ALTER TABLE loan_installments DROP COLUMN waived_interest;
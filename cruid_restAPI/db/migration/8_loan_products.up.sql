@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS loan_products (
+    product_code TEXT NOT NULL PRIMARY KEY,
+    product_name TEXT NOT NULL,
+    annual_interest_rate REAL NOT NULL,
+    interest_method TEXT NOT NULL,
+    min_loan_amount INTEGER NOT NULL,
+    max_loan_amount INTEGER NOT NULL,
+    min_tenure_month INTEGER NOT NULL,
+    max_tenure_month INTEGER NOT NULL,
+    eligible_vehicle_types TEXT NOT NULL,
+    is_commercial_vehicle BOOLEAN NOT NULL DEFAULT FALSE,
+    effective_from INTEGER NOT NULL,
+    effective_until INTEGER
+);
+
+ALTER TABLE loan_submissions ADD COLUMN product_code TEXT REFERENCES loan_products(product_code);
+
+INSERT INTO loan_products (
+    product_code, product_name,
+    annual_interest_rate, interest_method,
+    min_loan_amount, max_loan_amount,
+    min_tenure_month, max_tenure_month,
+    eligible_vehicle_types, is_commercial_vehicle,
+    effective_from, effective_until
+) VALUES
+    ('CAR_STANDARD', 'Standard Car Loan', 9.5, 'EFFECTIVE', 5000, 800000000, 6, 60, 'car,pickup', FALSE, 0, NULL),
+    ('MOTORCYCLE_STANDARD', 'Standard Motorcycle Loan', 14, 'FLAT', 1000, 60000000, 6, 36, 'motorcycle', FALSE, 0, NULL),
+    ('COMMERCIAL_VEHICLE', 'Commercial Vehicle Loan', 11, 'EFFECTIVE', 10000, 500000000, 12, 48, 'car,pickup,truck', TRUE, 0, NULL);
@@ -0,0 +1,3 @@
+ALTER TABLE loan_submissions DROP COLUMN loan_to_value;
+ALTER TABLE loan_submissions DROP COLUMN collateral_value;
+DROP TABLE IF EXISTS vehicle_reference_prices;
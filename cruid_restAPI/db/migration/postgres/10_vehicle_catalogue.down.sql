@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_vehicle_catalogue_brand_model;
+DROP TABLE IF EXISTS vehicle_catalogue;
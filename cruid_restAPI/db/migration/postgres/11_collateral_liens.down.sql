@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_collateral_liens_active_license_number;
+DROP INDEX IF EXISTS idx_collateral_liens_license_number;
+DROP TABLE IF EXISTS collateral_liens;
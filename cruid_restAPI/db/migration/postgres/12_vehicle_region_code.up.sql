@@ -0,0 +1,50 @@
+ALTER TABLE loan_submissions ADD COLUMN vehicle_region_code TEXT;
+
+-- Plates are stored the way licenseplate.Normalize writes them: upper-cased and
+-- without any of the characters unicode.IsSpace reports as whitespace.
+UPDATE loan_submissions
+SET vehicle_license_number = upper(translate(
+    vehicle_license_number,
+    E'\x09\x0A\x0B\x0C\x0D\x20\u0085\u00A0\u1680\u2000\u2001\u2002\u2003\u2004\u2005\u2006\u2007\u2008\u2009\u200A\u2028\u2029\u202F\u205F\u3000',
+    ''
+));
+
+-- The liens are normalized the same way, with the index that keeps a plate to
+-- one active lien dropped until the result has been checked.
+DROP INDEX idx_collateral_liens_active_license_number;
+
+UPDATE collateral_liens
+SET vehicle_license_number = upper(translate(
+    vehicle_license_number,
+    E'\x09\x0A\x0B\x0C\x0D\x20\u0085\u00A0\u1680\u2000\u2001\u2002\u2003\u2004\u2005\u2006\u2007\u2008\u2009\u200A\u2028\u2029\u202F\u205F\u3000',
+    ''
+));
+
+-- Two active liens whose plates only differed in case or whitespace pledge the
+-- same vehicle twice. They need a manual decision, so the migration stops and
+-- names them.
+DO $$
+DECLARE
+    conflicting TEXT;
+BEGIN
+    SELECT string_agg(submission_id, ', ' ORDER BY submission_id)
+    INTO conflicting
+    FROM collateral_liens
+    WHERE released_at IS NULL
+    AND vehicle_license_number IN (
+        SELECT vehicle_license_number
+        FROM collateral_liens
+        WHERE released_at IS NULL
+        GROUP BY vehicle_license_number
+        HAVING count(*) > 1
+    );
+
+    IF conflicting IS NOT NULL THEN
+        RAISE EXCEPTION 'active collateral liens share a plate once normalized, release or correct them first: %', conflicting;
+    END IF;
+END
+$$;
+
+CREATE UNIQUE INDEX idx_collateral_liens_active_license_number
+ON collateral_liens (vehicle_license_number)
+WHERE released_at IS NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_loan_submission_parties_customer_id;
+DROP TABLE IF EXISTS loan_submission_parties;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS loan_submission_parties (
+    submission_id TEXT NOT NULL,
+    customer_id TEXT NOT NULL,
+    party_role TEXT NOT NULL CHECK (party_role IN ('PRIMARY', 'CO_APPLICANT', 'GUARANTOR')),
+    created_at BIGINT NOT NULL,
+    PRIMARY KEY (submission_id, customer_id),
+    FOREIGN KEY(submission_id) REFERENCES loan_submissions(submission_id)
+    ON DELETE CASCADE,
+    FOREIGN KEY(customer_id) REFERENCES loan_customers(customer_id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_loan_submission_parties_customer_id
+ON loan_submission_parties (customer_id);
+
+INSERT INTO loan_submission_parties (submission_id, customer_id, party_role, created_at)
+SELECT submission_id, customer_id, 'PRIMARY', created_at
+FROM loan_submissions;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_loan_documents_submission_id;
+DROP TABLE IF EXISTS loan_documents;
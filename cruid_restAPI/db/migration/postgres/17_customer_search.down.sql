@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_loan_customers_email_trgm;
+DROP INDEX IF EXISTS idx_loan_customers_phone_number_trgm;
+DROP INDEX IF EXISTS idx_loan_customers_full_name_trgm;
+DROP INDEX IF EXISTS idx_loan_customers_id_card_number_trgm;
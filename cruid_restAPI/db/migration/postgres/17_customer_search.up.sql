@@ -0,0 +1,13 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_loan_customers_id_card_number_trgm
+ON loan_customers USING gin (id_card_number gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_loan_customers_full_name_trgm
+ON loan_customers USING gin (full_name gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_loan_customers_phone_number_trgm
+ON loan_customers USING gin (phone_number gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_loan_customers_email_trgm
+ON loan_customers USING gin (email gin_trgm_ops);
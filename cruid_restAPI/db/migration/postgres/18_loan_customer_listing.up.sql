@@ -0,0 +1,5 @@
+CREATE INDEX IF NOT EXISTS idx_loan_customers_full_name
+ON loan_customers (full_name, customer_id);
+
+CREATE INDEX IF NOT EXISTS idx_loan_customers_address_city
+ON loan_customers (LOWER(address_city));
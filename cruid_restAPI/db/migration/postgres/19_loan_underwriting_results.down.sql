@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS loan_underwriting_results;
+
+ALTER TABLE loan_submissions DROP COLUMN underwriting_recommendation;
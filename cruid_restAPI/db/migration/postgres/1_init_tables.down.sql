@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS loan_submissions;
+DROP TABLE IF EXISTS loan_customers;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_loan_submission_status_history_submission_id;
+DROP TABLE IF EXISTS loan_submission_status_history;
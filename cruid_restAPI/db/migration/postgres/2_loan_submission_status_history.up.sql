@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS loan_submission_status_history (
+    history_id BIGSERIAL PRIMARY KEY,
+    submission_id TEXT NOT NULL,
+    from_status TEXT,
+    to_status TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    reason TEXT,
+    changed_at BIGINT NOT NULL,
+    FOREIGN KEY(submission_id) REFERENCES loan_submissions(submission_id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_loan_submission_status_history_submission_id
+ON loan_submission_status_history (submission_id, changed_at);
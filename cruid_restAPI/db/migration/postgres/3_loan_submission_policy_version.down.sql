@@ -0,0 +1 @@
+ALTER TABLE loan_submissions DROP COLUMN policy_version;
@@ -0,0 +1 @@
+ALTER TABLE loan_submissions ADD COLUMN policy_version INTEGER;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS loan_installments;
+ALTER TABLE loan_submissions DROP COLUMN interest_method;
+ALTER TABLE loan_submissions DROP COLUMN annual_interest_rate;
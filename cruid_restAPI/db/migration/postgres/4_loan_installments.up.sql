@@ -0,0 +1,15 @@
+ALTER TABLE loan_submissions ADD COLUMN annual_interest_rate DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE loan_submissions ADD COLUMN interest_method TEXT NOT NULL DEFAULT 'EFFECTIVE';
+
+CREATE TABLE IF NOT EXISTS loan_installments (
+    submission_id TEXT NOT NULL,
+    installment_number INTEGER NOT NULL,
+    due_date BIGINT NOT NULL,
+    principal_amount DOUBLE PRECISION NOT NULL,
+    interest_amount DOUBLE PRECISION NOT NULL,
+    total_amount DOUBLE PRECISION NOT NULL,
+    remaining_balance DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (submission_id, installment_number),
+    FOREIGN KEY(submission_id) REFERENCES loan_submissions(submission_id)
+    ON DELETE CASCADE
+);
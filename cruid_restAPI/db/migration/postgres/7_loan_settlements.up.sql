@@ -0,0 +1,3 @@
+ALTER TABLE loan_installments ADD COLUMN waived_interest DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE loan_payments ADD COLUMN early_termination_fee DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE loan_payments ADD COLUMN waived_interest DOUBLE PRECISION NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE loan_submissions DROP COLUMN product_code;
+DROP TABLE IF EXISTS loan_products;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS vehicle_reference_prices (
+    vehicle_brand TEXT NOT NULL,
+    vehicle_model TEXT NOT NULL,
+    vehicle_type TEXT NOT NULL,
+    new_price DOUBLE PRECISION NOT NULL,
+    updated_at BIGINT NOT NULL,
+    PRIMARY KEY (vehicle_brand, vehicle_model)
+);
+
+ALTER TABLE loan_submissions ADD COLUMN collateral_value DOUBLE PRECISION;
+ALTER TABLE loan_submissions ADD COLUMN loan_to_value DOUBLE PRECISION;
+
+INSERT INTO vehicle_reference_prices (vehicle_brand, vehicle_model, vehicle_type, new_price, updated_at) VALUES
+    ('toyota', 'camry', 'car', 650000000, 0),
+    ('toyota', 'avanza', 'car', 250000000, 0),
+    ('toyota', 'hilux', 'pickup', 400000000, 0),
+    ('honda', 'civic', 'car', 550000000, 0),
+    ('honda', 'brio', 'car', 180000000, 0),
+    ('honda', 'beat', 'motorcycle', 18000000, 0),
+    ('yamaha', 'nmax', 'motorcycle', 32000000, 0),
+    ('mitsubishi', 'l300', 'pickup', 240000000, 0),
+    ('isuzu', 'elf', 'truck', 380000000, 0);
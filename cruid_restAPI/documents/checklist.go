@@ -0,0 +1,75 @@
+package documents
+
+import (
+	"slices"
+	"strings"
+)
+
+// Document is what the checklist needs to know about an uploaded document.
+type Document struct {
+	DocumentType string
+	Verified     bool
+}
+
+type ChecklistItem struct {
+	DocumentType string
+	Uploaded     bool
+	Verified     bool
+}
+
+// Checklist tells which of the document types a product requires are still
+// missing from a submission. A type counts as satisfied only once at least one
+// uploaded document of that type has been verified.
+type Checklist struct {
+	Items   []ChecklistItem
+	Missing []string
+}
+
+func (c Checklist) Complete() bool {
+	return len(c.Missing) == 0
+}
+
+// ParseTypes splits the comma-separated document types stored on a product.
+func ParseTypes(documentTypes string) []string {
+	parsed := []string{}
+	for _, documentType := range strings.Split(documentTypes, ",") {
+		documentType = strings.ToUpper(strings.TrimSpace(documentType))
+		if documentType != "" && !slices.Contains(parsed, documentType) {
+			parsed = append(parsed, documentType)
+		}
+	}
+
+	return parsed
+}
+
+func BuildChecklist(requiredTypes []string, uploaded []Document) Checklist {
+	checklist := Checklist{
+		Items:   make([]ChecklistItem, 0, len(requiredTypes)),
+		Missing: []string{},
+	}
+
+	for _, documentType := range requiredTypes {
+		item := ChecklistItem{
+			DocumentType: documentType,
+		}
+
+		for _, document := range uploaded {
+			if document.DocumentType != documentType {
+				continue
+			}
+
+			item.Uploaded = true
+			if document.Verified {
+				item.Verified = true
+			}
+		}
+
+		if !item.Verified {
+			checklist.Missing = append(checklist.Missing, documentType)
+		}
+
+		checklist.Items = append(checklist.Items, item)
+	}
+
+	return checklist
+}
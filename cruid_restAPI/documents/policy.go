@@ -0,0 +1,60 @@
+package documents
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/alphaloan/vehicle/blobstore"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	IDCard              = "ID_CARD"
+	Payslip             = "PAYSLIP"
+	VehicleRegistration = "VEHICLE_REGISTRATION"
+	BusinessLicence     = "BUSINESS_LICENCE"
+	Other               = "OTHER"
+)
+
+var documentTypes = []string{IDCard, Payslip, VehicleRegistration, BusinessLicence, Other}
+
+func IsValidType(documentType string) bool {
+	return slices.Contains(documentTypes, documentType)
+}
+
+func Types() []string {
+	return slices.Clone(documentTypes)
+}
+
+type Policy struct {
+	Storage          blobstore.Config `yaml:"storage"`
+	MaxSizeBytes     int64            `yaml:"max_size_bytes"`
+	AllowedMimeTypes []string         `yaml:"allowed_mime_types"`
+}
+
+func LoadPolicy(path string) (Policy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	var policy Policy
+	if err := yaml.Unmarshal(content, &policy); err != nil {
+		return Policy{}, fmt.Errorf("failed to parse document policy %s: %w", path, err)
+	}
+
+	if policy.MaxSizeBytes <= 0 {
+		return Policy{}, fmt.Errorf("invalid document policy %s: max_size_bytes must be greater than zero", path)
+	}
+
+	if len(policy.AllowedMimeTypes) == 0 {
+		return Policy{}, fmt.Errorf("invalid document policy %s: allowed_mime_types must not be empty", path)
+	}
+
+	return policy, nil
+}
+
+func (p Policy) IsAllowedMimeType(mimeType string) bool {
+	return slices.Contains(p.AllowedMimeTypes, mimeType)
+}
@@ -0,0 +1,60 @@
+package fuzzy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Trigrams returns the set of three-letter sequences of s the way PostgreSQL's
+// pg_trgm builds them: every word is lower-cased and padded with two spaces in
+// front and one behind, so short words and word starts still carry weight.
+func Trigrams(s string) map[string]struct{} {
+	trigrams := map[string]struct{}{}
+
+	for _, word := range words(s) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			trigrams[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+
+	return trigrams
+}
+
+// Similarity returns the share of trigrams a and b have in common, from 0 for
+// nothing in common to 1 for the same words. A typo in a word of five or more
+// letters usually still scores above 0.3.
+func Similarity(a, b string) float64 {
+	trigramsA, trigramsB := Trigrams(a), Trigrams(b)
+	if len(trigramsA) == 0 || len(trigramsB) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for trigram := range trigramsA {
+		if _, ok := trigramsB[trigram]; ok {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(trigramsA)+len(trigramsB)-shared)
+}
+
+// WordSimilarity returns the best Similarity between query and either the
+// whole of text or one of its words, so "jon" matches "John Smith" as well as
+// "jon smth" does.
+func WordSimilarity(query, text string) float64 {
+	best := Similarity(query, text)
+
+	for _, word := range words(text) {
+		best = max(best, Similarity(query, word))
+	}
+
+	return best
+}
+
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
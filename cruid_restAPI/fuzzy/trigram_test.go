@@ -0,0 +1,71 @@
+package fuzzy
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTrigrams(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []string
+	}{
+		{"Jon", []string{"  j", " jo", "jon", "on "}},
+		{"Ab-c", []string{"  a", " ab", "ab ", "  c", " c "}},
+		{"Zoë", []string{"  z", " zo", "zoë", "oë "}},
+		{" - ", nil},
+	}
+
+	for _, tt := range tests {
+		want := map[string]struct{}{}
+		for _, trigram := range tt.want {
+			want[trigram] = struct{}{}
+		}
+
+		if got := Trigrams(tt.s); !reflect.DeepEqual(got, want) {
+			t.Errorf("Trigrams(%q) = %v, want %v", tt.s, got, want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"Siti", "siti", 1},
+		{"Sitti", "Siti", 4.0 / 7},
+		{"John", "Jon", 2.0 / 7},
+		{"abc", "xyz", 0},
+		{"", "Siti", 0},
+	}
+
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestWordSimilarity(t *testing.T) {
+	tests := []struct {
+		query, text string
+		want        float64
+	}{
+		{"santoso", "Budi Santoso", 1},
+		{"Sitti", "Siti Rahayu", 4.0 / 7},
+		{"Budi Santoso", "Budi Santoso", 1},
+		{"xyz", "Budi Santoso", 0},
+	}
+
+	for _, tt := range tests {
+		if got := WordSimilarity(tt.query, tt.text); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("WordSimilarity(%q, %q) = %v, want %v", tt.query, tt.text, got, tt.want)
+		}
+	}
+
+	if whole, word := Similarity("santoso", "Budi Santoso"), WordSimilarity("santoso", "Budi Santoso"); whole >= word {
+		t.Errorf("WordSimilarity = %v should beat the similarity %v to the whole name", word, whole)
+	}
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/alphaloan/vehicle/datastore"
+	"github.com/alphaloan/vehicle/licenseplate"
+)
+
+type CollateralHandler struct {
+	LienStore datastore.CollateralLienStore
+}
+
+func NewCollateralHandler(lienStore datastore.CollateralLienStore) *CollateralHandler {
+	return &CollateralHandler{
+		LienStore: lienStore,
+	}
+}
+
+func (h *CollateralHandler) HandleGetCollateralLienHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	licenseNumber := licenseplate.Normalize(r.PathValue("license_number"))
+	if licenseNumber == "" {
+		errMsg := "Missing license_number path variable"
+		responseBodyErr := GetCollateralLienHistoryResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	lienRows, err := h.LienStore.GetLiensByLicenseNumber(r.Context(), licenseNumber)
+
+	if err != nil {
+		errMsg := "Failed to get collateral lien history"
+		responseBodyErr := GetCollateralLienHistoryResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	history := CollateralLienHistory{
+		VehicleLicenseNumber: licenseNumber,
+		Liens:                make([]CollateralLien, 0, len(lienRows)),
+	}
+
+	for _, row := range lienRows {
+		active := !row.ReleasedAt.Valid
+		if active {
+			history.Pledged = true
+			history.PledgedToSubmissionID = &row.SubmissionID
+		}
+
+		history.Liens = append(history.Liens, CollateralLien{
+			SubmissionID:  row.SubmissionID,
+			CustomerID:    row.CustomerID,
+			LoanStatus:    row.LoanStatus,
+			Active:        active,
+			PledgedAt:     row.PledgedAt,
+			ReleasedAt:    convertNullInt64(row.ReleasedAt),
+			ReleaseReason: convertNullStringPointer(row.ReleaseReason),
+		})
+	}
+
+	responseBody := GetCollateralLienHistoryResponse{
+		Data: &history,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/alphaloan/vehicle/collections"
+	"github.com/alphaloan/vehicle/datastore"
+)
+
+type DelinquencyHandler struct {
+	DelinquencyStore datastore.LoanDelinquencyStore
+}
+
+func NewDelinquencyHandler(delinquencyStore datastore.LoanDelinquencyStore) *DelinquencyHandler {
+	return &DelinquencyHandler{
+		DelinquencyStore: delinquencyStore,
+	}
+}
+
+func (h *DelinquencyHandler) HandleGetDelinquencies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	bucket := r.URL.Query().Get("bucket")
+	if bucket != "" && !collections.IsValidBucket(bucket) {
+		errMsg := "Invalid bucket: " + bucket
+		responseBodyErr := GetLoanDelinquenciesResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	delinquencyRows, err := h.DelinquencyStore.GetDelinquenciesByBucket(r.Context(), bucket, collections.BucketCurrent)
+
+	if err != nil {
+		errMsg := "Failed to get loan delinquencies"
+		responseBodyErr := GetLoanDelinquenciesResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	delinquencies := make([]LoanDelinquency, 0, len(delinquencyRows))
+	for _, row := range delinquencyRows {
+		delinquencies = append(delinquencies, LoanDelinquency{
+			SubmissionID:  row.LoanDelinquencyRow.SubmissionID,
+			CustomerID:    row.CustomerID,
+			FullName:      row.FullName,
+			PhoneNumber:   row.PhoneNumber,
+			Email:         convertNullStringPointer(row.Email),
+			DaysPastDue:   row.LoanDelinquencyRow.DaysPastDue,
+			Bucket:        row.LoanDelinquencyRow.Bucket,
+			OverdueAmount: row.LoanDelinquencyRow.OverdueAmount,
+			EvaluatedAt:   row.LoanDelinquencyRow.EvaluatedAt,
+		})
+	}
+
+	responseBody := GetLoanDelinquenciesResponse{
+		Data: &delinquencies,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
@@ -0,0 +1,366 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/alphaloan/vehicle/datastore"
+)
+
+const (
+	ndjsonContentType = "application/x-ndjson"
+	csvContentType    = "text/csv"
+
+	// exportFlushRows is how many rows an export writes between flushes to
+	// the client.
+	exportFlushRows = 100
+)
+
+type ExportHandler struct {
+	SubmissionStore datastore.SubmissionRepository
+	CustomerStore   datastore.CustomerRepository
+}
+
+func NewExportHandler(
+	submissionStore datastore.SubmissionRepository,
+	customerStore datastore.CustomerRepository) *ExportHandler {
+	return &ExportHandler{
+		SubmissionStore: submissionStore,
+		CustomerStore:   customerStore,
+	}
+}
+
+type ExportErrorResponse struct {
+	ErrorMessage *string `json:"error_message"`
+}
+
+// LoanSubmissionExport is a loan submission as it is exported, with the
+// customer and timestamps the list leaves out.
+type LoanSubmissionExport struct {
+	LoanSubmission
+	CustomerID string `json:"customer_id"`
+	CreatedAt  int64  `json:"created_at"`
+	UpdatedAt  int64  `json:"updated_at"`
+}
+
+// loanSubmissionExportColumns are the CSV columns of a submission export.
+var loanSubmissionExportColumns = []struct {
+	name  string
+	value func(LoanSubmissionExport) any
+}{
+	{"submission_id", func(s LoanSubmissionExport) any { return s.SubmissionID }},
+	{"customer_id", func(s LoanSubmissionExport) any { return s.CustomerID }},
+	{"vehicle_type", func(s LoanSubmissionExport) any { return s.VehicleType }},
+	{"vehicle_brand", func(s LoanSubmissionExport) any { return s.VehicleBrand }},
+	{"vehicle_model", func(s LoanSubmissionExport) any { return s.VehicleModel }},
+	{"vehicle_license_number", func(s LoanSubmissionExport) any { return s.VehicleLicenseNumber }},
+	{"vehicle_region_code", func(s LoanSubmissionExport) any { return s.VehicleRegionCode }},
+	{"vehicle_odometer", func(s LoanSubmissionExport) any { return s.VehicleOdometer }},
+	{"manufacturing_year", func(s LoanSubmissionExport) any { return s.ManufacturingYear }},
+	{"proposed_loan_amount", func(s LoanSubmissionExport) any { return s.ProposedLoanAmount }},
+	{"proposed_loan_tenure_month", func(s LoanSubmissionExport) any { return s.ProposedLoanTenureMonth }},
+	{"annual_interest_rate", func(s LoanSubmissionExport) any { return s.AnnualInterestRate }},
+	{"interest_method", func(s LoanSubmissionExport) any { return s.InterestMethod }},
+	{"product_code", func(s LoanSubmissionExport) any { return s.ProductCode }},
+	{"collateral_value", func(s LoanSubmissionExport) any { return s.CollateralValue }},
+	{"loan_to_value", func(s LoanSubmissionExport) any { return s.LoanToValue }},
+	{"is_commercial_vehicle", func(s LoanSubmissionExport) any { return s.IsCommercialVehicle }},
+	{"loan_status", func(s LoanSubmissionExport) any { return s.LoanStatus }},
+	{"policy_version", func(s LoanSubmissionExport) any { return s.PolicyVersion }},
+	{"underwriting_recommendation", func(s LoanSubmissionExport) any { return s.UnderwritingRecommendation }},
+	{"created_at", func(s LoanSubmissionExport) any { return s.CreatedAt }},
+	{"updated_at", func(s LoanSubmissionExport) any { return s.UpdatedAt }},
+}
+
+func (h *ExportHandler) HandleExportSubmissions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	contentType, ok := negotiateExportContentType(r.Header.Get("Accept"))
+	if !ok {
+		writeExportError(w, http.StatusNotAcceptable, "Exports are available as "+ndjsonContentType+" or "+csvContentType)
+		return
+	}
+
+	query, err := parseLoanSubmissionFilters(r.URL.Query())
+	if err != nil {
+		writeExportError(w, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	columns := make([]string, 0, len(loanSubmissionExportColumns))
+	for _, column := range loanSubmissionExportColumns {
+		columns = append(columns, column.name)
+	}
+
+	export := newExportWriter(w, contentType, "loan_submissions", columns)
+
+	err = h.SubmissionStore.StreamLoanSubmissions(r.Context(), query, func(row *datastore.LoanSubmissionRow) error {
+		record := LoanSubmissionExport{
+			LoanSubmission: convertLoanSubmissionRow(row),
+			CustomerID:     row.CustomerID,
+			CreatedAt:      row.CreatedAt,
+			UpdatedAt:      row.UpdatedAt,
+		}
+
+		values := make([]any, 0, len(loanSubmissionExportColumns))
+		for _, column := range loanSubmissionExportColumns {
+			values = append(values, column.value(record))
+		}
+
+		return export.WriteRecord(record, values)
+	})
+
+	export.Finish(err, "Failed to export loan submissions")
+}
+
+func (h *ExportHandler) HandleExportCustomers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	contentType, ok := negotiateExportContentType(r.Header.Get("Accept"))
+	if !ok {
+		writeExportError(w, http.StatusNotAcceptable, "Exports are available as "+ndjsonContentType+" or "+csvContentType)
+		return
+	}
+
+	query, err := parseLoanCustomerFilters(r.URL.Query())
+
+	var fields []string
+	if err == nil {
+		fields, err = parseLoanCustomerFields(r.URL.Query().Get("fields"))
+	}
+
+	if err != nil {
+		writeExportError(w, http.StatusBadRequest, "Invalid query parameters: "+err.Error())
+		return
+	}
+
+	columns := fields
+	if len(columns) == 0 {
+		columns = loanCustomerFieldNames
+	}
+
+	export := newExportWriter(w, contentType, "loan_customers", columns)
+
+	err = h.CustomerStore.StreamCustomers(r.Context(), query, func(row *datastore.LoanCustomerRow) error {
+		loanCustomer := convertLoanCustomerRow(row)
+
+		values := make([]any, 0, len(columns))
+		for _, column := range columns {
+			values = append(values, loanCustomerFields[column](loanCustomer))
+		}
+
+		if len(fields) > 0 {
+			return export.WriteRecord(projectLoanCustomer(loanCustomer, fields), values)
+		}
+
+		return export.WriteRecord(loanCustomer, values)
+	})
+
+	export.Finish(err, "Failed to export loan customers")
+}
+
+// negotiateExportContentType picks NDJSON or CSV for an Accept header, taking
+// the supported media range with the highest quality. A missing header or a
+// wildcard gets NDJSON.
+func negotiateExportContentType(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return ndjsonContentType, true
+	}
+
+	contentType, bestQuality := "", 0.0
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(mediaRange)
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		var candidate string
+		switch mediaType {
+		case ndjsonContentType, "application/*", "*/*":
+			candidate = ndjsonContentType
+		case csvContentType, "text/*":
+			candidate = csvContentType
+		default:
+			continue
+		}
+
+		if quality > bestQuality {
+			contentType, bestQuality = candidate, quality
+		}
+	}
+
+	return contentType, contentType != ""
+}
+
+func writeExportError(w http.ResponseWriter, statusCode int, errMsg string) {
+	responseBodyErr := ExportErrorResponse{
+		ErrorMessage: &errMsg,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(responseBodyErr)
+}
+
+// exportWriter writes the records of an export one by one as NDJSON lines or
+// CSV rows, flushing them to the client every exportFlushRows rows so that
+// memory use does not grow with the size of the export.
+type exportWriter struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+	json       *json.Encoder
+	csv        *csv.Writer
+	rows       int
+	// started is set once the first bytes of the body reach w, after which
+	// the status of the response can no longer change.
+	started bool
+}
+
+func newExportWriter(w http.ResponseWriter, contentType string, name string, columns []string) *exportWriter {
+	export := &exportWriter{
+		w:          w,
+		controller: http.NewResponseController(w),
+	}
+
+	extension := "ndjson"
+	if contentType == csvContentType {
+		extension = "csv"
+	}
+
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+extension))
+
+	if contentType == csvContentType {
+		export.csv = csv.NewWriter(export)
+		// Buffered until the first flush, so a failing export can still
+		// answer with an error instead.
+		export.csv.Write(columns)
+	} else {
+		export.json = json.NewEncoder(export)
+	}
+
+	return export
+}
+
+// WriteRecord adds one row: record as an NDJSON line, or values as a CSV row
+// in the order of the columns.
+func (e *exportWriter) WriteRecord(record any, values []any) error {
+	if e.csv != nil {
+		row := make([]string, 0, len(values))
+		for _, value := range values {
+			row = append(row, formatExportValue(value))
+		}
+
+		if err := e.csv.Write(row); err != nil {
+			return err
+		}
+	} else if err := e.json.Encode(record); err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%exportFlushRows == 0 {
+		return e.flush()
+	}
+
+	return nil
+}
+
+func (e *exportWriter) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+
+	if err := e.controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	return nil
+}
+
+// Finish completes the export after its rows were streamed with err as the
+// result. Before any of the body has been sent the client still gets an error
+// response. After that the status is already sent, so the connection is
+// aborted instead, which the client sees as an incomplete download rather
+// than a shorter file.
+func (e *exportWriter) Finish(err error, errMsg string) {
+	if err == nil {
+		err = e.flush()
+	}
+
+	if err == nil {
+		return
+	}
+
+	log.Printf("%s after %d rows: %v", errMsg, e.rows, err)
+
+	if e.started {
+		panic(http.ErrAbortHandler)
+	}
+
+	e.w.Header().Del("Content-Disposition")
+	writeExportError(e.w, http.StatusInternalServerError, errMsg)
+}
+
+// Write passes body bytes from the encoders on to the response.
+func (e *exportWriter) Write(p []byte) (int, error) {
+	e.started = true
+	return e.w.Write(p)
+}
+
+// formatExportValue writes a field value as CSV text. Missing values are left
+// empty.
+func formatExportValue(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case *string:
+		if value == nil {
+			return ""
+		}
+		return *value
+	case int:
+		return strconv.Itoa(value)
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case *int64:
+		if value == nil {
+			return ""
+		}
+		return strconv.FormatInt(*value, 10)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case *float64:
+		if value == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -3,22 +3,35 @@ package handler
 import (
 	"database/sql"
 	"encoding/json"
+	"math"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/alphaloan/vehicle/datastore"
+	"github.com/alphaloan/vehicle/fuzzy"
 )
 
 type LoanCustomerHandler struct {
-	CustomerStore   datastore.LoanCustomerStore
-	SubmissionStore datastore.LoanSubmissionStore
+	CustomerStore   datastore.CustomerRepository
+	SubmissionStore datastore.SubmissionRepository
+	PartyStore      datastore.LoanSubmissionPartyStore
+	SearchStore     datastore.CustomerSearchStore
 }
 
 func NewLoanCustomerHandler(
-	customerStore datastore.LoanCustomerStore,
-	submissionStore datastore.LoanSubmissionStore) *LoanCustomerHandler {
+	customerStore datastore.CustomerRepository,
+	submissionStore datastore.SubmissionRepository,
+	partyStore datastore.LoanSubmissionPartyStore,
+	searchStore datastore.CustomerSearchStore) *LoanCustomerHandler {
 	return &LoanCustomerHandler{
 		CustomerStore:   customerStore,
 		SubmissionStore: submissionStore,
+		PartyStore:      partyStore,
+		SearchStore:     searchStore,
 	}
 }
 
@@ -30,7 +43,29 @@ func (h *LoanCustomerHandler) HandleGetAllCustomers(w http.ResponseWriter, r *ht
 
 	w.Header().Set("Content-Type", "application/json")
 
-	loanCustomerRows, err := h.CustomerStore.GetAllCustomers()
+	query, err := parseLoanCustomerQuery(r.URL.Query())
+
+	var fields []string
+	if err == nil {
+		fields, err = parseLoanCustomerFields(r.URL.Query().Get("fields"))
+	}
+
+	if err != nil {
+		errMsg := "Invalid query parameters: " + err.Error()
+		responseBodyErr := GetAllLoanCustomersResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	// One row more than the page tells whether another page follows.
+	pageLimit := query.Limit
+	query.Limit++
+
+	loanCustomerRows, err := h.CustomerStore.GetAllCustomers(r.Context(), query)
 
 	if err != nil {
 		errMsg := "Failed to get all loan customers"
@@ -43,6 +78,19 @@ func (h *LoanCustomerHandler) HandleGetAllCustomers(w http.ResponseWriter, r *ht
 		return
 	}
 
+	var nextCursor *string
+	if len(loanCustomerRows) > pageLimit {
+		loanCustomerRows = loanCustomerRows[:pageLimit]
+
+		last := loanCustomerRows[pageLimit-1]
+		cursor := encodePageCursor(pageCursor{
+			Sort: loanCustomerSort,
+			Text: last.FullName,
+			ID:   last.CustomerID,
+		})
+		nextCursor = &cursor
+	}
+
 	loanCustomers := make([]LoanCustomer, 0, len(loanCustomerRows))
 	for _, row := range loanCustomerRows {
 		loanCustomers = append(loanCustomers, LoanCustomer{
@@ -58,14 +106,195 @@ func (h *LoanCustomerHandler) HandleGetAllCustomers(w http.ResponseWriter, r *ht
 		})
 	}
 
+	if len(fields) > 0 {
+		projectedCustomers := make([]map[string]any, 0, len(loanCustomers))
+		for _, loanCustomer := range loanCustomers {
+			projectedCustomers = append(projectedCustomers, projectLoanCustomer(loanCustomer, fields))
+		}
+
+		responseBody := GetAllLoanCustomerFieldsResponse{
+			Data:       &projectedCustomers,
+			NextCursor: nextCursor,
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(responseBody)
+		return
+	}
+
 	responseBody := GetAllLoanCustomersResponse{
-		Data: &loanCustomers,
+		Data:       &loanCustomers,
+		NextCursor: nextCursor,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+// loanCustomerSort names the only order of the customer list in its cursors.
+const loanCustomerSort = "full_name"
+
+// parseLoanCustomerQuery reads the filters and page of the customer list.
+func parseLoanCustomerQuery(values url.Values) (datastore.LoanCustomerQuery, error) {
+	query, err := parseLoanCustomerFilters(values)
+	if err != nil {
+		return query, err
+	}
+
+	if query.Limit, err = parsePageLimit(values.Get("limit")); err != nil {
+		return query, err
+	}
+
+	if param := values.Get("cursor"); param != "" {
+		cursor, err := decodePageCursor(param, loanCustomerSort)
+		if err != nil {
+			return query, err
+		}
+		query.After = &datastore.LoanCustomerCursor{
+			FullName:   cursor.Text,
+			CustomerID: cursor.ID,
+		}
+	}
+
+	return query, nil
+}
+
+// parseLoanCustomerFilters reads the filters of the customer list and export.
+func parseLoanCustomerFilters(values url.Values) (datastore.LoanCustomerQuery, error) {
+	query := datastore.LoanCustomerQuery{
+		City: strings.TrimSpace(values.Get("city")),
+	}
+
+	var err error
+	if query.MinMonthlyIncome, err = parseFloatParam(values, "min_monthly_income"); err != nil {
+		return query, err
+	}
+
+	if query.MaxMonthlyIncome, err = parseFloatParam(values, "max_monthly_income"); err != nil {
+		return query, err
+	}
+
+	return query, nil
+}
+
+const (
+	// minCustomerSearchLength is the shortest query the trigram index can find.
+	minCustomerSearchLength = 3
+	// customerSearchCandidates bounds how many rows are ranked per search.
+	customerSearchCandidates = 200
+	// minCustomerNameSimilarity is the trigram similarity a name needs to
+	// count as a fuzzy match, the pg_trgm default.
+	minCustomerNameSimilarity = 0.3
+)
+
+func (h *LoanCustomerHandler) HandleSearchCustomers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if utf8.RuneCountInString(query) < minCustomerSearchLength {
+		errMsg := "Query parameter q must have at least " + strconv.Itoa(minCustomerSearchLength) + " characters"
+		responseBodyErr := SearchLoanCustomersResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	limit, err := parsePageLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		errMsg := "Invalid query parameters: " + err.Error()
+		responseBodyErr := SearchLoanCustomersResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	loanCustomerRows, err := h.SearchStore.SearchCustomers(r.Context(), query, customerSearchCandidates)
+
+	if err != nil {
+		errMsg := "Failed to search loan customers"
+		responseBodyErr := SearchLoanCustomersResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	searchResults := rankCustomerSearchResults(query, loanCustomerRows)
+	if len(searchResults) > limit {
+		searchResults = searchResults[:limit]
+	}
+
+	responseBody := SearchLoanCustomersResponse{
+		Data: &searchResults,
 	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(responseBody)
 }
 
+// rankCustomerSearchResults scores the candidates of a search: a customer with
+// the query in its ID card number, phone number, email or name scores 1, any
+// other customer the trigram similarity of its name. Names below
+// minCustomerNameSimilarity are dropped and the rest sorted best first.
+func rankCustomerSearchResults(query string, rows []*datastore.LoanCustomerRow) []LoanCustomerSearchResult {
+	loweredQuery := strings.ToLower(query)
+
+	searchResults := make([]LoanCustomerSearchResult, 0, len(rows))
+	for _, row := range rows {
+		var matchedFields []string
+		for _, field := range []struct {
+			name  string
+			value string
+		}{
+			{"id_card_number", row.IDCardNumber},
+			{"phone_number", row.PhoneNumber},
+			{"email", row.Email.String},
+			{"full_name", row.FullName},
+		} {
+			if strings.Contains(strings.ToLower(field.value), loweredQuery) {
+				matchedFields = append(matchedFields, field.name)
+			}
+		}
+
+		score := 1.0
+		if len(matchedFields) == 0 {
+			score = fuzzy.WordSimilarity(query, row.FullName)
+			if score < minCustomerNameSimilarity {
+				continue
+			}
+			matchedFields = []string{"full_name"}
+		}
+
+		searchResults = append(searchResults, LoanCustomerSearchResult{
+			LoanCustomer:  convertLoanCustomerRow(row),
+			Score:         math.Round(score*1000) / 1000,
+			MatchedFields: matchedFields,
+		})
+	}
+
+	sort.SliceStable(searchResults, func(i, j int) bool {
+		if searchResults[i].Score != searchResults[j].Score {
+			return searchResults[i].Score > searchResults[j].Score
+		}
+		return searchResults[i].FullName < searchResults[j].FullName
+	})
+
+	return searchResults
+}
+
 func validateCustomerID(w http.ResponseWriter, customerID string) bool {
 	if customerID == "" {
 		errMsg := "Missing customer_id path variable"
@@ -105,7 +334,7 @@ func (h *LoanCustomerHandler) HandleGetCustomerInfo(w http.ResponseWriter, r *ht
 		return
 	}
 
-	loanCustomerWithAllSubmissionsRow, err := h.CustomerStore.GetCustomerByID(customerID)
+	loanCustomerWithAllSubmissionsRow, err := h.CustomerStore.GetCustomerByID(r.Context(), customerID)
 
 	if loanCustomerWithAllSubmissionsRow == nil {
 		errMsg := "Customer not found"
@@ -129,19 +358,50 @@ func (h *LoanCustomerHandler) HandleGetCustomerInfo(w http.ResponseWriter, r *ht
 		return
 	}
 
+	partyRows, err := h.PartyStore.GetPartiesByPrimaryCustomerID(r.Context(), customerID)
+
+	if err != nil {
+		errMsg := "Failed to get loan submission parties"
+		responseBodyErr := GetAllLoanCustomersResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	partyRowsBySubmissionID := make(map[string][]*datastore.LoanSubmissionPartyWithCustomerRow)
+	for _, partyRow := range partyRows {
+		partyRowsBySubmissionID[partyRow.SubmissionID] = append(partyRowsBySubmissionID[partyRow.SubmissionID], partyRow)
+	}
+
 	loanSubmissions := make([]LoanSubmission, 0, len(loanCustomerWithAllSubmissionsRow.LoanSubmissions))
 	for _, submissionRow := range loanCustomerWithAllSubmissionsRow.LoanSubmissions {
+		parties, householdIncome := convertLoanSubmissionParties(partyRowsBySubmissionID[submissionRow.SubmissionID])
+
 		loanSubmissions = append(loanSubmissions, LoanSubmission{
-			SubmissionID:            submissionRow.SubmissionID,
-			VehicleType:             submissionRow.VehicleType,
-			VehicleBrand:            submissionRow.VehicleBrand,
-			VehicleModel:            submissionRow.VehicleModel,
-			VehicleLicenseNumber:    submissionRow.VehicleLicenseNumber,
-			VehicleOdometer:         submissionRow.VehicleOdometer,
-			ManufacturingYear:       submissionRow.ManufacturingYear,
-			ProposedLoanAmount:      submissionRow.ProposedLoanAmount,
-			ProposedLoanTenureMonth: submissionRow.ProposedLoanTenure,
-			IsCommercialVehicle:     submissionRow.IsCommercialVehicle,
+			SubmissionID:               submissionRow.SubmissionID,
+			VehicleType:                submissionRow.VehicleType,
+			VehicleBrand:               submissionRow.VehicleBrand,
+			VehicleModel:               submissionRow.VehicleModel,
+			VehicleLicenseNumber:       submissionRow.VehicleLicenseNumber,
+			VehicleRegionCode:          convertNullStringPointer(submissionRow.VehicleRegionCode),
+			VehicleOdometer:            submissionRow.VehicleOdometer,
+			ManufacturingYear:          submissionRow.ManufacturingYear,
+			ProposedLoanAmount:         submissionRow.ProposedLoanAmount,
+			ProposedLoanTenureMonth:    submissionRow.ProposedLoanTenure,
+			AnnualInterestRate:         &submissionRow.AnnualInterestRate,
+			InterestMethod:             &submissionRow.InterestMethod,
+			ProductCode:                convertNullStringPointer(submissionRow.ProductCode),
+			CollateralValue:            convertNullFloat64(submissionRow.CollateralValue),
+			LoanToValue:                convertNullFloat64(submissionRow.LoanToValue),
+			IsCommercialVehicle:        submissionRow.IsCommercialVehicle,
+			LoanStatus:                 submissionRow.LoanStatus,
+			PolicyVersion:              convertNullInt64(submissionRow.PolicyVersion),
+			UnderwritingRecommendation: convertNullStringPointer(submissionRow.UnderwritingRecommendation),
+			Parties:                    &parties,
+			HouseholdIncome:            &householdIncome,
 		})
 	}
 
@@ -193,7 +453,7 @@ func (h *LoanCustomerHandler) HandleUpdateCustomer(w http.ResponseWriter, r *htt
 
 	LoanCustomerRow := convertLoanCustomer(&request)
 
-	updatedCustomerID, err := h.CustomerStore.UpdateCustomerByID(LoanCustomerRow, customerID)
+	updatedCustomerID, err := h.CustomerStore.UpdateCustomerByID(r.Context(), LoanCustomerRow, customerID)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -242,7 +502,7 @@ func (h *LoanCustomerHandler) HandleDeleteCustomer(w http.ResponseWriter, r *htt
 		return
 	}
 
-	deleteCustomerID, err := h.CustomerStore.DeleteCustomerByID(customerID)
+	deleteCustomerID, err := h.CustomerStore.DeleteCustomerByID(r.Context(), customerID)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
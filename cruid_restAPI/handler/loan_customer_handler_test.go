@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+
+	"github.com/alphaloan/vehicle/datastore"
+)
+
+func TestRankCustomerSearchResults(t *testing.T) {
+	rows := []*datastore.LoanCustomerRow{
+		{CustomerID: "sitti", IDCardNumber: "3171000000000001", FullName: "Sitti Aminah", PhoneNumber: "081200000001"},
+		{CustomerID: "budi", IDCardNumber: "3171000000000002", FullName: "Budi Santoso", PhoneNumber: "081200000002"},
+		{CustomerID: "siti", IDCardNumber: "3171000000000003", FullName: "Siti Rahayu", PhoneNumber: "081200000003"},
+		{CustomerID: "rina", IDCardNumber: "3171000000000004", FullName: "Rina Wati", PhoneNumber: "081200000004",
+			Email: sql.NullString{String: "SITI.rina@example.com", Valid: true}},
+		{CustomerID: "agus", IDCardNumber: "3171000000000005", FullName: "Agus Siti", PhoneNumber: "081200000005"},
+	}
+
+	type ranked struct {
+		customerID    string
+		score         float64
+		matchedFields []string
+	}
+
+	want := []ranked{
+		{"agus", 1, []string{"full_name"}},
+		{"rina", 1, []string{"email"}},
+		{"siti", 1, []string{"full_name"}},
+		{"sitti", 0.571, []string{"full_name"}},
+	}
+
+	var got []ranked
+	for _, result := range rankCustomerSearchResults("siti", rows) {
+		got = append(got, ranked{result.CustomerID, result.Score, result.MatchedFields})
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rankCustomerSearchResults() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRankCustomerSearchResultsMatchesEveryField(t *testing.T) {
+	rows := []*datastore.LoanCustomerRow{
+		{CustomerID: "budi", IDCardNumber: "3171081200000001", FullName: "Budi Santoso", PhoneNumber: "081200000001"},
+	}
+
+	results := rankCustomerSearchResults("0812", rows)
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+
+	if want := []string{"id_card_number", "phone_number"}; !reflect.DeepEqual(results[0].MatchedFields, want) {
+		t.Errorf("matched fields = %v, want %v", results[0].MatchedFields, want)
+	}
+}
@@ -0,0 +1,684 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alphaloan/vehicle/blobstore"
+	"github.com/alphaloan/vehicle/datastore"
+	"github.com/alphaloan/vehicle/documents"
+	"github.com/alphaloan/vehicle/loanstatus"
+	"github.com/google/uuid"
+)
+
+// maxDocumentFormOverheadBytes leaves room for the multipart boundaries and
+// the other form fields on top of the file itself.
+const maxDocumentFormOverheadBytes = 1 << 20
+
+type LoanDocumentHandler struct {
+	SubmissionStore datastore.SubmissionRepository
+	ProductStore    datastore.LoanProductStore
+	DocumentStore   datastore.LoanDocumentStore
+	BlobStore       blobstore.Store
+	Policy          documents.Policy
+}
+
+func NewLoanDocumentHandler(
+	submissionStore datastore.SubmissionRepository,
+	productStore datastore.LoanProductStore,
+	documentStore datastore.LoanDocumentStore,
+	blobStore blobstore.Store,
+	policy documents.Policy) *LoanDocumentHandler {
+	return &LoanDocumentHandler{
+		SubmissionStore: submissionStore,
+		ProductStore:    productStore,
+		DocumentStore:   documentStore,
+		BlobStore:       blobStore,
+		Policy:          policy,
+	}
+}
+
+// loadDocumentChecklist compares the documents uploaded for the submission
+// with the ones its loan product requires. Submissions made before products
+// existed have no product and therefore nothing to check.
+func loadDocumentChecklist(
+	ctx context.Context,
+	productStore datastore.LoanProductStore,
+	documentStore datastore.LoanDocumentStore,
+	loanSubmissionRow *datastore.LoanSubmissionRow) (documents.Checklist, error) {
+	var requiredTypes []string
+
+	if loanSubmissionRow.ProductCode.Valid {
+		loanProductRow, err := productStore.GetProductByCode(ctx, loanSubmissionRow.ProductCode.String)
+		if err != nil {
+			return documents.Checklist{}, err
+		}
+
+		if loanProductRow != nil {
+			requiredTypes = documents.ParseTypes(loanProductRow.RequiredDocumentTypes)
+		}
+	}
+
+	documentRows, err := documentStore.GetDocumentsBySubmissionID(ctx, loanSubmissionRow.SubmissionID)
+	if err != nil {
+		return documents.Checklist{}, err
+	}
+
+	uploaded := make([]documents.Document, 0, len(documentRows))
+	for _, row := range documentRows {
+		uploaded = append(uploaded, documents.Document{
+			DocumentType: row.DocumentType,
+			Verified:     row.VerifiedAt.Valid,
+		})
+	}
+
+	return documents.BuildChecklist(requiredTypes, uploaded), nil
+}
+
+func validateDocumentID(w http.ResponseWriter, documentID string) bool {
+	if documentID == "" {
+		errMsg := "Missing document_id path variable"
+		responseBodyErr := UploadLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return false
+	}
+
+	if !IsValidUUID(documentID) {
+		errMsg := "Invalid document_id: " + documentID
+		responseBodyErr := UploadLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return false
+	}
+
+	return true
+}
+
+// sniffMimeType detects the MIME type from the first bytes of the file, the
+// same way browsers do, and leaves the reader positioned at the start again.
+func sniffMimeType(file io.ReadSeeker) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	mimeType, _, err := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if err != nil {
+		return "", err
+	}
+
+	return mimeType, nil
+}
+
+func (h *LoanDocumentHandler) HandleUploadLoanDocument(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	submissionID := r.PathValue("submission_id")
+	if !validateLoanSubmissionID(w, submissionID) {
+		return
+	}
+
+	loanSubmissionRow, err := h.SubmissionStore.GetLoanSubmissionByID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get loan submission"
+		responseBodyErr := UploadLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if loanSubmissionRow == nil {
+		errMsg := "Loan submission not found"
+		responseBodyErr := UploadLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.Policy.MaxSizeBytes+maxDocumentFormOverheadBytes)
+
+	if err := r.ParseMultipartForm(maxDocumentFormOverheadBytes); err != nil {
+		errMsg := "Bad multipart form: " + err.Error()
+		status := http.StatusBadRequest
+
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			errMsg = "Document is larger than " + strconv.FormatInt(h.Policy.MaxSizeBytes, 10) + " bytes"
+			status = http.StatusRequestEntityTooLarge
+		}
+
+		responseBodyErr := UploadLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	documentType := strings.ToUpper(strings.TrimSpace(r.FormValue("document_type")))
+	if !documents.IsValidType(documentType) {
+		errMsg := "Invalid document_type: " + documentType + ", expected one of " + strings.Join(documents.Types(), ", ")
+		responseBodyErr := UploadLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	uploadedBy := strings.TrimSpace(r.FormValue("uploaded_by"))
+	if uploadedBy == "" {
+		errMsg := "Missing uploaded_by"
+		responseBodyErr := UploadLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	file, fileHeader, err := r.FormFile("file")
+
+	if err != nil {
+		errMsg := "Missing file"
+		responseBodyErr := UploadLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+	defer file.Close()
+
+	if fileHeader.Size > h.Policy.MaxSizeBytes {
+		errMsg := "Document is larger than " + strconv.FormatInt(h.Policy.MaxSizeBytes, 10) + " bytes"
+		responseBodyErr := UploadLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if fileHeader.Size == 0 {
+		errMsg := "Document is empty"
+		responseBodyErr := UploadLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	mimeType, err := sniffMimeType(file)
+
+	if err != nil {
+		errMsg := "Failed to read document"
+		responseBodyErr := UploadLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if !h.Policy.IsAllowedMimeType(mimeType) {
+		errMsg := "Unsupported document type " + mimeType + ", expected one of " + strings.Join(h.Policy.AllowedMimeTypes, ", ")
+		responseBodyErr := UploadLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	documentID := uuid.New().String()
+	storageKey := submissionID + "/" + documentID
+	checksum := sha256.New()
+
+	if err := h.BlobStore.Put(storageKey, io.TeeReader(file, checksum)); err != nil {
+		errMsg := "Failed to store document"
+		responseBodyErr := UploadLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	documentRow := &datastore.LoanDocumentRow{
+		DocumentID:     documentID,
+		SubmissionID:   submissionID,
+		DocumentType:   documentType,
+		FileName:       filepath.Base(fileHeader.Filename),
+		MimeType:       mimeType,
+		SizeBytes:      fileHeader.Size,
+		ChecksumSHA256: hex.EncodeToString(checksum.Sum(nil)),
+		StorageKey:     storageKey,
+		UploadedBy:     uploadedBy,
+		UploadedAt:     time.Now().Unix(),
+	}
+
+	if _, err := h.DocumentStore.CreateDocument(r.Context(), documentRow); err != nil {
+		if err := h.BlobStore.Delete(storageKey); err != nil {
+			log.Printf("Failed to remove orphaned document blob %s: %v", storageKey, err)
+		}
+
+		errMsg := "Failed to record document"
+		responseBodyErr := UploadLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	document := convertLoanDocumentRow(documentRow)
+	responseBody := UploadLoanDocumentResponse{
+		Data: &document,
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+func (h *LoanDocumentHandler) HandleGetAllLoanDocuments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	submissionID := r.PathValue("submission_id")
+	if !validateLoanSubmissionID(w, submissionID) {
+		return
+	}
+
+	documentRows, err := h.DocumentStore.GetDocumentsBySubmissionID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get loan documents"
+		responseBodyErr := GetAllLoanDocumentsResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	loanDocuments := make([]LoanDocument, 0, len(documentRows))
+	for _, row := range documentRows {
+		loanDocuments = append(loanDocuments, convertLoanDocumentRow(row))
+	}
+
+	responseBody := GetAllLoanDocumentsResponse{
+		Data: &loanDocuments,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+func (h *LoanDocumentHandler) HandleDownloadLoanDocument(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	submissionID := r.PathValue("submission_id")
+	if !validateLoanSubmissionID(w, submissionID) {
+		return
+	}
+
+	documentID := r.PathValue("document_id")
+	if !validateDocumentID(w, documentID) {
+		return
+	}
+
+	documentRow, err := h.DocumentStore.GetDocumentByID(r.Context(), submissionID, documentID)
+
+	if err != nil {
+		errMsg := "Failed to get loan document"
+		responseBodyErr := UploadLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if documentRow == nil {
+		errMsg := "Loan document not found"
+		responseBodyErr := UploadLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	content, err := h.BlobStore.Get(documentRow.StorageKey)
+
+	if err != nil {
+		errMsg := "Failed to read loan document"
+		if errors.Is(err, blobstore.ErrNotFound) {
+			errMsg = "Loan document content is missing from storage"
+		}
+
+		responseBodyErr := UploadLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", documentRow.MimeType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": documentRow.FileName}))
+	w.Header().Set("Content-Length", strconv.FormatInt(documentRow.SizeBytes, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, content); err != nil {
+		log.Printf("Failed to send loan document %s: %v", documentID, err)
+	}
+}
+
+func (h *LoanDocumentHandler) HandleVerifyLoanDocument(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	submissionID := r.PathValue("submission_id")
+	if !validateLoanSubmissionID(w, submissionID) {
+		return
+	}
+
+	documentID := r.PathValue("document_id")
+	if !validateDocumentID(w, documentID) {
+		return
+	}
+
+	var request VerifyLoanDocumentRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Bad request body", http.StatusBadRequest)
+		return
+	}
+
+	verifiedBy := strings.TrimSpace(request.VerifiedBy)
+	if verifiedBy == "" {
+		errMsg := "Missing verified_by"
+		responseBodyErr := VerifyLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+			DocumentID:   &documentID,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	verifiedDocumentID, err := h.DocumentStore.VerifyDocumentByID(r.Context(), submissionID, documentID, verifiedBy, time.Now().Unix())
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errMsg := "Loan document not found"
+			responseBodyErr := VerifyLoanDocumentResponse{
+				ErrorMessage: &errMsg,
+				DocumentID:   &documentID,
+			}
+
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(responseBodyErr)
+		} else {
+			errMsg := "Failed to verify loan document"
+			responseBodyErr := VerifyLoanDocumentResponse{
+				ErrorMessage: &errMsg,
+				DocumentID:   &documentID,
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(responseBodyErr)
+		}
+		return
+	}
+
+	responseBody := VerifyLoanDocumentResponse{
+		DocumentID: &verifiedDocumentID,
+		Verified:   true,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+func (h *LoanDocumentHandler) HandleGetLoanDocumentChecklist(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	submissionID := r.PathValue("submission_id")
+	if !validateLoanSubmissionID(w, submissionID) {
+		return
+	}
+
+	loanSubmissionRow, err := h.SubmissionStore.GetLoanSubmissionByID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get loan submission"
+		responseBodyErr := GetLoanDocumentChecklistResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if loanSubmissionRow == nil {
+		errMsg := "Loan submission not found"
+		responseBodyErr := GetLoanDocumentChecklistResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	checklist, err := loadDocumentChecklist(r.Context(), h.ProductStore, h.DocumentStore, loanSubmissionRow)
+
+	if err != nil {
+		errMsg := "Failed to get document checklist"
+		responseBodyErr := GetLoanDocumentChecklistResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	documentChecklist := convertLoanDocumentChecklist(submissionID, loanSubmissionRow.ProductCode, checklist)
+
+	responseBody := GetLoanDocumentChecklistResponse{
+		Data: &documentChecklist,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+func (h *LoanDocumentHandler) HandleDeleteLoanDocument(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Only DELETE method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	submissionID := r.PathValue("submission_id")
+	if !validateLoanSubmissionID(w, submissionID) {
+		return
+	}
+
+	documentID := r.PathValue("document_id")
+	if !validateDocumentID(w, documentID) {
+		return
+	}
+
+	loanSubmissionRow, err := h.SubmissionStore.GetLoanSubmissionByID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get loan submission"
+		responseBodyErr := DeleteLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if loanSubmissionRow == nil {
+		errMsg := "Loan submission not found"
+		responseBodyErr := DeleteLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if !loanstatus.DocumentsDeletable(loanSubmissionRow.LoanStatus) {
+		errMsg := "Documents of a loan submission in status " + loanSubmissionRow.LoanStatus + " can no longer be deleted"
+		responseBodyErr := DeleteLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	documentRow, err := h.DocumentStore.GetDocumentByID(r.Context(), submissionID, documentID)
+
+	if err != nil {
+		errMsg := "Failed to get loan document"
+		responseBodyErr := DeleteLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if documentRow == nil {
+		errMsg := "Loan document not found"
+		responseBodyErr := DeleteLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	// The delete is conditional on the status read above, so a submission
+	// approved in the meantime keeps its documents.
+	deletedDocumentID, err := h.DocumentStore.DeleteDocumentByID(r.Context(), submissionID, documentID, loanSubmissionRow.LoanStatus)
+
+	if err != nil {
+		errMsg := "Failed to delete loan document"
+		status := http.StatusInternalServerError
+		if errors.Is(err, sql.ErrNoRows) {
+			errMsg = "Loan submission status changed, please retry"
+			status = http.StatusConflict
+		}
+
+		responseBodyErr := DeleteLoanDocumentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	// The metadata row is what makes a document visible, so a blob that fails
+	// to delete is only logged rather than failing the request.
+	if err := h.BlobStore.Delete(documentRow.StorageKey); err != nil && !errors.Is(err, blobstore.ErrNotFound) {
+		log.Printf("Failed to remove document blob %s: %v", documentRow.StorageKey, err)
+	}
+
+	responseBody := DeleteLoanDocumentResponse{
+		DocumentID: &deletedDocumentID,
+		Deleted:    true,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
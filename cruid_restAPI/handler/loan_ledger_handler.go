@@ -0,0 +1,653 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alphaloan/vehicle/amortization"
+	"github.com/alphaloan/vehicle/collections"
+	"github.com/alphaloan/vehicle/datastore"
+	"github.com/alphaloan/vehicle/ledger"
+	"github.com/alphaloan/vehicle/loanstatus"
+	"github.com/google/uuid"
+)
+
+type LoanLedgerHandler struct {
+	UnitOfWork          datastore.UnitOfWork
+	SubmissionStore     datastore.SubmissionRepository
+	InstallmentStore    datastore.LoanInstallmentStore
+	PaymentStore        datastore.LoanPaymentStore
+	StatusHistoryStore  datastore.LoanStatusHistoryStore
+	CollateralLienStore datastore.CollateralLienStore
+	CollectionsPolicy   collections.Policy
+}
+
+func NewLoanLedgerHandler(
+	unitOfWork datastore.UnitOfWork,
+	submissionStore datastore.SubmissionRepository,
+	installmentStore datastore.LoanInstallmentStore,
+	paymentStore datastore.LoanPaymentStore,
+	statusHistoryStore datastore.LoanStatusHistoryStore,
+	collateralLienStore datastore.CollateralLienStore,
+	collectionsPolicy collections.Policy) *LoanLedgerHandler {
+	return &LoanLedgerHandler{
+		UnitOfWork:          unitOfWork,
+		SubmissionStore:     submissionStore,
+		InstallmentStore:    installmentStore,
+		PaymentStore:        paymentStore,
+		StatusHistoryStore:  statusHistoryStore,
+		CollateralLienStore: collateralLienStore,
+		CollectionsPolicy:   collectionsPolicy,
+	}
+}
+
+// loanLedgerError fails a ledger transaction with the response to send.
+type loanLedgerError struct {
+	status  int
+	message string
+	err     error
+}
+
+func (e *loanLedgerError) Error() string {
+	if e.err == nil {
+		return e.message
+	}
+	return e.message + ": " + e.err.Error()
+}
+
+func (e *loanLedgerError) Unwrap() error {
+	return e.err
+}
+
+func (h *LoanLedgerHandler) HandleRecordLoanPayment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	submissionID := r.PathValue("submission_id")
+	if !validateLoanSubmissionID(w, submissionID) {
+		return
+	}
+
+	var request RecordLoanPaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Bad request body", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().Unix()
+
+	paidAt := now
+	if request.PaidAt != nil {
+		paidAt = *request.PaidAt
+	}
+
+	if request.Amount <= 0 || paidAt > now {
+		errMsg := "Payment amount must be greater than zero and paid_at must not be in the future"
+		responseBodyErr := RecordLoanPaymentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	loanSubmissionRow, err := h.SubmissionStore.GetLoanSubmissionByID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get loan submission"
+		responseBodyErr := RecordLoanPaymentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if loanSubmissionRow == nil {
+		errMsg := "Loan submission not found"
+		responseBodyErr := RecordLoanPaymentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if loanSubmissionRow.LoanStatus != loanstatus.Disbursed {
+		errMsg := "Payments can only be recorded for disbursed loans, current status: " + loanSubmissionRow.LoanStatus
+		responseBodyErr := RecordLoanPaymentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	var paymentRow *datastore.LoanPaymentRow
+	var paymentID string
+	var runningBalance float64
+
+	// The installments are read and paid in one transaction. A payment
+	// recorded by another request in between makes RecordPayment fail with
+	// sql.ErrNoRows, so the same balance is never paid twice.
+	err = h.UnitOfWork.Do(r.Context(), func(tx datastore.Tx) error {
+		installmentRows, err := h.InstallmentStore.WithTx(tx).GetInstallmentsBySubmissionID(r.Context(), submissionID)
+
+		if err != nil {
+			return &loanLedgerError{http.StatusInternalServerError, "Failed to get loan schedule", err}
+		}
+
+		balances := convertInstallmentBalances(installmentRows)
+		allocation := ledger.Apply(request.Amount, balances)
+
+		paymentRow = &datastore.LoanPaymentRow{
+			PaymentID:        uuid.New().String(),
+			SubmissionID:     submissionID,
+			Amount:           amortization.Round(request.Amount),
+			FeeApplied:       allocation.Fee,
+			InterestApplied:  allocation.Interest,
+			PrincipalApplied: allocation.Principal,
+			UnappliedAmount:  allocation.Unapplied,
+			Reference:        convertNullString(request.Reference),
+			PaidAt:           paidAt,
+			CreatedAt:        now,
+		}
+
+		paymentID, err = h.PaymentStore.WithTx(tx).RecordPayment(r.Context(), paymentRow, convertInstallmentAllocations(allocation.Installments, installmentRows))
+
+		if err == sql.ErrNoRows {
+			return &loanLedgerError{http.StatusConflict, "Loan schedule was changed by another payment, please retry", err}
+		}
+
+		if err != nil {
+			return &loanLedgerError{http.StatusInternalServerError, "Failed to record loan payment", err}
+		}
+
+		runningBalance = amortization.Round(ledger.Outstanding(balances) - allocation.Fee - allocation.Interest - allocation.Principal)
+
+		return nil
+	})
+
+	if err != nil {
+		errMsg, statusCode := "Failed to record loan payment", http.StatusInternalServerError
+
+		var ledgerErr *loanLedgerError
+		if errors.As(err, &ledgerErr) {
+			errMsg, statusCode = ledgerErr.message, ledgerErr.status
+		}
+
+		responseBodyErr := RecordLoanPaymentResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	responseBody := RecordLoanPaymentResponse{
+		Data: &LoanPayment{
+			PaymentID:        paymentID,
+			Amount:           paymentRow.Amount,
+			FeeApplied:       paymentRow.FeeApplied,
+			InterestApplied:  paymentRow.InterestApplied,
+			PrincipalApplied: paymentRow.PrincipalApplied,
+			UnappliedAmount:  paymentRow.UnappliedAmount,
+			RunningBalance:   runningBalance,
+			Reference:        request.Reference,
+			PaidAt:           paidAt,
+		},
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+func (h *LoanLedgerHandler) HandleGetLoanLedger(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	submissionID := r.PathValue("submission_id")
+	if !validateLoanSubmissionID(w, submissionID) {
+		return
+	}
+
+	loanSubmissionRow, err := h.SubmissionStore.GetLoanSubmissionByID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get loan submission"
+		responseBodyErr := GetLoanLedgerResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if loanSubmissionRow == nil {
+		errMsg := "Loan submission not found"
+		responseBodyErr := GetLoanLedgerResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	installmentRows, err := h.InstallmentStore.GetInstallmentsBySubmissionID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get loan schedule"
+		responseBodyErr := GetLoanLedgerResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	paymentRows, err := h.PaymentStore.GetPaymentsBySubmissionID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get loan payments"
+		responseBodyErr := GetLoanLedgerResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	loanLedger := LoanLedger{
+		SubmissionID: submissionID,
+		LoanStatus:   loanSubmissionRow.LoanStatus,
+		Payments:     make([]LoanPayment, 0, len(paymentRows)),
+	}
+
+	for _, balance := range convertInstallmentBalances(installmentRows) {
+		loanLedger.OutstandingFees = amortization.Round(loanLedger.OutstandingFees + balance.FeeDue)
+		loanLedger.OutstandingInterest = amortization.Round(loanLedger.OutstandingInterest + balance.InterestDue)
+		loanLedger.OutstandingPrincipal = amortization.Round(loanLedger.OutstandingPrincipal + balance.PrincipalDue)
+	}
+
+	for _, row := range installmentRows {
+		loanLedger.TotalCharged = amortization.Round(loanLedger.TotalCharged + row.TotalAmount + row.FeeAmount)
+	}
+
+	runningBalance := loanLedger.TotalCharged
+	for _, row := range paymentRows {
+		runningBalance = amortization.Round(runningBalance - row.FeeApplied - row.InterestApplied - row.PrincipalApplied - row.WaivedInterest)
+
+		loanLedger.TotalPaid = amortization.Round(loanLedger.TotalPaid + row.Amount)
+		loanLedger.CreditBalance = amortization.Round(loanLedger.CreditBalance + row.UnappliedAmount)
+		loanLedger.Payments = append(loanLedger.Payments, LoanPayment{
+			PaymentID:           row.PaymentID,
+			Amount:              row.Amount,
+			FeeApplied:          row.FeeApplied,
+			InterestApplied:     row.InterestApplied,
+			PrincipalApplied:    row.PrincipalApplied,
+			UnappliedAmount:     row.UnappliedAmount,
+			EarlyTerminationFee: row.EarlyTerminationFee,
+			WaivedInterest:      row.WaivedInterest,
+			RunningBalance:      runningBalance,
+			Reference:           convertNullStringPointer(row.Reference),
+			PaidAt:              row.PaidAt,
+		})
+	}
+
+	loanLedger.OutstandingBalance = amortization.Round(loanLedger.OutstandingFees + loanLedger.OutstandingInterest + loanLedger.OutstandingPrincipal)
+
+	responseBody := GetLoanLedgerResponse{
+		Data: &loanLedger,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+// calculatePayoff loads everything the payoff of a disbursed loan depends on.
+// It writes the error response itself and returns false when the payoff cannot
+// be calculated.
+func (h *LoanLedgerHandler) calculatePayoff(w http.ResponseWriter, r *http.Request, submissionID string, asOf time.Time) (*ledger.Payoff, bool) {
+	loanSubmissionRow, err := h.SubmissionStore.GetLoanSubmissionByID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get loan submission"
+		responseBodyErr := GetLoanPayoffResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return nil, false
+	}
+
+	if loanSubmissionRow == nil {
+		errMsg := "Loan submission not found"
+		responseBodyErr := GetLoanPayoffResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return nil, false
+	}
+
+	if loanSubmissionRow.LoanStatus != loanstatus.Disbursed {
+		errMsg := "Only disbursed loans can be paid off, current status: " + loanSubmissionRow.LoanStatus
+		responseBodyErr := GetLoanPayoffResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return nil, false
+	}
+
+	_, payoff, err := h.loadPayoff(r.Context(), &h.InstallmentStore, &h.PaymentStore, submissionID, asOf)
+
+	if err != nil {
+		errMsg, statusCode := "Failed to calculate loan payoff", http.StatusInternalServerError
+
+		var ledgerErr *loanLedgerError
+		if errors.As(err, &ledgerErr) {
+			errMsg, statusCode = ledgerErr.message, ledgerErr.status
+		}
+
+		responseBodyErr := GetLoanPayoffResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return nil, false
+	}
+
+	return payoff, true
+}
+
+// loadPayoff calculates the payoff at asOf from the installments and payments
+// read through installmentStore and paymentStore. Its errors are
+// *loanLedgerError.
+func (h *LoanLedgerHandler) loadPayoff(ctx context.Context, installmentStore *datastore.LoanInstallmentStore, paymentStore *datastore.LoanPaymentStore, submissionID string, asOf time.Time) ([]*datastore.LoanInstallmentRow, *ledger.Payoff, error) {
+	installmentRows, err := installmentStore.GetInstallmentsBySubmissionID(ctx, submissionID)
+
+	if err != nil {
+		return nil, nil, &loanLedgerError{http.StatusInternalServerError, "Failed to get loan schedule", err}
+	}
+
+	paymentRows, err := paymentStore.GetPaymentsBySubmissionID(ctx, submissionID)
+
+	if err != nil {
+		return nil, nil, &loanLedgerError{http.StatusInternalServerError, "Failed to get loan payments", err}
+	}
+
+	var creditBalance float64
+	for _, row := range paymentRows {
+		creditBalance = amortization.Round(creditBalance + row.UnappliedAmount)
+	}
+
+	payoff := ledger.CalculatePayoff(convertInstallmentBalances(installmentRows), asOf, h.CollectionsPolicy.EarlyTerminationFeeRate, creditBalance)
+
+	return installmentRows, &payoff, nil
+}
+
+func (h *LoanLedgerHandler) HandleGetLoanPayoff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	submissionID := r.PathValue("submission_id")
+	if !validateLoanSubmissionID(w, submissionID) {
+		return
+	}
+
+	asOf := time.Now()
+	if asOfParam := r.URL.Query().Get("as_of"); asOfParam != "" {
+		asOfUnix, err := strconv.ParseInt(asOfParam, 10, 64)
+		if err != nil {
+			errMsg := "Invalid as_of, expected a unix timestamp: " + asOfParam
+			responseBodyErr := GetLoanPayoffResponse{
+				ErrorMessage: &errMsg,
+			}
+
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(responseBodyErr)
+			return
+		}
+
+		asOf = time.Unix(asOfUnix, 0)
+	}
+
+	payoff, ok := h.calculatePayoff(w, r, submissionID, asOf)
+	if !ok {
+		return
+	}
+
+	loanPayoff := convertLoanPayoff(submissionID, *payoff)
+
+	responseBody := GetLoanPayoffResponse{
+		Data: &loanPayoff,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+func (h *LoanLedgerHandler) HandleSettleLoan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	submissionID := r.PathValue("submission_id")
+	if !validateLoanSubmissionID(w, submissionID) {
+		return
+	}
+
+	var request SettleLoanRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Bad request body", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().Unix()
+
+	paidAt := now
+	if request.PaidAt != nil {
+		paidAt = *request.PaidAt
+	}
+
+	// A zero amount settles a loan whose credit balance covers the payoff.
+	if request.Actor == "" || request.Amount < 0 || paidAt > now {
+		errMsg := "Settlement needs an actor, an amount that is not negative and a paid_at that is not in the future"
+		responseBodyErr := SettleLoanResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	loanSubmissionRow, err := h.SubmissionStore.GetLoanSubmissionByID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get loan submission"
+		responseBodyErr := SettleLoanResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if loanSubmissionRow == nil {
+		errMsg := "Loan submission not found"
+		responseBodyErr := SettleLoanResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if loanSubmissionRow.LoanStatus != loanstatus.Disbursed {
+		errMsg := "Only disbursed loans can be paid off, current status: " + loanSubmissionRow.LoanStatus
+		responseBodyErr := SettleLoanResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	amount := amortization.Round(request.Amount)
+
+	var payoff *ledger.Payoff
+	var paymentRow *datastore.LoanPaymentRow
+	var paymentID string
+
+	// The loan is closed, paid off, given its history entry and has its
+	// collateral released in one transaction. Closing it first only succeeds
+	// while it is still disbursed, so a concurrent settlement or transition
+	// makes this one roll back instead of settling the loan twice.
+	err = h.UnitOfWork.Do(r.Context(), func(tx datastore.Tx) error {
+		_, err := h.SubmissionStore.WithTx(tx).UpdateLoanStatusByID(r.Context(), submissionID, loanstatus.Disbursed, loanstatus.Closed, now)
+
+		if err == sql.ErrNoRows {
+			return &loanLedgerError{http.StatusConflict, "Loan status was changed by another request, please retry", err}
+		}
+
+		if err != nil {
+			return &loanLedgerError{http.StatusInternalServerError, "Failed to close loan submission", err}
+		}
+
+		var installmentRows []*datastore.LoanInstallmentRow
+		installmentRows, payoff, err = h.loadPayoff(r.Context(), h.InstallmentStore.WithTx(tx), h.PaymentStore.WithTx(tx), submissionID, time.Unix(paidAt, 0))
+
+		if err != nil {
+			return err
+		}
+
+		if amount < payoff.PayoffAmount {
+			return &loanLedgerError{http.StatusUnprocessableEntity, "Settlement amount is less than the payoff amount", nil}
+		}
+
+		paymentRow = &datastore.LoanPaymentRow{
+			PaymentID:        uuid.New().String(),
+			SubmissionID:     submissionID,
+			Amount:           amount,
+			FeeApplied:       payoff.OutstandingFees,
+			InterestApplied:  payoff.AccruedInterest,
+			PrincipalApplied: payoff.OutstandingPrincipal,
+			// Negative when part of the credit balance pays for the
+			// settlement, so the balance left afterwards is the sum of the
+			// unapplied amounts of all payments.
+			UnappliedAmount:     amortization.Round(amount - payoff.Due()),
+			EarlyTerminationFee: payoff.EarlyTerminationFee,
+			WaivedInterest:      payoff.WaivedInterest,
+			Reference:           convertNullString(request.Reference),
+			PaidAt:              paidAt,
+			CreatedAt:           now,
+		}
+
+		paymentID, err = h.PaymentStore.WithTx(tx).RecordPayment(r.Context(), paymentRow, convertInstallmentAllocations(payoff.Installments, installmentRows))
+
+		if err == sql.ErrNoRows {
+			return &loanLedgerError{http.StatusConflict, "Loan schedule was changed by another payment, please retry", err}
+		}
+
+		if err != nil {
+			return &loanLedgerError{http.StatusInternalServerError, "Failed to record settlement payment", err}
+		}
+
+		settlementReason := "Settled early with payment " + paymentID
+		statusHistoryRow := convertLoanStatusHistory(submissionID, loanstatus.Disbursed, loanstatus.Closed, request.Actor, &settlementReason, now)
+
+		if _, err := h.StatusHistoryStore.WithTx(tx).AppendStatusHistory(r.Context(), statusHistoryRow); err != nil {
+			return &loanLedgerError{http.StatusInternalServerError, "Failed to record loan status history", err}
+		}
+
+		if err := h.CollateralLienStore.WithTx(tx).ReleaseCollateral(r.Context(), submissionID, now, loanstatus.Closed); err != nil {
+			return &loanLedgerError{http.StatusInternalServerError, "Failed to release collateral", err}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		errMsg, statusCode := "Failed to settle loan", http.StatusInternalServerError
+
+		var ledgerErr *loanLedgerError
+		if errors.As(err, &ledgerErr) {
+			errMsg, statusCode = ledgerErr.message, ledgerErr.status
+		}
+
+		responseBodyErr := SettleLoanResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		if statusCode == http.StatusUnprocessableEntity {
+			responseBodyErr.Data = &LoanSettlement{
+				LoanStatus: loanSubmissionRow.LoanStatus,
+				Payoff:     convertLoanPayoff(submissionID, *payoff),
+			}
+		}
+
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	responseBody := SettleLoanResponse{
+		Data: &LoanSettlement{
+			LoanStatus: loanstatus.Closed,
+			Payoff:     convertLoanPayoff(submissionID, *payoff),
+			Payment: &LoanPayment{
+				PaymentID:           paymentID,
+				Amount:              paymentRow.Amount,
+				FeeApplied:          paymentRow.FeeApplied,
+				InterestApplied:     paymentRow.InterestApplied,
+				PrincipalApplied:    paymentRow.PrincipalApplied,
+				UnappliedAmount:     paymentRow.UnappliedAmount,
+				EarlyTerminationFee: paymentRow.EarlyTerminationFee,
+				WaivedInterest:      paymentRow.WaivedInterest,
+				Reference:           request.Reference,
+				PaidAt:              paidAt,
+			},
+		},
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
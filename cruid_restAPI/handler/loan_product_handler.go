@@ -0,0 +1,426 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alphaloan/vehicle/amortization"
+	"github.com/alphaloan/vehicle/datastore"
+	"github.com/alphaloan/vehicle/documents"
+)
+
+type LoanProductHandler struct {
+	ProductStore datastore.LoanProductStore
+}
+
+func NewLoanProductHandler(productStore datastore.LoanProductStore) *LoanProductHandler {
+	return &LoanProductHandler{
+		ProductStore: productStore,
+	}
+}
+
+func validateLoanProduct(product *LoanProduct) error {
+	if strings.TrimSpace(product.ProductCode) == "" {
+		return errors.New("product_code is required")
+	}
+
+	if strings.TrimSpace(product.ProductName) == "" {
+		return errors.New("product_name is required")
+	}
+
+	if product.AnnualInterestRate < 0 {
+		return errors.New("annual_interest_rate must not be negative")
+	}
+
+	if !amortization.IsValidMethod(product.InterestMethod) {
+		return fmt.Errorf("invalid interest_method: %s", product.InterestMethod)
+	}
+
+	if product.MinLoanAmount <= 0 || product.MaxLoanAmount < product.MinLoanAmount {
+		return errors.New("min_loan_amount must be positive and not greater than max_loan_amount")
+	}
+
+	if product.MinTenureMonth <= 0 || product.MaxTenureMonth < product.MinTenureMonth {
+		return errors.New("min_tenure_month must be positive and not greater than max_tenure_month")
+	}
+
+	if len(product.EligibleVehicleTypes) == 0 {
+		return errors.New("eligible_vehicle_types must not be empty")
+	}
+
+	for _, vehicleType := range product.EligibleVehicleTypes {
+		if strings.TrimSpace(vehicleType) == "" || strings.Contains(vehicleType, ",") {
+			return fmt.Errorf("invalid eligible vehicle type: %q", vehicleType)
+		}
+	}
+
+	for _, documentType := range product.RequiredDocumentTypes {
+		if !documents.IsValidType(strings.ToUpper(strings.TrimSpace(documentType))) {
+			return fmt.Errorf("invalid required document type: %q", documentType)
+		}
+	}
+
+	if product.EffectiveUntil != nil && *product.EffectiveUntil <= product.EffectiveFrom {
+		return errors.New("effective_until must be after effective_from")
+	}
+
+	return nil
+}
+
+// validateLoanProposalForProduct lists every way the proposal falls outside the
+// product, so the applicant can fix them all in one go.
+func validateLoanProposalForProduct(product *datastore.LoanProductRow, proposal *LoanSubmission, now time.Time) []string {
+	var violations []string
+
+	if now.Unix() < product.EffectiveFrom || (product.EffectiveUntil.Valid && now.Unix() >= product.EffectiveUntil.Int64) {
+		violations = append(violations, "product is not offered at this time")
+	}
+
+	if proposal.ProposedLoanAmount < product.MinLoanAmount || proposal.ProposedLoanAmount > product.MaxLoanAmount {
+		violations = append(violations, fmt.Sprintf("proposed_loan_amount must be between %d and %d", product.MinLoanAmount, product.MaxLoanAmount))
+	}
+
+	if proposal.ProposedLoanTenureMonth < product.MinTenureMonth || proposal.ProposedLoanTenureMonth > product.MaxTenureMonth {
+		violations = append(violations, fmt.Sprintf("proposed_loan_tenure_month must be between %d and %d", product.MinTenureMonth, product.MaxTenureMonth))
+	}
+
+	eligibleVehicleTypes := strings.Split(product.EligibleVehicleTypes, ",")
+	if !slices.Contains(eligibleVehicleTypes, strings.ToLower(strings.TrimSpace(proposal.VehicleType))) {
+		violations = append(violations, fmt.Sprintf("vehicle_type must be one of %s", strings.Join(eligibleVehicleTypes, ", ")))
+	}
+
+	if proposal.IsCommercialVehicle != product.IsCommercialVehicle {
+		if product.IsCommercialVehicle {
+			violations = append(violations, "product is only for commercial vehicles")
+		} else {
+			violations = append(violations, "product is not for commercial vehicles")
+		}
+	}
+
+	return violations
+}
+
+func validateProductCode(w http.ResponseWriter, productCode string) bool {
+	if productCode == "" {
+		errMsg := "Missing product_code path variable"
+		responseBodyErr := GetLoanProductResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return false
+	}
+
+	return true
+}
+
+func (h *LoanProductHandler) HandleGetAllProducts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	loanProductRows, err := h.ProductStore.GetAllProducts(r.Context())
+
+	if err != nil {
+		errMsg := "Failed to get all loan products"
+		responseBodyErr := GetAllLoanProductsResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	loanProducts := make([]LoanProduct, 0, len(loanProductRows))
+	for _, row := range loanProductRows {
+		loanProducts = append(loanProducts, convertLoanProductRow(row))
+	}
+
+	responseBody := GetAllLoanProductsResponse{
+		Data: &loanProducts,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+func (h *LoanProductHandler) HandleCreateProduct(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var request LoanProduct
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Bad request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateLoanProduct(&request); err != nil {
+		errMsg := "Invalid loan product: " + err.Error()
+		responseBodyErr := CreateLoanProductResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	loanProductRow := convertLoanProduct(&request)
+
+	existingProductRow, err := h.ProductStore.GetProductByCode(r.Context(), loanProductRow.ProductCode)
+
+	if err != nil {
+		errMsg := "Failed to get loan product"
+		responseBodyErr := CreateLoanProductResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if existingProductRow != nil {
+		errMsg := "Product code already exists"
+		responseBodyErr := CreateLoanProductResponse{
+			ErrorMessage: &errMsg,
+			ProductCode:  &loanProductRow.ProductCode,
+		}
+
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	createdProductCode, err := h.ProductStore.CreateProduct(r.Context(), loanProductRow)
+
+	if err != nil {
+		errMsg := "Failed to create loan product"
+		responseBodyErr := CreateLoanProductResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	responseBody := CreateLoanProductResponse{
+		ProductCode: &createdProductCode,
+		Created:     true,
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+func (h *LoanProductHandler) HandleGetProductInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	productCode := r.PathValue("product_code")
+	if !validateProductCode(w, productCode) {
+		return
+	}
+
+	loanProductRow, err := h.ProductStore.GetProductByCode(r.Context(), productCode)
+
+	if err != nil {
+		errMsg := "Failed to get loan product"
+		responseBodyErr := GetLoanProductResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if loanProductRow == nil {
+		errMsg := "Product not found"
+		responseBodyErr := GetLoanProductResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	loanProduct := convertLoanProductRow(loanProductRow)
+
+	responseBody := GetLoanProductResponse{
+		Data: &loanProduct,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+func (h *LoanProductHandler) HandleUpdateProduct(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "Only PATCH method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	productCode := r.PathValue("product_code")
+	if !validateProductCode(w, productCode) {
+		return
+	}
+
+	var request LoanProduct
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Bad request body", http.StatusBadRequest)
+		return
+	}
+
+	request.ProductCode = productCode
+
+	if err := validateLoanProduct(&request); err != nil {
+		errMsg := "Invalid loan product: " + err.Error()
+		responseBodyErr := UpdateLoanProductResponse{
+			ErrorMessage: &errMsg,
+			ProductCode:  &productCode,
+			Updated:      false,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	loanProductRow := convertLoanProduct(&request)
+
+	updatedProductCode, err := h.ProductStore.UpdateProductByCode(r.Context(), loanProductRow, productCode)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errMsg := "Product code not found"
+			responseBodyErr := UpdateLoanProductResponse{
+				ErrorMessage: &errMsg,
+				ProductCode:  &productCode,
+				Updated:      false,
+			}
+
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(responseBodyErr)
+		} else {
+			errMsg := "Failed to update loan product"
+			responseBodyErr := UpdateLoanProductResponse{
+				ErrorMessage: &errMsg,
+				ProductCode:  &productCode,
+				Updated:      false,
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(responseBodyErr)
+		}
+		return
+	}
+
+	responseBody := UpdateLoanProductResponse{
+		ProductCode: &updatedProductCode,
+		Updated:     true,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+func (h *LoanProductHandler) HandleDeleteProduct(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Only DELETE method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	productCode := r.PathValue("product_code")
+	if !validateProductCode(w, productCode) {
+		return
+	}
+
+	inUse, err := h.ProductStore.IsProductInUse(r.Context(), productCode)
+
+	if err != nil {
+		errMsg := "Failed to delete loan product"
+		responseBodyErr := DeleteLoanProductResponse{
+			ErrorMessage: &errMsg,
+			ProductCode:  &productCode,
+			Deleted:      false,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if inUse {
+		errMsg := "Product is referenced by loan submissions; set effective_until to retire it instead"
+		responseBodyErr := DeleteLoanProductResponse{
+			ErrorMessage: &errMsg,
+			ProductCode:  &productCode,
+			Deleted:      false,
+		}
+
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	deletedProductCode, err := h.ProductStore.DeleteProductByCode(r.Context(), productCode)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errMsg := "Product code not found"
+			responseBodyErr := DeleteLoanProductResponse{
+				ErrorMessage: &errMsg,
+				ProductCode:  &productCode,
+				Deleted:      false,
+			}
+
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(responseBodyErr)
+		} else {
+			errMsg := "Failed to delete loan product"
+			responseBodyErr := DeleteLoanProductResponse{
+				ErrorMessage: &errMsg,
+				ProductCode:  &productCode,
+				Deleted:      false,
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(responseBodyErr)
+		}
+		return
+	}
+
+	responseBody := DeleteLoanProductResponse{
+		ProductCode: &deletedProductCode,
+		Deleted:     true,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/alphaloan/vehicle/amortization"
+	"github.com/alphaloan/vehicle/datastore"
+	"github.com/alphaloan/vehicle/loanstatus"
+)
+
+type LoanScheduleHandler struct {
+	SubmissionStore  datastore.SubmissionRepository
+	InstallmentStore datastore.LoanInstallmentStore
+}
+
+func NewLoanScheduleHandler(
+	submissionStore datastore.SubmissionRepository,
+	installmentStore datastore.LoanInstallmentStore) *LoanScheduleHandler {
+	return &LoanScheduleHandler{
+		SubmissionStore:  submissionStore,
+		InstallmentStore: installmentStore,
+	}
+}
+
+func (h *LoanScheduleHandler) HandleGetLoanSchedule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	submissionID := r.PathValue("submission_id")
+	if !validateLoanSubmissionID(w, submissionID) {
+		return
+	}
+
+	loanSubmissionRow, err := h.SubmissionStore.GetLoanSubmissionByID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get loan submission"
+		responseBodyErr := GetLoanScheduleResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if loanSubmissionRow == nil {
+		errMsg := "Loan submission not found"
+		responseBodyErr := GetLoanScheduleResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	installmentRows, err := h.InstallmentStore.GetInstallmentsBySubmissionID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get loan schedule"
+		responseBodyErr := GetLoanScheduleResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if len(installmentRows) == 0 {
+		switch loanSubmissionRow.LoanStatus {
+		case loanstatus.New, loanstatus.UnderReview, loanstatus.Rejected, loanstatus.Cancelled:
+			errMsg := "Loan submission is not approved: " + loanSubmissionRow.LoanStatus
+			responseBodyErr := GetLoanScheduleResponse{
+				ErrorMessage: &errMsg,
+			}
+
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(responseBodyErr)
+		default:
+			errMsg := "Loan schedule not found"
+			responseBodyErr := GetLoanScheduleResponse{
+				ErrorMessage: &errMsg,
+			}
+
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(responseBodyErr)
+		}
+		return
+	}
+
+	schedule := LoanSchedule{
+		SubmissionID:       loanSubmissionRow.SubmissionID,
+		PrincipalAmount:    loanSubmissionRow.ProposedLoanAmount,
+		AnnualInterestRate: loanSubmissionRow.AnnualInterestRate,
+		InterestMethod:     loanSubmissionRow.InterestMethod,
+		TenureMonths:       loanSubmissionRow.ProposedLoanTenure,
+		Installments:       make([]LoanInstallment, 0, len(installmentRows)),
+	}
+
+	for _, row := range installmentRows {
+		schedule.TotalInterest = amortization.Round(schedule.TotalInterest + row.InterestAmount)
+		schedule.TotalPayable = amortization.Round(schedule.TotalPayable + row.TotalAmount)
+		schedule.Installments = append(schedule.Installments, LoanInstallment{
+			InstallmentNumber: row.InstallmentNumber,
+			DueDate:           row.DueDate,
+			PrincipalAmount:   row.PrincipalAmount,
+			InterestAmount:    row.InterestAmount,
+			TotalAmount:       row.TotalAmount,
+			RemainingBalance:  row.RemainingBalance,
+		})
+	}
+
+	responseBody := GetLoanScheduleResponse{
+		Data: &schedule,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/alphaloan/vehicle/amortization"
+	"github.com/alphaloan/vehicle/datastore"
+	"github.com/alphaloan/vehicle/underwriting"
+	"github.com/alphaloan/vehicle/valuation"
+)
+
+type LoanSimulationHandler struct {
+	ProductStore        datastore.LoanProductStore
+	ReferencePriceStore datastore.VehicleReferencePriceStore
+	Depreciation        valuation.DepreciationModel
+	Underwriting        *underwriting.Engine
+}
+
+func NewLoanSimulationHandler(
+	productStore datastore.LoanProductStore,
+	referencePriceStore datastore.VehicleReferencePriceStore,
+	depreciation valuation.DepreciationModel,
+	underwritingEngine *underwriting.Engine) *LoanSimulationHandler {
+	return &LoanSimulationHandler{
+		ProductStore:        productStore,
+		ReferencePriceStore: referencePriceStore,
+		Depreciation:        depreciation,
+		Underwriting:        underwritingEngine,
+	}
+}
+
+// HandleSimulateLoan quotes a proposed loan without persisting the customer or
+// the submission, so it can be called before HandleSubmitLoan.
+func (h *LoanSimulationHandler) HandleSimulateLoan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var request LoanSimulationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Bad request body", http.StatusBadRequest)
+		return
+	}
+
+	policy := h.Underwriting.Policy()
+
+	loanSubmissionRow := convertLoanProposal(&request.LoanSubmission, "", policy)
+
+	if request.ProductCode != nil && *request.ProductCode != "" {
+		loanProductRow, err := h.ProductStore.GetProductByCode(r.Context(), *request.ProductCode)
+
+		if err != nil {
+			errMsg := "Failed to get loan product"
+			responseBodyErr := LoanSimulationResponse{
+				ErrorMessage: &errMsg,
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(responseBodyErr)
+			return
+		}
+
+		if loanProductRow == nil {
+			errMsg := "Unknown product_code: " + *request.ProductCode
+			responseBodyErr := LoanSimulationResponse{
+				ErrorMessage: &errMsg,
+			}
+
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(responseBodyErr)
+			return
+		}
+
+		if violations := validateLoanProposalForProduct(loanProductRow, &request.LoanSubmission, time.Now()); len(violations) > 0 {
+			errMsg := "Loan proposal does not match product " + loanProductRow.ProductCode + ": " + strings.Join(violations, "; ")
+			responseBodyErr := LoanSimulationResponse{
+				ErrorMessage: &errMsg,
+			}
+
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(responseBodyErr)
+			return
+		}
+
+		applyLoanProduct(loanSubmissionRow, loanProductRow)
+	}
+
+	schedule, err := amortization.Generate(
+		float64(loanSubmissionRow.ProposedLoanAmount),
+		loanSubmissionRow.AnnualInterestRate,
+		loanSubmissionRow.ProposedLoanTenure,
+		loanSubmissionRow.InterestMethod,
+		time.Now(),
+	)
+
+	if err != nil {
+		errMsg := "Invalid loan proposal: " + err.Error()
+		responseBodyErr := LoanSimulationResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	collateral, err := estimateCollateral(r.Context(), h.ReferencePriceStore, h.Depreciation, loanSubmissionRow, time.Now())
+
+	if err != nil {
+		errMsg := "Failed to estimate collateral value"
+		responseBodyErr := LoanSimulationResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	loanCustomerRow := &datastore.LoanCustomerRow{}
+	if request.MonthlyIncome != nil {
+		loanCustomerRow.MonthlyIncome = *request.MonthlyIncome
+	}
+
+	evaluation := h.Underwriting.Evaluate(policy, convertUnderwritingApplication(loanCustomerRow, loanSubmissionRow))
+
+	responseBody := LoanSimulationResponse{
+		Data: &LoanSimulation{
+			ProductCode:        request.ProductCode,
+			PrincipalAmount:    loanSubmissionRow.ProposedLoanAmount,
+			AnnualInterestRate: loanSubmissionRow.AnnualInterestRate,
+			InterestMethod:     loanSubmissionRow.InterestMethod,
+			TenureMonths:       loanSubmissionRow.ProposedLoanTenure,
+			MonthlyInstallment: schedule.Installments[0].Payment,
+			TotalInterest:      schedule.TotalInterest,
+			TotalPayable:       schedule.TotalPayable,
+			Collateral:         convertCollateralValuation(collateral, loanSubmissionRow.LoanToValue.Float64),
+			Underwriting:       convertUnderwritingEvaluation("", evaluation),
+		},
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
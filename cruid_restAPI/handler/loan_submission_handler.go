@@ -1,23 +1,68 @@
 package handler
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/alphaloan/vehicle/datastore"
+	"github.com/alphaloan/vehicle/loanstatus"
 )
 
 type LoanSubmissionHandler struct {
-	SubmissionStore datastore.LoanSubmissionStore
+	UnitOfWork          datastore.UnitOfWork
+	SubmissionStore     datastore.SubmissionRepository
+	StatusHistoryStore  datastore.LoanStatusHistoryStore
+	InstallmentStore    datastore.LoanInstallmentStore
+	CollateralLienStore datastore.CollateralLienStore
+	ProductStore        datastore.LoanProductStore
+	DocumentStore       datastore.LoanDocumentStore
 }
 
 func NewLoanSubmissionHandler(
-	submissionStore datastore.LoanSubmissionStore) *LoanSubmissionHandler {
+	unitOfWork datastore.UnitOfWork,
+	submissionStore datastore.SubmissionRepository,
+	statusHistoryStore datastore.LoanStatusHistoryStore,
+	installmentStore datastore.LoanInstallmentStore,
+	collateralLienStore datastore.CollateralLienStore,
+	productStore datastore.LoanProductStore,
+	documentStore datastore.LoanDocumentStore) *LoanSubmissionHandler {
 	return &LoanSubmissionHandler{
-		SubmissionStore: submissionStore,
+		UnitOfWork:          unitOfWork,
+		SubmissionStore:     submissionStore,
+		StatusHistoryStore:  statusHistoryStore,
+		InstallmentStore:    installmentStore,
+		CollateralLienStore: collateralLienStore,
+		ProductStore:        productStore,
+		DocumentStore:       documentStore,
 	}
 }
 
+// loanTransitionError fails the transition transaction with the response to
+// send.
+type loanTransitionError struct {
+	status  int
+	message string
+	err     error
+}
+
+func (e *loanTransitionError) Error() string {
+	if e.err == nil {
+		return e.message
+	}
+	return e.message + ": " + e.err.Error()
+}
+
+func (e *loanTransitionError) Unwrap() error {
+	return e.err
+}
+
 func (h *LoanSubmissionHandler) HandleGetAllLoanSubmissions(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
@@ -26,7 +71,25 @@ func (h *LoanSubmissionHandler) HandleGetAllLoanSubmissions(w http.ResponseWrite
 
 	w.Header().Set("Content-Type", "application/json")
 
-	loanSubmissionRows, err := h.SubmissionStore.GetAllLoanSubmissions()
+	query, err := parseLoanSubmissionQuery(r.URL.Query())
+
+	if err != nil {
+		errMsg := "Invalid query parameters: " + err.Error()
+		responseBodyErr := GetAllLoanSubmissionsResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+
+		return
+	}
+
+	// One row more than the page tells whether another page follows.
+	pageLimit := query.Limit
+	query.Limit++
+
+	loanSubmissionRows, err := h.SubmissionStore.GetAllLoanSubmissions(r.Context(), query)
 
 	if err != nil {
 		errMsg := "Failed to get all loan submissions"
@@ -40,33 +103,125 @@ func (h *LoanSubmissionHandler) HandleGetAllLoanSubmissions(w http.ResponseWrite
 		return
 	}
 
+	var nextCursor *string
+	if len(loanSubmissionRows) > pageLimit {
+		loanSubmissionRows = loanSubmissionRows[:pageLimit]
+
+		last := loanSubmissionRows[pageLimit-1]
+		cursor := encodePageCursor(pageCursor{
+			Sort:  loanSubmissionSortParam(query),
+			Value: query.SortValue(last),
+			ID:    last.SubmissionID,
+		})
+		nextCursor = &cursor
+	}
+
 	loanSubmissions := make([]LoanSubmission, 0, len(loanSubmissionRows))
 	for _, row := range loanSubmissionRows {
-		loanSubmissions = append(loanSubmissions, LoanSubmission{
-			SubmissionID:            row.SubmissionID,
-			VehicleType:             row.VehicleType,
-			VehicleBrand:            row.VehicleBrand,
-			VehicleModel:            row.VehicleModel,
-			VehicleLicenseNumber:    row.VehicleLicenseNumber,
-			VehicleOdometer:         row.VehicleOdometer,
-			ManufacturingYear:       row.ManufacturingYear,
-			ProposedLoanAmount:      row.ProposedLoanAmount,
-			ProposedLoanTenureMonth: row.ProposedLoanTenure,
-			IsCommercialVehicle:     row.IsCommercialVehicle,
-		})
+		loanSubmissions = append(loanSubmissions, convertLoanSubmissionRow(row))
 	}
 
 	responseBody := GetAllLoanSubmissionsResponse{
-		Data: &loanSubmissions,
+		Data:       &loanSubmissions,
+		NextCursor: nextCursor,
 	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(responseBody)
 }
 
+// parseLoanSubmissionQuery reads the filters, sort and page of the submission
+// list.
+func parseLoanSubmissionQuery(values url.Values) (datastore.LoanSubmissionQuery, error) {
+	query, err := parseLoanSubmissionFilters(values)
+	if err != nil {
+		return query, err
+	}
+
+	if query.Limit, err = parsePageLimit(values.Get("limit")); err != nil {
+		return query, err
+	}
+
+	if param := values.Get("cursor"); param != "" {
+		cursor, err := decodePageCursor(param, loanSubmissionSortParam(query))
+		if err != nil {
+			return query, err
+		}
+		query.After = &datastore.LoanSubmissionCursor{
+			SortValue:    cursor.Value,
+			SubmissionID: cursor.ID,
+		}
+	}
+
+	return query, nil
+}
+
+// parseLoanSubmissionFilters reads the filters and sort of the submission list
+// and export. Timestamps are unix seconds; sort is a column, prefixed with "-"
+// for descending order, and defaults to newest first.
+func parseLoanSubmissionFilters(values url.Values) (datastore.LoanSubmissionQuery, error) {
+	query := datastore.LoanSubmissionQuery{
+		LoanStatus:  values.Get("loan_status"),
+		VehicleType: values.Get("vehicle_type"),
+		CustomerID:  values.Get("customer_id"),
+		SortBy:      datastore.SubmissionSortCreatedAt,
+		Descending:  true,
+	}
+
+	if query.LoanStatus != "" && !loanstatus.IsValid(query.LoanStatus) {
+		return query, fmt.Errorf("invalid loan_status: %s", query.LoanStatus)
+	}
+
+	if query.CustomerID != "" && !IsValidUUID(query.CustomerID) {
+		return query, fmt.Errorf("invalid customer_id: %s", query.CustomerID)
+	}
+
+	if param := values.Get("is_commercial_vehicle"); param != "" {
+		isCommercialVehicle, err := strconv.ParseBool(param)
+		if err != nil {
+			return query, fmt.Errorf("invalid is_commercial_vehicle, expected true or false: %s", param)
+		}
+		query.IsCommercialVehicle = &isCommercialVehicle
+	}
+
+	var err error
+	if query.MinLoanAmount, err = parseIntParam(values, "min_loan_amount"); err != nil {
+		return query, err
+	}
+
+	if query.MaxLoanAmount, err = parseIntParam(values, "max_loan_amount"); err != nil {
+		return query, err
+	}
+
+	if query.CreatedFrom, err = parseUnixParam(values, "created_from"); err != nil {
+		return query, err
+	}
+
+	if query.CreatedTo, err = parseUnixParam(values, "created_to"); err != nil {
+		return query, err
+	}
+
+	if sort := values.Get("sort"); sort != "" {
+		query.SortBy, query.Descending = strings.TrimPrefix(sort, "-"), strings.HasPrefix(sort, "-")
+		if !datastore.IsValidSubmissionSort(query.SortBy) {
+			return query, fmt.Errorf("invalid sort %q: must be %s or %s, optionally prefixed with -", sort, datastore.SubmissionSortCreatedAt, datastore.SubmissionSortLoanAmount)
+		}
+	}
+
+	return query, nil
+}
+
+func loanSubmissionSortParam(query datastore.LoanSubmissionQuery) string {
+	if query.Descending {
+		return "-" + query.SortBy
+	}
+
+	return query.SortBy
+}
+
 func validateLoanSubmissionID(w http.ResponseWriter, loanSubmissionID string) bool {
 	if loanSubmissionID == "" {
-		errMsg := "Missing submission_id query parameter"
+		errMsg := "Missing submission_id"
 		responseBodyErr := LoanSubmissionTrackStatusResponse{
 			ErrorMessage: &errMsg,
 		}
@@ -103,7 +258,7 @@ func (h *LoanSubmissionHandler) HandleTrackLoanSubmission(w http.ResponseWriter,
 		return
 	}
 
-	loanSubmissionRow, err := h.SubmissionStore.GetLoanSubmissionByID(loanSubmissionID)
+	loanSubmissionRow, err := h.SubmissionStore.GetLoanSubmissionByID(r.Context(), loanSubmissionID)
 
 	if err != nil {
 		errMsg := "Failed to get loan submission"
@@ -127,18 +282,7 @@ func (h *LoanSubmissionHandler) HandleTrackLoanSubmission(w http.ResponseWriter,
 		return
 	}
 
-	loanSubmission := LoanSubmission{
-		SubmissionID:            loanSubmissionRow.SubmissionID,
-		VehicleType:             loanSubmissionRow.VehicleType,
-		VehicleBrand:            loanSubmissionRow.VehicleBrand,
-		VehicleModel:            loanSubmissionRow.VehicleModel,
-		VehicleLicenseNumber:    loanSubmissionRow.VehicleLicenseNumber,
-		VehicleOdometer:         loanSubmissionRow.VehicleOdometer,
-		ManufacturingYear:       loanSubmissionRow.ManufacturingYear,
-		ProposedLoanAmount:      loanSubmissionRow.ProposedLoanAmount,
-		ProposedLoanTenureMonth: loanSubmissionRow.ProposedLoanTenure,
-		IsCommercialVehicle:     loanSubmissionRow.IsCommercialVehicle,
-	}
+	loanSubmission := convertLoanSubmissionRow(loanSubmissionRow)
 
 	responseBody := LoanSubmissionTrackStatusResponse{
 		Data: &loanSubmission,
@@ -147,3 +291,340 @@ func (h *LoanSubmissionHandler) HandleTrackLoanSubmission(w http.ResponseWriter,
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(responseBody)
 }
+
+func (h *LoanSubmissionHandler) HandleTransitionLoanStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	submissionID := r.PathValue("submission_id")
+	if !validateLoanSubmissionID(w, submissionID) {
+		return
+	}
+
+	var request LoanStatusTransitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Bad request body", http.StatusBadRequest)
+		return
+	}
+
+	if !loanstatus.IsValid(request.ToStatus) {
+		errMsg := "Unknown loan status: " + request.ToStatus
+		responseBodyErr := LoanStatusTransitionResponse{
+			ErrorMessage: &errMsg,
+			SubmissionID: &submissionID,
+			ToStatus:     &request.ToStatus,
+			Transitioned: false,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if request.Actor == "" {
+		errMsg := "Missing actor"
+		responseBodyErr := LoanStatusTransitionResponse{
+			ErrorMessage: &errMsg,
+			SubmissionID: &submissionID,
+			ToStatus:     &request.ToStatus,
+			Transitioned: false,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	loanSubmissionRow, err := h.SubmissionStore.GetLoanSubmissionByID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get loan submission"
+		responseBodyErr := LoanStatusTransitionResponse{
+			ErrorMessage: &errMsg,
+			SubmissionID: &submissionID,
+			Transitioned: false,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if loanSubmissionRow == nil {
+		errMsg := "Loan submission not found"
+		responseBodyErr := LoanStatusTransitionResponse{
+			ErrorMessage: &errMsg,
+			SubmissionID: &submissionID,
+			Transitioned: false,
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	fromStatus := loanSubmissionRow.LoanStatus
+
+	var transitionErr *loanstatus.TransitionError
+	if err := loanstatus.ValidateTransition(fromStatus, request.ToStatus); errors.As(err, &transitionErr) {
+		errMsg := transitionErr.Error()
+		responseBodyErr := LoanStatusTransitionResponse{
+			ErrorMessage:    &errMsg,
+			SubmissionID:    &submissionID,
+			FromStatus:      &fromStatus,
+			ToStatus:        &request.ToStatus,
+			AllowedStatuses: transitionErr.Allowed,
+			Transitioned:    false,
+		}
+
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if loanstatus.ClosedBySettlement(fromStatus, request.ToStatus) {
+		errMsg := "A disbursed loan can only be closed by settling it through /api/loan/submission/" + submissionID + "/settle"
+		responseBodyErr := LoanStatusTransitionResponse{
+			ErrorMessage: &errMsg,
+			SubmissionID: &submissionID,
+			FromStatus:   &fromStatus,
+			ToStatus:     &request.ToStatus,
+			Transitioned: false,
+		}
+
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if request.ToStatus == loanstatus.Approved {
+		checklist, err := loadDocumentChecklist(r.Context(), h.ProductStore, h.DocumentStore, loanSubmissionRow)
+
+		if err != nil {
+			errMsg := "Failed to get document checklist"
+			responseBodyErr := LoanStatusTransitionResponse{
+				ErrorMessage: &errMsg,
+				SubmissionID: &submissionID,
+				FromStatus:   &fromStatus,
+				ToStatus:     &request.ToStatus,
+				Transitioned: false,
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(responseBodyErr)
+			return
+		}
+
+		if !checklist.Complete() {
+			errMsg := "Required documents are missing or not verified: " + strings.Join(checklist.Missing, ", ")
+			responseBodyErr := LoanStatusTransitionResponse{
+				ErrorMessage:     &errMsg,
+				SubmissionID:     &submissionID,
+				FromStatus:       &fromStatus,
+				ToStatus:         &request.ToStatus,
+				MissingDocuments: checklist.Missing,
+				Transitioned:     false,
+			}
+
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(responseBodyErr)
+			return
+		}
+	}
+
+	updatedAt := time.Now().Unix()
+
+	var installmentRows []*datastore.LoanInstallmentRow
+
+	if loanstatus.GeneratesSchedule(request.ToStatus) {
+		installmentRows, err = convertLoanSchedule(loanSubmissionRow, time.Unix(updatedAt, 0))
+
+		if err != nil {
+			errMsg := "Failed to generate loan schedule"
+			responseBodyErr := LoanStatusTransitionResponse{
+				ErrorMessage: &errMsg,
+				SubmissionID: &submissionID,
+				FromStatus:   &fromStatus,
+				ToStatus:     &request.ToStatus,
+				Transitioned: false,
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(responseBodyErr)
+			return
+		}
+	}
+
+	var updatedSubmissionID string
+
+	// The status, its history entry, the schedule of an approved or disbursed
+	// loan and the release of the collateral of a finished one are written in
+	// one transaction, so a failed transition changes nothing and can be
+	// retried.
+	// The status update only matches while the submission is still in
+	// fromStatus, so of two racing requests the second rolls back.
+	err = h.UnitOfWork.Do(r.Context(), func(tx datastore.Tx) error {
+		updatedSubmissionID, err = h.SubmissionStore.WithTx(tx).UpdateLoanStatusByID(r.Context(), submissionID, fromStatus, request.ToStatus, updatedAt)
+
+		if err == sql.ErrNoRows {
+			return &loanTransitionError{http.StatusConflict, "Loan status was changed by another request, please retry", err}
+		}
+
+		if err != nil {
+			return &loanTransitionError{http.StatusInternalServerError, "Failed to update loan status", err}
+		}
+
+		// The checklist above was read before the submission row was locked,
+		// so it is read again now that a document delete has to wait for
+		// this transaction or has already committed.
+		if request.ToStatus == loanstatus.Approved {
+			checklist, err := loadDocumentChecklist(r.Context(), h.ProductStore, *h.DocumentStore.WithTx(tx), loanSubmissionRow)
+
+			if err != nil {
+				return &loanTransitionError{http.StatusInternalServerError, "Failed to get document checklist", err}
+			}
+
+			if !checklist.Complete() {
+				return &loanTransitionError{http.StatusConflict, "Required documents are missing or not verified: " + strings.Join(checklist.Missing, ", "), nil}
+			}
+		}
+
+		statusHistoryRow := convertLoanStatusHistory(submissionID, fromStatus, request.ToStatus, request.Actor, request.Reason, updatedAt)
+
+		if _, err := h.StatusHistoryStore.WithTx(tx).AppendStatusHistory(r.Context(), statusHistoryRow); err != nil {
+			return &loanTransitionError{http.StatusInternalServerError, "Failed to record loan status history", err}
+		}
+
+		if loanstatus.GeneratesSchedule(request.ToStatus) {
+			if err := h.InstallmentStore.WithTx(tx).ReplaceInstallments(r.Context(), submissionID, installmentRows); err != nil {
+				return &loanTransitionError{http.StatusInternalServerError, "Failed to generate loan schedule", err}
+			}
+		}
+
+		if loanstatus.ReleasesCollateral(request.ToStatus) {
+			if err := h.CollateralLienStore.WithTx(tx).ReleaseCollateral(r.Context(), submissionID, updatedAt, request.ToStatus); err != nil {
+				return &loanTransitionError{http.StatusInternalServerError, "Failed to release collateral", err}
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		errMsg, statusCode := "Failed to update loan status", http.StatusInternalServerError
+
+		var failedErr *loanTransitionError
+		if errors.As(err, &failedErr) {
+			errMsg, statusCode = failedErr.message, failedErr.status
+		}
+
+		responseBodyErr := LoanStatusTransitionResponse{
+			ErrorMessage: &errMsg,
+			SubmissionID: &submissionID,
+			FromStatus:   &fromStatus,
+			ToStatus:     &request.ToStatus,
+			Transitioned: false,
+		}
+
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	responseBody := LoanStatusTransitionResponse{
+		SubmissionID:    &updatedSubmissionID,
+		FromStatus:      &fromStatus,
+		ToStatus:        &request.ToStatus,
+		AllowedStatuses: loanstatus.AllowedTransitions(request.ToStatus),
+		UpdatedAt:       &updatedAt,
+		Transitioned:    true,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+func (h *LoanSubmissionHandler) HandleGetLoanSubmissionTimeline(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	submissionID := r.PathValue("submission_id")
+	if !validateLoanSubmissionID(w, submissionID) {
+		return
+	}
+
+	loanSubmissionRow, err := h.SubmissionStore.GetLoanSubmissionByID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get loan submission"
+		responseBodyErr := LoanSubmissionTimelineResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if loanSubmissionRow == nil {
+		errMsg := "Loan submission not found"
+		responseBodyErr := LoanSubmissionTimelineResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	statusHistoryRows, err := h.StatusHistoryStore.GetStatusHistoryBySubmissionID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get loan status history"
+		responseBodyErr := LoanSubmissionTimelineResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	history := make([]LoanStatusHistory, 0, len(statusHistoryRows))
+	for _, row := range statusHistoryRows {
+		statusHistory := LoanStatusHistory{
+			ToStatus:  row.ToStatus,
+			Actor:     row.Actor,
+			ChangedAt: row.ChangedAt,
+		}
+
+		if row.FromStatus.Valid {
+			statusHistory.FromStatus = &row.FromStatus.String
+		}
+
+		if row.Reason.Valid {
+			statusHistory.Reason = &row.Reason.String
+		}
+
+		history = append(history, statusHistory)
+	}
+
+	responseBody := LoanSubmissionTimelineResponse{
+		Data: &LoanSubmissionTimeline{
+			SubmissionID:  loanSubmissionRow.SubmissionID,
+			CurrentStatus: loanSubmissionRow.LoanStatus,
+			History:       history,
+		},
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
@@ -1,24 +1,110 @@
 package handler
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/alphaloan/vehicle/catalogue"
 	"github.com/alphaloan/vehicle/datastore"
+	"github.com/alphaloan/vehicle/licenseplate"
+	"github.com/alphaloan/vehicle/loanparty"
+	"github.com/alphaloan/vehicle/underwriting"
+	"github.com/alphaloan/vehicle/valuation"
 )
 
+const loanSubmitActor = "applicant"
+
 type LoanSubmitHandler struct {
-	CustomerStore   datastore.LoanCustomerStore
-	SubmissionStore datastore.LoanSubmissionStore
+	UnitOfWork          datastore.UnitOfWork
+	CustomerStore       datastore.CustomerRepository
+	SubmissionStore     datastore.SubmissionRepository
+	PartyStore          datastore.LoanSubmissionPartyStore
+	StatusHistoryStore  datastore.LoanStatusHistoryStore
+	UnderwritingStore   datastore.LoanUnderwritingStore
+	ProductStore        datastore.LoanProductStore
+	CatalogueStore      datastore.VehicleCatalogueStore
+	CollateralLienStore datastore.CollateralLienStore
+	ReferencePriceStore datastore.VehicleReferencePriceStore
+	Depreciation        valuation.DepreciationModel
+	PlateFormats        *licenseplate.Registry
+	Underwriting        *underwriting.Engine
 }
 
 func NewLoanSubmitHandler(
-	customerStore datastore.LoanCustomerStore,
-	submissionStore datastore.LoanSubmissionStore) *LoanSubmitHandler {
+	unitOfWork datastore.UnitOfWork,
+	customerStore datastore.CustomerRepository,
+	submissionStore datastore.SubmissionRepository,
+	partyStore datastore.LoanSubmissionPartyStore,
+	statusHistoryStore datastore.LoanStatusHistoryStore,
+	underwritingStore datastore.LoanUnderwritingStore,
+	productStore datastore.LoanProductStore,
+	catalogueStore datastore.VehicleCatalogueStore,
+	collateralLienStore datastore.CollateralLienStore,
+	referencePriceStore datastore.VehicleReferencePriceStore,
+	depreciation valuation.DepreciationModel,
+	plateFormats *licenseplate.Registry,
+	underwritingEngine *underwriting.Engine) *LoanSubmitHandler {
 	return &LoanSubmitHandler{
-		CustomerStore:   customerStore,
-		SubmissionStore: submissionStore,
+		UnitOfWork:          unitOfWork,
+		CustomerStore:       customerStore,
+		SubmissionStore:     submissionStore,
+		PartyStore:          partyStore,
+		StatusHistoryStore:  statusHistoryStore,
+		UnderwritingStore:   underwritingStore,
+		ProductStore:        productStore,
+		CatalogueStore:      catalogueStore,
+		CollateralLienStore: collateralLienStore,
+		ReferencePriceStore: referencePriceStore,
+		Depreciation:        depreciation,
+		PlateFormats:        plateFormats,
+		Underwriting:        underwritingEngine,
+	}
+}
+
+// loanSubmitError fails the submit transaction with the response to send.
+type loanSubmitError struct {
+	status  int
+	message string
+	err     error
+}
+
+func (e *loanSubmitError) Error() string {
+	if e.err == nil {
+		return e.message
+	}
+	return e.message + ": " + e.err.Error()
+}
+
+func (e *loanSubmitError) Unwrap() error {
+	return e.err
+}
+
+// validateAdditionalParties checks that every additional party is a
+// co-applicant or guarantor and that nobody appears twice on the submission.
+func validateAdditionalParties(primary *LoanCustomer, parties []LoanSubmissionParty) error {
+	seenIDCardNumbers := map[string]bool{primary.IDCardNumber: true}
+
+	for _, party := range parties {
+		if party.PartyRole != loanparty.CoApplicant && party.PartyRole != loanparty.Guarantor {
+			return fmt.Errorf("invalid party_role %q: must be %s or %s", party.PartyRole, loanparty.CoApplicant, loanparty.Guarantor)
+		}
+
+		if party.Customer.IDCardNumber == "" {
+			return errors.New("every additional party needs an id_card_number")
+		}
+
+		if seenIDCardNumbers[party.Customer.IDCardNumber] {
+			return fmt.Errorf("id_card_number %s appears more than once on the submission", party.Customer.IDCardNumber)
+		}
+		seenIDCardNumbers[party.Customer.IDCardNumber] = true
 	}
+
+	return nil
 }
 
 func (h *LoanSubmitHandler) HandleSubmitLoan(w http.ResponseWriter, r *http.Request) {
@@ -33,27 +119,217 @@ func (h *LoanSubmitHandler) HandleSubmitLoan(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if err := validateAdditionalParties(&request.Customer, request.AdditionalParties); err != nil {
+		http.Error(w, "Invalid additional_parties: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var regionCode string
+	if request.ProposedLoan.VehicleRegionCode != nil {
+		regionCode = strings.ToUpper(strings.TrimSpace(*request.ProposedLoan.VehicleRegionCode))
+	}
+
+	licenseNumber, regionCode, err := h.PlateFormats.Validate(regionCode, request.ProposedLoan.VehicleLicenseNumber)
+
+	if err != nil {
+		var unknownRegion *licenseplate.UnknownRegionError
+		if errors.As(err, &unknownRegion) {
+			http.Error(w, "Invalid vehicle_region_code: "+unknownRegion.RegionCode+", expected one of "+strings.Join(h.PlateFormats.RegionCodes(), ", "), http.StatusBadRequest)
+			return
+		}
+
+		http.Error(w, "Invalid vehicle_license_number: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	request.ProposedLoan.VehicleLicenseNumber = licenseNumber
+	request.ProposedLoan.VehicleRegionCode = &regionCode
+
+	if err := matchVehicleCatalogue(r.Context(), h.CatalogueStore, &request.ProposedLoan); err != nil {
+		var mismatch *catalogue.MismatchError
+		if errors.As(err, &mismatch) {
+			http.Error(w, "Vehicle is not in the catalogue: "+mismatch.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		http.Error(w, "Failed to get vehicle catalogue", http.StatusInternalServerError)
+		return
+	}
+
+	if request.ProposedLoan.ProductCode == nil || *request.ProposedLoan.ProductCode == "" {
+		http.Error(w, "Missing product_code", http.StatusBadRequest)
+		return
+	}
+
+	loanProductRow, err := h.ProductStore.GetProductByCode(r.Context(), *request.ProposedLoan.ProductCode)
+
+	if err != nil {
+		http.Error(w, "Failed to get loan product", http.StatusInternalServerError)
+		return
+	}
+
+	if loanProductRow == nil {
+		http.Error(w, "Unknown product_code: "+*request.ProposedLoan.ProductCode, http.StatusBadRequest)
+		return
+	}
+
+	if violations := validateLoanProposalForProduct(loanProductRow, &request.ProposedLoan, time.Now()); len(violations) > 0 {
+		http.Error(w, "Loan proposal does not match product "+loanProductRow.ProductCode+": "+strings.Join(violations, "; "), http.StatusUnprocessableEntity)
+		return
+	}
+
+	activeLienRow, err := h.CollateralLienStore.GetActiveLienByLicenseNumber(r.Context(), request.ProposedLoan.VehicleLicenseNumber)
+
+	if err != nil {
+		http.Error(w, "Failed to check collateral registry", http.StatusInternalServerError)
+		return
+	}
+
+	if activeLienRow != nil {
+		http.Error(w, "Vehicle "+activeLienRow.VehicleLicenseNumber+" is already pledged to loan submission "+activeLienRow.SubmissionID, http.StatusConflict)
+		return
+	}
+
 	loanCustomerRow := convertLoanCustomer(&request.Customer)
 
-	upsertCustomerID, err := h.CustomerStore.UpsertCustomer(loanCustomerRow)
+	now := time.Now()
+
+	partyCustomerRows := make([]*datastore.LoanCustomerRow, 0, len(request.AdditionalParties))
+	coApplicantIncome := 0.0
+
+	for _, party := range request.AdditionalParties {
+		partyCustomerRow := convertLoanCustomer(&party.Customer)
+		partyCustomerRows = append(partyCustomerRows, partyCustomerRow)
+
+		if party.PartyRole == loanparty.CoApplicant {
+			coApplicantIncome += partyCustomerRow.MonthlyIncome
+		}
+	}
+
+	// One policy serves both the defaults of the submission and its
+	// evaluation, so the recorded policy version is the one they came from.
+	policy := h.Underwriting.Policy()
+
+	loanSubmissionRow := convertLoanProposal(&request.ProposedLoan, "", policy)
+	applyLoanProduct(loanSubmissionRow, loanProductRow)
+
+	collateral, err := estimateCollateral(r.Context(), h.ReferencePriceStore, h.Depreciation, loanSubmissionRow, now)
 
 	if err != nil {
-		http.Error(w, "Failed to upsert customer", http.StatusInternalServerError)
+		http.Error(w, "Failed to estimate collateral value", http.StatusInternalServerError)
 		return
 	}
 
-	loanSubmissionRow := convertLoanProposal(&request.ProposedLoan, upsertCustomerID)
+	application := convertUnderwritingApplication(loanCustomerRow, loanSubmissionRow)
+	application.CoApplicantIncome = coApplicantIncome
+
+	evaluation := h.Underwriting.Evaluate(policy, application)
+
+	loanSubmissionRow.PolicyVersion = sql.NullInt64{
+		Int64: int64(evaluation.PolicyVersion),
+		Valid: true,
+	}
+	loanSubmissionRow.UnderwritingRecommendation = sql.NullString{
+		String: evaluation.Recommendation,
+		Valid:  true,
+	}
+
+	var upsertCustomerID, upsertSubmissionID string
+
+	// Everything below is written in one transaction, so a failure part way
+	// leaves neither a half-updated customer nor a submission without its
+	// lien, parties, underwriting results or history.
+	err = h.UnitOfWork.Do(r.Context(), func(tx datastore.Tx) error {
+		customerStore := h.CustomerStore.WithTx(tx)
+
+		upsertCustomerID, err = customerStore.UpsertCustomer(r.Context(), loanCustomerRow)
+
+		if err != nil {
+			return &loanSubmitError{http.StatusInternalServerError, "Failed to upsert customer", err}
+		}
+
+		partyRows := []*datastore.LoanSubmissionPartyRow{{
+			CustomerID: upsertCustomerID,
+			PartyRole:  loanparty.Primary,
+			CreatedAt:  now.Unix(),
+		}}
+
+		for i, party := range request.AdditionalParties {
+			partyCustomerID, err := customerStore.UpsertCustomer(r.Context(), partyCustomerRows[i])
+
+			if err != nil {
+				return &loanSubmitError{http.StatusInternalServerError, "Failed to upsert " + strings.ToLower(party.PartyRole) + " customer", err}
+			}
+
+			partyRows = append(partyRows, &datastore.LoanSubmissionPartyRow{
+				CustomerID: partyCustomerID,
+				PartyRole:  party.PartyRole,
+				CreatedAt:  now.Unix(),
+			})
+		}
+
+		loanSubmissionRow.CustomerID = upsertCustomerID
+
+		upsertSubmissionID, err = h.SubmissionStore.WithTx(tx).UpsertSubmission(r.Context(), loanSubmissionRow)
+
+		if err != nil {
+			return &loanSubmitError{http.StatusInternalServerError, "Failed to upsert submission", err}
+		}
 
-	upsertSubmissionID, err := h.SubmissionStore.UpsertSubmission(loanSubmissionRow)
+		pledged, err := h.CollateralLienStore.WithTx(tx).PledgeCollateral(r.Context(), &datastore.CollateralLienRow{
+			SubmissionID:         upsertSubmissionID,
+			VehicleLicenseNumber: loanSubmissionRow.VehicleLicenseNumber,
+			PledgedAt:            loanSubmissionRow.CreatedAt,
+		})
+
+		if err != nil {
+			return &loanSubmitError{http.StatusInternalServerError, "Failed to pledge collateral", err}
+		}
+
+		if !pledged {
+			return &loanSubmitError{http.StatusConflict, "Vehicle " + loanSubmissionRow.VehicleLicenseNumber + " is already pledged to another loan submission", nil}
+		}
+
+		for _, partyRow := range partyRows {
+			partyRow.SubmissionID = upsertSubmissionID
+		}
+
+		if err := h.PartyStore.WithTx(tx).AddParties(r.Context(), partyRows); err != nil {
+			return &loanSubmitError{http.StatusInternalServerError, "Failed to record loan submission parties", err}
+		}
+
+		if err := h.UnderwritingStore.WithTx(tx).ReplaceUnderwritingResults(r.Context(), upsertSubmissionID, convertUnderwritingResultRows(upsertSubmissionID, evaluation)); err != nil {
+			return &loanSubmitError{http.StatusInternalServerError, "Failed to record underwriting results", err}
+		}
+
+		statusHistoryRow := convertLoanStatusHistory(upsertSubmissionID, "", loanSubmissionRow.LoanStatus, loanSubmitActor, nil, loanSubmissionRow.CreatedAt)
+
+		if _, err := h.StatusHistoryStore.WithTx(tx).AppendStatusHistory(r.Context(), statusHistoryRow); err != nil {
+			return &loanSubmitError{http.StatusInternalServerError, "Failed to record loan status history", err}
+		}
+
+		return nil
+	})
 
 	if err != nil {
-		http.Error(w, "Failed to upsert submission", http.StatusInternalServerError)
+		var submitErr *loanSubmitError
+		if errors.As(err, &submitErr) {
+			http.Error(w, submitErr.message, submitErr.status)
+			return
+		}
+
+		http.Error(w, "Failed to submit loan", http.StatusInternalServerError)
 		return
 	}
 
+	householdIncome := application.HouseholdIncome()
+
 	response := LoanSubmitResponse{
-		CustomerID:   &upsertCustomerID,
-		SubmissionID: &upsertSubmissionID,
+		CustomerID:      &upsertCustomerID,
+		SubmissionID:    &upsertSubmissionID,
+		HouseholdIncome: &householdIncome,
+		Collateral:      convertCollateralValuation(collateral, loanSubmissionRow.LoanToValue.Float64),
+		Underwriting:    convertUnderwritingEvaluation(upsertSubmissionID, evaluation),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -2,9 +2,20 @@ package handler
 
 import (
 	"database/sql"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/alphaloan/vehicle/amortization"
 	"github.com/alphaloan/vehicle/datastore"
+	"github.com/alphaloan/vehicle/documents"
+	"github.com/alphaloan/vehicle/ledger"
+	"github.com/alphaloan/vehicle/loanparty"
+	"github.com/alphaloan/vehicle/loanstatus"
+	"github.com/alphaloan/vehicle/underwriting"
+	"github.com/alphaloan/vehicle/valuation"
 	"github.com/google/uuid"
 )
 
@@ -21,26 +32,47 @@ type LoanCustomer struct {
 }
 
 type LoanSubmission struct {
-	SubmissionID            string `json:"submission_id"`
-	VehicleType             string `json:"vehicle_type"`
-	VehicleBrand            string `json:"vehicle_brand"`
-	VehicleModel            string `json:"vehicle_model"`
-	VehicleLicenseNumber    string `json:"vehicle_license_number"`
-	VehicleOdometer         int    `json:"vehicle_odometer"`
-	ManufacturingYear       int    `json:"manufacturing_year"`
-	ProposedLoanAmount      int    `json:"proposed_loan_amount"`
-	ProposedLoanTenureMonth int    `json:"proposed_loan_tenure_month"`
-	IsCommercialVehicle     bool   `json:"is_commercial_vehicle"`
+	SubmissionID               string   `json:"submission_id"`
+	VehicleType                string   `json:"vehicle_type"`
+	VehicleBrand               string   `json:"vehicle_brand"`
+	VehicleModel               string   `json:"vehicle_model"`
+	VehicleLicenseNumber       string   `json:"vehicle_license_number"`
+	VehicleRegionCode          *string  `json:"vehicle_region_code"`
+	VehicleOdometer            int      `json:"vehicle_odometer"`
+	ManufacturingYear          int      `json:"manufacturing_year"`
+	ProposedLoanAmount         int      `json:"proposed_loan_amount"`
+	ProposedLoanTenureMonth    int      `json:"proposed_loan_tenure_month"`
+	AnnualInterestRate         *float64 `json:"annual_interest_rate"`
+	InterestMethod             *string  `json:"interest_method"`
+	ProductCode                *string  `json:"product_code"`
+	CollateralValue            *float64 `json:"collateral_value"`
+	LoanToValue                *float64 `json:"loan_to_value"`
+	IsCommercialVehicle        bool     `json:"is_commercial_vehicle"`
+	LoanStatus                 string   `json:"loan_status"`
+	PolicyVersion              *int64   `json:"policy_version"`
+	UnderwritingRecommendation *string  `json:"underwriting_recommendation"`
+
+	Parties         *[]LoanSubmissionParty `json:"parties,omitempty"`
+	HouseholdIncome *float64               `json:"household_income,omitempty"`
+}
+
+type LoanSubmissionParty struct {
+	PartyRole string       `json:"party_role"`
+	Customer  LoanCustomer `json:"customer"`
 }
 
 type LoanSubmitRequest struct {
-	Customer     LoanCustomer   `json:"customer"`
-	ProposedLoan LoanSubmission `json:"proposed_loan"`
+	Customer          LoanCustomer          `json:"customer"`
+	AdditionalParties []LoanSubmissionParty `json:"additional_parties"`
+	ProposedLoan      LoanSubmission        `json:"proposed_loan"`
 }
 
 type LoanSubmitResponse struct {
-	CustomerID   *string `json:"customer_id"`
-	SubmissionID *string `json:"submission_id"`
+	CustomerID      *string                 `json:"customer_id"`
+	SubmissionID    *string                 `json:"submission_id"`
+	HouseholdIncome *float64                `json:"household_income"`
+	Collateral      *CollateralValuation    `json:"collateral"`
+	Underwriting    *UnderwritingEvaluation `json:"underwriting"`
 }
 
 func convertLoanCustomer(loanCustomer *LoanCustomer) *datastore.LoanCustomerRow {
@@ -70,24 +102,37 @@ func convertLoanCustomer(loanCustomer *LoanCustomer) *datastore.LoanCustomerRow
 	}
 }
 
-func convertLoanProposal(loanProposal *LoanSubmission, customerID string) *datastore.LoanSubmissionRow {
+func convertLoanProposal(loanProposal *LoanSubmission, customerID string, policy underwriting.Policy) *datastore.LoanSubmissionRow {
 	if loanProposal == nil {
 		return nil
 	}
 
 	now := time.Now().Unix()
 
+	annualInterestRate := policy.DefaultAnnualInterestRate
+	if loanProposal.AnnualInterestRate != nil {
+		annualInterestRate = *loanProposal.AnnualInterestRate
+	}
+
+	interestMethod := policy.DefaultInterestMethod
+	if loanProposal.InterestMethod != nil {
+		interestMethod = *loanProposal.InterestMethod
+	}
+
 	return &datastore.LoanSubmissionRow{
 		SubmissionID:         uuid.New().String(),
 		VehicleType:          loanProposal.VehicleType,
 		VehicleBrand:         loanProposal.VehicleBrand,
 		VehicleModel:         loanProposal.VehicleModel,
 		VehicleLicenseNumber: loanProposal.VehicleLicenseNumber,
+		VehicleRegionCode:    convertNullString(loanProposal.VehicleRegionCode),
 		VehicleOdometer:      loanProposal.VehicleOdometer,
 		ManufacturingYear:    loanProposal.ManufacturingYear,
 		ProposedLoanAmount:   loanProposal.ProposedLoanAmount,
 		ProposedLoanTenure:   loanProposal.ProposedLoanTenureMonth,
-		LoanStatus:           "NEW",
+		AnnualInterestRate:   annualInterestRate,
+		InterestMethod:       interestMethod,
+		LoanStatus:           loanstatus.New,
 		IsCommercialVehicle:  loanProposal.IsCommercialVehicle,
 		CreatedAt:            now,
 		UpdatedAt:            now,
@@ -95,9 +140,56 @@ func convertLoanProposal(loanProposal *LoanSubmission, customerID string) *datas
 	}
 }
 
+// applyLoanProduct prices the submission with the product's rate and interest
+// method, replacing the policy defaults set by convertLoanProposal.
+func applyLoanProduct(loanSubmissionRow *datastore.LoanSubmissionRow, product *datastore.LoanProductRow) {
+	loanSubmissionRow.AnnualInterestRate = product.AnnualInterestRate
+	loanSubmissionRow.InterestMethod = product.InterestMethod
+	loanSubmissionRow.ProductCode = sql.NullString{
+		String: product.ProductCode,
+		Valid:  true,
+	}
+}
+
+func convertNullInt64(value sql.NullInt64) *int64 {
+	if !value.Valid {
+		return nil
+	}
+
+	return &value.Int64
+}
+
+func convertNullFloat64(value sql.NullFloat64) *float64 {
+	if !value.Valid {
+		return nil
+	}
+
+	return &value.Float64
+}
+
+func convertNullString(value *string) sql.NullString {
+	if value == nil || *value == "" {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{
+		String: *value,
+		Valid:  true,
+	}
+}
+
+func convertNullStringPointer(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+
+	return &value.String
+}
+
 type GetAllLoanSubmissionsResponse struct {
 	ErrorMessage *string           `json:"error_message"`
 	Data         *[]LoanSubmission `json:"data"`
+	NextCursor   *string           `json:"next_cursor"`
 }
 
 type LoanSubmissionTrackStatusResponse struct {
@@ -108,6 +200,26 @@ type LoanSubmissionTrackStatusResponse struct {
 type GetAllLoanCustomersResponse struct {
 	ErrorMessage *string         `json:"error_message"`
 	Data         *[]LoanCustomer `json:"data"`
+	NextCursor   *string         `json:"next_cursor"`
+}
+
+// GetAllLoanCustomerFieldsResponse carries a customer list projected to the
+// fields the client asked for.
+type GetAllLoanCustomerFieldsResponse struct {
+	ErrorMessage *string           `json:"error_message"`
+	Data         *[]map[string]any `json:"data"`
+	NextCursor   *string           `json:"next_cursor"`
+}
+
+type LoanCustomerSearchResult struct {
+	LoanCustomer
+	Score         float64  `json:"score"`
+	MatchedFields []string `json:"matched_fields"`
+}
+
+type SearchLoanCustomersResponse struct {
+	ErrorMessage *string                     `json:"error_message"`
+	Data         *[]LoanCustomerSearchResult `json:"data"`
 }
 
 type LoanCustomerWithAllSubmissions struct {
@@ -131,3 +243,825 @@ type DeleteCustomerResponse struct {
 	CustomerID   *string `json:"customer_id"`
 	Deleted      bool    `json:"deleted"`
 }
+
+type LoanStatusTransitionRequest struct {
+	ToStatus string  `json:"to_status"`
+	Actor    string  `json:"actor"`
+	Reason   *string `json:"reason"`
+}
+
+type LoanStatusTransitionResponse struct {
+	ErrorMessage     *string  `json:"error_message"`
+	SubmissionID     *string  `json:"submission_id"`
+	FromStatus       *string  `json:"from_status"`
+	ToStatus         *string  `json:"to_status"`
+	AllowedStatuses  []string `json:"allowed_statuses"`
+	MissingDocuments []string `json:"missing_documents,omitempty"`
+	UpdatedAt        *int64   `json:"updated_at"`
+	Transitioned     bool     `json:"transitioned"`
+}
+
+type LoanStatusHistory struct {
+	FromStatus *string `json:"from_status"`
+	ToStatus   string  `json:"to_status"`
+	Actor      string  `json:"actor"`
+	Reason     *string `json:"reason"`
+	ChangedAt  int64   `json:"changed_at"`
+}
+
+type LoanSubmissionTimeline struct {
+	SubmissionID  string              `json:"submission_id"`
+	CurrentStatus string              `json:"current_status"`
+	History       []LoanStatusHistory `json:"history"`
+}
+
+type LoanSubmissionTimelineResponse struct {
+	ErrorMessage *string                 `json:"error_message"`
+	Data         *LoanSubmissionTimeline `json:"data"`
+}
+
+func convertLoanStatusHistory(submissionID string, fromStatus string, toStatus string, actor string, reason *string, changedAt int64) *datastore.LoanStatusHistoryRow {
+	parsedReason := ""
+
+	if reason != nil {
+		parsedReason = *reason
+	}
+
+	return &datastore.LoanStatusHistoryRow{
+		SubmissionID: submissionID,
+		FromStatus: sql.NullString{
+			String: fromStatus,
+			Valid:  fromStatus != "",
+		},
+		ToStatus: toStatus,
+		Actor:    actor,
+		Reason: sql.NullString{
+			String: parsedReason,
+			Valid:  parsedReason != "",
+		},
+		ChangedAt: changedAt,
+	}
+}
+
+type UnderwritingRuleResult struct {
+	Rule    string `json:"rule"`
+	Outcome string `json:"outcome"`
+	Reason  string `json:"reason"`
+}
+
+type UnderwritingEvaluation struct {
+	SubmissionID   string                   `json:"submission_id,omitempty"`
+	PolicyVersion  int                      `json:"policy_version"`
+	Recommendation string                   `json:"recommendation"`
+	Rules          []UnderwritingRuleResult `json:"rules"`
+}
+
+type EvaluateLoanSubmissionResponse struct {
+	ErrorMessage *string                 `json:"error_message"`
+	Data         *UnderwritingEvaluation `json:"data"`
+}
+
+func convertUnderwritingApplication(loanCustomer *datastore.LoanCustomerRow, loanSubmission *datastore.LoanSubmissionRow) underwriting.Application {
+	return underwriting.Application{
+		MonthlyIncome:       loanCustomer.MonthlyIncome,
+		ProposedLoanAmount:  loanSubmission.ProposedLoanAmount,
+		ProposedLoanTenure:  loanSubmission.ProposedLoanTenure,
+		AnnualInterestRate:  loanSubmission.AnnualInterestRate,
+		InterestMethod:      loanSubmission.InterestMethod,
+		ManufacturingYear:   loanSubmission.ManufacturingYear,
+		VehicleOdometer:     loanSubmission.VehicleOdometer,
+		VehicleType:         loanSubmission.VehicleType,
+		VehicleBrand:        loanSubmission.VehicleBrand,
+		IsCommercialVehicle: loanSubmission.IsCommercialVehicle,
+		CollateralValue:     loanSubmission.CollateralValue.Float64,
+		LoanToValue:         loanSubmission.LoanToValue.Float64,
+	}
+}
+
+func convertUnderwritingEvaluation(submissionID string, evaluation underwriting.Evaluation) *UnderwritingEvaluation {
+	rules := make([]UnderwritingRuleResult, 0, len(evaluation.Results))
+	for _, result := range evaluation.Results {
+		rules = append(rules, UnderwritingRuleResult{
+			Rule:    result.Rule,
+			Outcome: result.Outcome,
+			Reason:  result.Reason,
+		})
+	}
+
+	return &UnderwritingEvaluation{
+		SubmissionID:   submissionID,
+		PolicyVersion:  evaluation.PolicyVersion,
+		Recommendation: evaluation.Recommendation,
+		Rules:          rules,
+	}
+}
+
+func convertUnderwritingResultRows(submissionID string, evaluation underwriting.Evaluation) []*datastore.LoanUnderwritingResultRow {
+	resultRows := make([]*datastore.LoanUnderwritingResultRow, 0, len(evaluation.Results))
+	for _, result := range evaluation.Results {
+		resultRows = append(resultRows, &datastore.LoanUnderwritingResultRow{
+			SubmissionID: submissionID,
+			RuleName:     result.Rule,
+			Outcome:      result.Outcome,
+			Reason:       result.Reason,
+		})
+	}
+
+	return resultRows
+}
+
+// convertStoredUnderwritingEvaluation rebuilds the last evaluation of a
+// submission from its recommendation and the rule results stored with it.
+func convertStoredUnderwritingEvaluation(submission *datastore.LoanSubmissionRow, resultRows []*datastore.LoanUnderwritingResultRow) *UnderwritingEvaluation {
+	rules := make([]UnderwritingRuleResult, 0, len(resultRows))
+	for _, resultRow := range resultRows {
+		rules = append(rules, UnderwritingRuleResult{
+			Rule:    resultRow.RuleName,
+			Outcome: resultRow.Outcome,
+			Reason:  resultRow.Reason,
+		})
+	}
+
+	return &UnderwritingEvaluation{
+		SubmissionID:   submission.SubmissionID,
+		PolicyVersion:  int(submission.PolicyVersion.Int64),
+		Recommendation: submission.UnderwritingRecommendation.String,
+		Rules:          rules,
+	}
+}
+
+type LoanInstallment struct {
+	InstallmentNumber int     `json:"installment_number"`
+	DueDate           int64   `json:"due_date"`
+	PrincipalAmount   float64 `json:"principal_amount"`
+	InterestAmount    float64 `json:"interest_amount"`
+	TotalAmount       float64 `json:"total_amount"`
+	RemainingBalance  float64 `json:"remaining_balance"`
+}
+
+type LoanSchedule struct {
+	SubmissionID       string            `json:"submission_id"`
+	PrincipalAmount    int               `json:"principal_amount"`
+	AnnualInterestRate float64           `json:"annual_interest_rate"`
+	InterestMethod     string            `json:"interest_method"`
+	TenureMonths       int               `json:"tenure_months"`
+	TotalInterest      float64           `json:"total_interest"`
+	TotalPayable       float64           `json:"total_payable"`
+	Installments       []LoanInstallment `json:"installments"`
+}
+
+type GetLoanScheduleResponse struct {
+	ErrorMessage *string       `json:"error_message"`
+	Data         *LoanSchedule `json:"data"`
+}
+
+func convertLoanSchedule(loanSubmission *datastore.LoanSubmissionRow, startDate time.Time) ([]*datastore.LoanInstallmentRow, error) {
+	schedule, err := amortization.Generate(
+		float64(loanSubmission.ProposedLoanAmount),
+		loanSubmission.AnnualInterestRate,
+		loanSubmission.ProposedLoanTenure,
+		loanSubmission.InterestMethod,
+		startDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	installments := make([]*datastore.LoanInstallmentRow, 0, len(schedule.Installments))
+	for _, installment := range schedule.Installments {
+		installments = append(installments, &datastore.LoanInstallmentRow{
+			SubmissionID:      loanSubmission.SubmissionID,
+			InstallmentNumber: installment.Number,
+			DueDate:           installment.DueDate.Unix(),
+			PrincipalAmount:   installment.Principal,
+			InterestAmount:    installment.Interest,
+			TotalAmount:       installment.Payment,
+			RemainingBalance:  installment.Balance,
+		})
+	}
+
+	return installments, nil
+}
+
+type LoanSimulationRequest struct {
+	LoanSubmission
+	MonthlyIncome *float64 `json:"monthly_income"`
+}
+
+type LoanSimulation struct {
+	ProductCode        *string                 `json:"product_code"`
+	PrincipalAmount    int                     `json:"principal_amount"`
+	AnnualInterestRate float64                 `json:"annual_interest_rate"`
+	InterestMethod     string                  `json:"interest_method"`
+	TenureMonths       int                     `json:"tenure_months"`
+	MonthlyInstallment float64                 `json:"monthly_installment"`
+	TotalInterest      float64                 `json:"total_interest"`
+	TotalPayable       float64                 `json:"total_payable"`
+	Collateral         *CollateralValuation    `json:"collateral"`
+	Underwriting       *UnderwritingEvaluation `json:"underwriting"`
+}
+
+type LoanSimulationResponse struct {
+	ErrorMessage *string         `json:"error_message"`
+	Data         *LoanSimulation `json:"data"`
+}
+
+type RecordLoanPaymentRequest struct {
+	Amount    float64 `json:"amount"`
+	PaidAt    *int64  `json:"paid_at"`
+	Reference *string `json:"reference"`
+}
+
+type LoanPayment struct {
+	PaymentID           string  `json:"payment_id"`
+	Amount              float64 `json:"amount"`
+	FeeApplied          float64 `json:"fee_applied"`
+	InterestApplied     float64 `json:"interest_applied"`
+	PrincipalApplied    float64 `json:"principal_applied"`
+	UnappliedAmount     float64 `json:"unapplied_amount"`
+	EarlyTerminationFee float64 `json:"early_termination_fee"`
+	WaivedInterest      float64 `json:"waived_interest"`
+	RunningBalance      float64 `json:"running_balance"`
+	Reference           *string `json:"reference"`
+	PaidAt              int64   `json:"paid_at"`
+}
+
+type RecordLoanPaymentResponse struct {
+	ErrorMessage *string      `json:"error_message"`
+	Data         *LoanPayment `json:"data"`
+}
+
+type LoanLedger struct {
+	SubmissionID         string        `json:"submission_id"`
+	LoanStatus           string        `json:"loan_status"`
+	TotalCharged         float64       `json:"total_charged"`
+	TotalPaid            float64       `json:"total_paid"`
+	OutstandingFees      float64       `json:"outstanding_fees"`
+	OutstandingInterest  float64       `json:"outstanding_interest"`
+	OutstandingPrincipal float64       `json:"outstanding_principal"`
+	OutstandingBalance   float64       `json:"outstanding_balance"`
+	CreditBalance        float64       `json:"credit_balance"`
+	Payments             []LoanPayment `json:"payments"`
+}
+
+type GetLoanLedgerResponse struct {
+	ErrorMessage *string     `json:"error_message"`
+	Data         *LoanLedger `json:"data"`
+}
+
+func convertInstallmentBalances(installments []*datastore.LoanInstallmentRow) []ledger.InstallmentBalance {
+	balances := make([]ledger.InstallmentBalance, 0, len(installments))
+	for _, installment := range installments {
+		balances = append(balances, ledger.InstallmentBalance{
+			InstallmentNumber: installment.InstallmentNumber,
+			DueDate:           installment.DueDate,
+			ScheduledInterest: installment.InterestAmount,
+			FeeDue:            amortization.Round(installment.FeeAmount - installment.PaidFee),
+			InterestDue:       amortization.Round(installment.InterestAmount - installment.PaidInterest - installment.WaivedInterest),
+			PrincipalDue:      amortization.Round(installment.PrincipalAmount - installment.PaidPrincipal),
+		})
+	}
+
+	return balances
+}
+
+// convertInstallmentAllocations turns the allocations calculated from
+// installmentRows into rows that are only applied while the installments are
+// still paid as they were in installmentRows.
+func convertInstallmentAllocations(installments []ledger.InstallmentAllocation, installmentRows []*datastore.LoanInstallmentRow) []*datastore.LoanInstallmentAllocationRow {
+	installmentRowsByNumber := make(map[int]*datastore.LoanInstallmentRow, len(installmentRows))
+	for _, installmentRow := range installmentRows {
+		installmentRowsByNumber[installmentRow.InstallmentNumber] = installmentRow
+	}
+
+	allocations := make([]*datastore.LoanInstallmentAllocationRow, 0, len(installments))
+	for _, installment := range installments {
+		allocation := &datastore.LoanInstallmentAllocationRow{
+			InstallmentNumber: installment.InstallmentNumber,
+			Fee:               installment.Fee,
+			Interest:          installment.Interest,
+			Principal:         installment.Principal,
+			WaivedInterest:    installment.WaivedInterest,
+		}
+
+		if installmentRow, ok := installmentRowsByNumber[installment.InstallmentNumber]; ok {
+			allocation.PaidFee = installmentRow.PaidFee
+			allocation.PaidInterest = installmentRow.PaidInterest
+			allocation.PaidPrincipal = installmentRow.PaidPrincipal
+			allocation.PaidWaivedInterest = installmentRow.WaivedInterest
+		}
+
+		allocations = append(allocations, allocation)
+	}
+
+	return allocations
+}
+
+type LoanDelinquency struct {
+	SubmissionID  string  `json:"submission_id"`
+	CustomerID    string  `json:"customer_id"`
+	FullName      string  `json:"full_name"`
+	PhoneNumber   string  `json:"phone_number"`
+	Email         *string `json:"email"`
+	DaysPastDue   int     `json:"days_past_due"`
+	Bucket        string  `json:"bucket"`
+	OverdueAmount float64 `json:"overdue_amount"`
+	EvaluatedAt   int64   `json:"evaluated_at"`
+}
+
+type GetLoanDelinquenciesResponse struct {
+	ErrorMessage *string            `json:"error_message"`
+	Data         *[]LoanDelinquency `json:"data"`
+}
+
+type LoanPayoff struct {
+	SubmissionID         string  `json:"submission_id"`
+	AsOf                 int64   `json:"as_of"`
+	OutstandingPrincipal float64 `json:"outstanding_principal"`
+	AccruedInterest      float64 `json:"accrued_interest"`
+	OutstandingFees      float64 `json:"outstanding_fees"`
+	EarlyTerminationFee  float64 `json:"early_termination_fee"`
+	CreditBalance        float64 `json:"credit_balance"`
+	WaivedInterest       float64 `json:"waived_interest"`
+	PayoffAmount         float64 `json:"payoff_amount"`
+}
+
+type GetLoanPayoffResponse struct {
+	ErrorMessage *string     `json:"error_message"`
+	Data         *LoanPayoff `json:"data"`
+}
+
+type SettleLoanRequest struct {
+	Amount    float64 `json:"amount"`
+	PaidAt    *int64  `json:"paid_at"`
+	Reference *string `json:"reference"`
+	Actor     string  `json:"actor"`
+}
+
+type LoanSettlement struct {
+	LoanStatus string       `json:"loan_status"`
+	Payoff     LoanPayoff   `json:"payoff"`
+	Payment    *LoanPayment `json:"payment"`
+}
+
+type SettleLoanResponse struct {
+	ErrorMessage *string         `json:"error_message"`
+	Data         *LoanSettlement `json:"data"`
+}
+
+func convertLoanPayoff(submissionID string, payoff ledger.Payoff) LoanPayoff {
+	return LoanPayoff{
+		SubmissionID:         submissionID,
+		AsOf:                 payoff.AsOf.Unix(),
+		OutstandingPrincipal: payoff.OutstandingPrincipal,
+		AccruedInterest:      payoff.AccruedInterest,
+		OutstandingFees:      payoff.OutstandingFees,
+		EarlyTerminationFee:  payoff.EarlyTerminationFee,
+		CreditBalance:        payoff.CreditBalance,
+		WaivedInterest:       payoff.WaivedInterest,
+		PayoffAmount:         payoff.PayoffAmount,
+	}
+}
+
+type LoanProduct struct {
+	ProductCode           string   `json:"product_code"`
+	ProductName           string   `json:"product_name"`
+	AnnualInterestRate    float64  `json:"annual_interest_rate"`
+	InterestMethod        string   `json:"interest_method"`
+	MinLoanAmount         int      `json:"min_loan_amount"`
+	MaxLoanAmount         int      `json:"max_loan_amount"`
+	MinTenureMonth        int      `json:"min_tenure_month"`
+	MaxTenureMonth        int      `json:"max_tenure_month"`
+	EligibleVehicleTypes  []string `json:"eligible_vehicle_types"`
+	IsCommercialVehicle   bool     `json:"is_commercial_vehicle"`
+	EffectiveFrom         int64    `json:"effective_from"`
+	EffectiveUntil        *int64   `json:"effective_until"`
+	RequiredDocumentTypes []string `json:"required_document_types"`
+}
+
+type GetAllLoanProductsResponse struct {
+	ErrorMessage *string        `json:"error_message"`
+	Data         *[]LoanProduct `json:"data"`
+}
+
+type GetLoanProductResponse struct {
+	ErrorMessage *string      `json:"error_message"`
+	Data         *LoanProduct `json:"data"`
+}
+
+type CreateLoanProductResponse struct {
+	ErrorMessage *string `json:"error_message"`
+	ProductCode  *string `json:"product_code"`
+	Created      bool    `json:"created"`
+}
+
+type UpdateLoanProductResponse struct {
+	ErrorMessage *string `json:"error_message"`
+	ProductCode  *string `json:"product_code"`
+	Updated      bool    `json:"updated"`
+}
+
+type DeleteLoanProductResponse struct {
+	ErrorMessage *string `json:"error_message"`
+	ProductCode  *string `json:"product_code"`
+	Deleted      bool    `json:"deleted"`
+}
+
+func convertLoanProduct(product *LoanProduct) *datastore.LoanProductRow {
+	if product == nil {
+		return nil
+	}
+
+	vehicleTypes := make([]string, 0, len(product.EligibleVehicleTypes))
+	for _, vehicleType := range product.EligibleVehicleTypes {
+		vehicleTypes = append(vehicleTypes, strings.ToLower(strings.TrimSpace(vehicleType)))
+	}
+
+	documentTypes := make([]string, 0, len(product.RequiredDocumentTypes))
+	for _, documentType := range product.RequiredDocumentTypes {
+		documentTypes = append(documentTypes, strings.ToUpper(strings.TrimSpace(documentType)))
+	}
+
+	row := &datastore.LoanProductRow{
+		ProductCode:           strings.TrimSpace(product.ProductCode),
+		ProductName:           product.ProductName,
+		AnnualInterestRate:    product.AnnualInterestRate,
+		InterestMethod:        product.InterestMethod,
+		MinLoanAmount:         product.MinLoanAmount,
+		MaxLoanAmount:         product.MaxLoanAmount,
+		MinTenureMonth:        product.MinTenureMonth,
+		MaxTenureMonth:        product.MaxTenureMonth,
+		EligibleVehicleTypes:  strings.Join(vehicleTypes, ","),
+		IsCommercialVehicle:   product.IsCommercialVehicle,
+		EffectiveFrom:         product.EffectiveFrom,
+		RequiredDocumentTypes: strings.Join(documentTypes, ","),
+	}
+
+	if product.EffectiveUntil != nil {
+		row.EffectiveUntil = sql.NullInt64{
+			Int64: *product.EffectiveUntil,
+			Valid: true,
+		}
+	}
+
+	return row
+}
+
+func convertLoanProductRow(row *datastore.LoanProductRow) LoanProduct {
+	return LoanProduct{
+		ProductCode:           row.ProductCode,
+		ProductName:           row.ProductName,
+		AnnualInterestRate:    row.AnnualInterestRate,
+		InterestMethod:        row.InterestMethod,
+		MinLoanAmount:         row.MinLoanAmount,
+		MaxLoanAmount:         row.MaxLoanAmount,
+		MinTenureMonth:        row.MinTenureMonth,
+		MaxTenureMonth:        row.MaxTenureMonth,
+		EligibleVehicleTypes:  strings.Split(row.EligibleVehicleTypes, ","),
+		IsCommercialVehicle:   row.IsCommercialVehicle,
+		EffectiveFrom:         row.EffectiveFrom,
+		EffectiveUntil:        convertNullInt64(row.EffectiveUntil),
+		RequiredDocumentTypes: documents.ParseTypes(row.RequiredDocumentTypes),
+	}
+}
+
+type VehicleReferencePrice struct {
+	VehicleBrand string  `json:"vehicle_brand"`
+	VehicleModel string  `json:"vehicle_model"`
+	VehicleType  string  `json:"vehicle_type"`
+	NewPrice     float64 `json:"new_price"`
+	UpdatedAt    int64   `json:"updated_at"`
+}
+
+type GetAllVehicleReferencePricesResponse struct {
+	ErrorMessage *string                  `json:"error_message"`
+	Data         *[]VehicleReferencePrice `json:"data"`
+}
+
+type ImportVehicleReferencePricesResponse struct {
+	ErrorMessage *string `json:"error_message"`
+	Imported     int     `json:"imported"`
+}
+
+type CollateralValuation struct {
+	NewPrice       float64 `json:"new_price"`
+	AgeYears       int     `json:"age_years"`
+	AgeFactor      float64 `json:"age_factor"`
+	MileageFactor  float64 `json:"mileage_factor"`
+	EstimatedValue float64 `json:"estimated_value"`
+	LoanToValue    float64 `json:"loan_to_value"`
+}
+
+func convertReferencePriceRow(row *datastore.VehicleReferencePriceRow) valuation.ReferencePrice {
+	return valuation.ReferencePrice{
+		VehicleBrand: row.VehicleBrand,
+		VehicleModel: row.VehicleModel,
+		VehicleType:  row.VehicleType,
+		NewPrice:     row.NewPrice,
+	}
+}
+
+func convertCollateralValuation(estimate *valuation.Valuation, loanToValue float64) *CollateralValuation {
+	if estimate == nil {
+		return nil
+	}
+
+	return &CollateralValuation{
+		NewPrice:       estimate.NewPrice,
+		AgeYears:       estimate.AgeYears,
+		AgeFactor:      math.Round(estimate.AgeFactor*10000) / 10000,
+		MileageFactor:  math.Round(estimate.MileageFactor*10000) / 10000,
+		EstimatedValue: estimate.EstimatedValue,
+		LoanToValue:    loanToValue,
+	}
+}
+
+type VehicleCatalogueEntry struct {
+	CatalogueID  string `json:"catalogue_id"`
+	VehicleBrand string `json:"vehicle_brand"`
+	VehicleModel string `json:"vehicle_model"`
+	VehicleType  string `json:"vehicle_type"`
+}
+
+type GetAllVehicleCatalogueEntriesResponse struct {
+	ErrorMessage *string                  `json:"error_message"`
+	Data         *[]VehicleCatalogueEntry `json:"data"`
+}
+
+type GetVehicleCatalogueEntryResponse struct {
+	ErrorMessage *string                `json:"error_message"`
+	Data         *VehicleCatalogueEntry `json:"data"`
+}
+
+type CreateVehicleCatalogueEntryResponse struct {
+	ErrorMessage *string `json:"error_message"`
+	CatalogueID  *string `json:"catalogue_id"`
+	Created      bool    `json:"created"`
+}
+
+type UpdateVehicleCatalogueEntryResponse struct {
+	ErrorMessage *string `json:"error_message"`
+	CatalogueID  *string `json:"catalogue_id"`
+	Updated      bool    `json:"updated"`
+}
+
+type DeleteVehicleCatalogueEntryResponse struct {
+	ErrorMessage *string `json:"error_message"`
+	CatalogueID  *string `json:"catalogue_id"`
+	Deleted      bool    `json:"deleted"`
+}
+
+func convertVehicleCatalogueRow(row *datastore.VehicleCatalogueRow) VehicleCatalogueEntry {
+	return VehicleCatalogueEntry{
+		CatalogueID:  row.CatalogueID,
+		VehicleBrand: row.VehicleBrand,
+		VehicleModel: row.VehicleModel,
+		VehicleType:  row.VehicleType,
+	}
+}
+
+type CollateralLien struct {
+	SubmissionID  string  `json:"submission_id"`
+	CustomerID    string  `json:"customer_id"`
+	LoanStatus    string  `json:"loan_status"`
+	Active        bool    `json:"active"`
+	PledgedAt     int64   `json:"pledged_at"`
+	ReleasedAt    *int64  `json:"released_at"`
+	ReleaseReason *string `json:"release_reason"`
+}
+
+type CollateralLienHistory struct {
+	VehicleLicenseNumber  string           `json:"vehicle_license_number"`
+	Pledged               bool             `json:"pledged"`
+	PledgedToSubmissionID *string          `json:"pledged_to_submission_id"`
+	Liens                 []CollateralLien `json:"liens"`
+}
+
+type GetCollateralLienHistoryResponse struct {
+	ErrorMessage *string                `json:"error_message"`
+	Data         *CollateralLienHistory `json:"data"`
+}
+
+// loanCustomerFieldNames lists the LoanCustomer fields in the order they are
+// exported.
+var loanCustomerFieldNames = []string{
+	"customer_id",
+	"id_card_number",
+	"full_name",
+	"birth_date",
+	"phone_number",
+	"email",
+	"monthly_income",
+	"address_street",
+	"address_city",
+}
+
+// loanCustomerFields maps the JSON name of every LoanCustomer field to its
+// value, for projecting customer lists.
+var loanCustomerFields = map[string]func(LoanCustomer) any{
+	"customer_id":    func(c LoanCustomer) any { return c.CustomerID },
+	"id_card_number": func(c LoanCustomer) any { return c.IDCardNumber },
+	"full_name":      func(c LoanCustomer) any { return c.FullName },
+	"birth_date":     func(c LoanCustomer) any { return c.BirthDate },
+	"phone_number":   func(c LoanCustomer) any { return c.PhoneNumber },
+	"email":          func(c LoanCustomer) any { return c.Email },
+	"monthly_income": func(c LoanCustomer) any { return c.MonthlyIncome },
+	"address_street": func(c LoanCustomer) any { return c.AddressStreet },
+	"address_city":   func(c LoanCustomer) any { return c.AddressCity },
+}
+
+// parseLoanCustomerFields splits a comma-separated fields parameter. An empty
+// parameter selects every field and returns nil.
+func parseLoanCustomerFields(fieldsParam string) ([]string, error) {
+	if strings.TrimSpace(fieldsParam) == "" {
+		return nil, nil
+	}
+
+	var fields []string
+	for _, field := range strings.Split(fieldsParam, ",") {
+		field = strings.TrimSpace(field)
+		if _, ok := loanCustomerFields[field]; !ok {
+			return nil, fmt.Errorf("invalid field %q", field)
+		}
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields, nil
+}
+
+func projectLoanCustomer(loanCustomer LoanCustomer, fields []string) map[string]any {
+	projected := make(map[string]any, len(fields))
+	for _, field := range fields {
+		projected[field] = loanCustomerFields[field](loanCustomer)
+	}
+
+	return projected
+}
+
+func convertLoanSubmissionRow(row *datastore.LoanSubmissionRow) LoanSubmission {
+	return LoanSubmission{
+		SubmissionID:               row.SubmissionID,
+		VehicleType:                row.VehicleType,
+		VehicleBrand:               row.VehicleBrand,
+		VehicleModel:               row.VehicleModel,
+		VehicleLicenseNumber:       row.VehicleLicenseNumber,
+		VehicleRegionCode:          convertNullStringPointer(row.VehicleRegionCode),
+		VehicleOdometer:            row.VehicleOdometer,
+		ManufacturingYear:          row.ManufacturingYear,
+		ProposedLoanAmount:         row.ProposedLoanAmount,
+		ProposedLoanTenureMonth:    row.ProposedLoanTenure,
+		AnnualInterestRate:         &row.AnnualInterestRate,
+		InterestMethod:             &row.InterestMethod,
+		ProductCode:                convertNullStringPointer(row.ProductCode),
+		CollateralValue:            convertNullFloat64(row.CollateralValue),
+		LoanToValue:                convertNullFloat64(row.LoanToValue),
+		IsCommercialVehicle:        row.IsCommercialVehicle,
+		LoanStatus:                 row.LoanStatus,
+		PolicyVersion:              convertNullInt64(row.PolicyVersion),
+		UnderwritingRecommendation: convertNullStringPointer(row.UnderwritingRecommendation),
+	}
+}
+
+func convertLoanCustomerRow(row *datastore.LoanCustomerRow) LoanCustomer {
+	return LoanCustomer{
+		CustomerID:    row.CustomerID,
+		IDCardNumber:  row.IDCardNumber,
+		FullName:      row.FullName,
+		BirthDate:     row.BirthDate,
+		PhoneNumber:   row.PhoneNumber,
+		Email:         convertNullStringPointer(row.Email),
+		MonthlyIncome: row.MonthlyIncome,
+		AddressStreet: row.AddressStreet,
+		AddressCity:   row.AddressCity,
+	}
+}
+
+// convertLoanSubmissionParties also returns the household income of the
+// parties, counting only the roles that contribute income.
+func convertLoanSubmissionParties(rows []*datastore.LoanSubmissionPartyWithCustomerRow) ([]LoanSubmissionParty, float64) {
+	parties := make([]LoanSubmissionParty, 0, len(rows))
+	householdIncome := 0.0
+
+	for _, row := range rows {
+		parties = append(parties, LoanSubmissionParty{
+			PartyRole: row.PartyRole,
+			Customer:  convertLoanCustomerRow(&row.Customer),
+		})
+
+		if loanparty.ContributesIncome(row.PartyRole) {
+			householdIncome += row.Customer.MonthlyIncome
+		}
+	}
+
+	return parties, householdIncome
+}
+
+// coApplicantIncome sums the income of the co-applicants of a submission; the
+// primary applicant's income is taken from the customer record itself.
+func coApplicantIncome(rows []*datastore.LoanSubmissionPartyWithCustomerRow) float64 {
+	income := 0.0
+	for _, row := range rows {
+		if row.PartyRole == loanparty.CoApplicant {
+			income += row.Customer.MonthlyIncome
+		}
+	}
+	return income
+}
+
+type LoanDocument struct {
+	DocumentID     string  `json:"document_id"`
+	SubmissionID   string  `json:"submission_id"`
+	DocumentType   string  `json:"document_type"`
+	FileName       string  `json:"file_name"`
+	MimeType       string  `json:"mime_type"`
+	SizeBytes      int64   `json:"size_bytes"`
+	ChecksumSHA256 string  `json:"checksum_sha256"`
+	UploadedBy     string  `json:"uploaded_by"`
+	UploadedAt     int64   `json:"uploaded_at"`
+	Verified       bool    `json:"verified"`
+	VerifiedBy     *string `json:"verified_by"`
+	VerifiedAt     *int64  `json:"verified_at"`
+}
+
+type GetAllLoanDocumentsResponse struct {
+	ErrorMessage *string         `json:"error_message"`
+	Data         *[]LoanDocument `json:"data"`
+}
+
+type UploadLoanDocumentResponse struct {
+	ErrorMessage *string       `json:"error_message"`
+	Data         *LoanDocument `json:"data"`
+}
+
+type DeleteLoanDocumentResponse struct {
+	ErrorMessage *string `json:"error_message"`
+	DocumentID   *string `json:"document_id"`
+	Deleted      bool    `json:"deleted"`
+}
+
+func convertLoanDocumentRow(row *datastore.LoanDocumentRow) LoanDocument {
+	return LoanDocument{
+		DocumentID:     row.DocumentID,
+		SubmissionID:   row.SubmissionID,
+		DocumentType:   row.DocumentType,
+		FileName:       row.FileName,
+		MimeType:       row.MimeType,
+		SizeBytes:      row.SizeBytes,
+		ChecksumSHA256: row.ChecksumSHA256,
+		UploadedBy:     row.UploadedBy,
+		UploadedAt:     row.UploadedAt,
+		Verified:       row.VerifiedAt.Valid,
+		VerifiedBy:     convertNullStringPointer(row.VerifiedBy),
+		VerifiedAt:     convertNullInt64(row.VerifiedAt),
+	}
+}
+
+type VerifyLoanDocumentRequest struct {
+	VerifiedBy string `json:"verified_by"`
+}
+
+type VerifyLoanDocumentResponse struct {
+	ErrorMessage *string `json:"error_message"`
+	DocumentID   *string `json:"document_id"`
+	Verified     bool    `json:"verified"`
+}
+
+type LoanDocumentChecklistItem struct {
+	DocumentType string `json:"document_type"`
+	Uploaded     bool   `json:"uploaded"`
+	Verified     bool   `json:"verified"`
+}
+
+type LoanDocumentChecklist struct {
+	SubmissionID string                      `json:"submission_id"`
+	ProductCode  *string                     `json:"product_code"`
+	Complete     bool                        `json:"complete"`
+	Items        []LoanDocumentChecklistItem `json:"items"`
+	Missing      []string                    `json:"missing"`
+}
+
+type GetLoanDocumentChecklistResponse struct {
+	ErrorMessage *string                `json:"error_message"`
+	Data         *LoanDocumentChecklist `json:"data"`
+}
+
+func convertLoanDocumentChecklist(submissionID string, productCode sql.NullString, checklist documents.Checklist) LoanDocumentChecklist {
+	items := make([]LoanDocumentChecklistItem, 0, len(checklist.Items))
+	for _, item := range checklist.Items {
+		items = append(items, LoanDocumentChecklistItem{
+			DocumentType: item.DocumentType,
+			Uploaded:     item.Uploaded,
+			Verified:     item.Verified,
+		})
+	}
+
+	return LoanDocumentChecklist{
+		SubmissionID: submissionID,
+		ProductCode:  convertNullStringPointer(productCode),
+		Complete:     checklist.Complete(),
+		Items:        items,
+		Missing:      checklist.Missing,
+	}
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 200
+)
+
+// pageCursor is the position after the last row of a page. Clients get it as
+// an opaque string and only hand it back; Sort ties it to the ordering it was
+// issued for. The sort key of the row is kept in Value when it is a number and
+// in Text when it is text.
+type pageCursor struct {
+	Sort  string `json:"s"`
+	Value int64  `json:"v,omitempty"`
+	Text  string `json:"t,omitempty"`
+	ID    string `json:"id"`
+}
+
+func encodePageCursor(cursor pageCursor) string {
+	content, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(content)
+}
+
+func decodePageCursor(encoded string, sort string) (pageCursor, error) {
+	content, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return pageCursor{}, errors.New("invalid cursor")
+	}
+
+	var cursor pageCursor
+	if err := json.Unmarshal(content, &cursor); err != nil || cursor.ID == "" {
+		return pageCursor{}, errors.New("invalid cursor")
+	}
+
+	if cursor.Sort != sort {
+		return pageCursor{}, fmt.Errorf("cursor was issued for sort %s, not %s", cursor.Sort, sort)
+	}
+
+	return cursor, nil
+}
+
+func parsePageLimit(limitParam string) (int, error) {
+	if limitParam == "" {
+		return defaultPageLimit, nil
+	}
+
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil || limit <= 0 || limit > maxPageLimit {
+		return 0, fmt.Errorf("invalid limit %q: must be a number from 1 to %d", limitParam, maxPageLimit)
+	}
+
+	return limit, nil
+}
+
+func parseIntParam(values url.Values, name string) (*int, error) {
+	param := values.Get(name)
+	if param == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.Atoi(param)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s, expected a number: %s", name, param)
+	}
+
+	return &value, nil
+}
+
+func parseUnixParam(values url.Values, name string) (*int64, error) {
+	param := values.Get(name)
+	if param == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s, expected a unix timestamp: %s", name, param)
+	}
+
+	return &value, nil
+}
+
+func parseFloatParam(values url.Values, name string) (*float64, error) {
+	param := values.Get(name)
+	if param == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s, expected a number: %s", name, param)
+	}
+
+	return &value, nil
+}
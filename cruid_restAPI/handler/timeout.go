@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/alphaloan/vehicle/timeouts"
+)
+
+type TimeoutErrorResponse struct {
+	ErrorMessage  *string `json:"error_message"`
+	ErrorCode     string  `json:"error_code"`
+	Operation     string  `json:"operation"`
+	TimeoutMillis int64   `json:"timeout_ms"`
+}
+
+// WithTimeout runs next with a request context that expires after the timeout
+// the policy sets for operation. Handlers answer a failed store call with a
+// server error; when that happens after the deadline passed, the client gets
+// 504 Gateway Timeout with a TimeoutErrorResponse instead.
+func WithTimeout(policy timeouts.Policy, operation string, next http.HandlerFunc) http.HandlerFunc {
+	timeout := policy.For(operation)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		tw := &timeoutResponseWriter{
+			ResponseWriter: w,
+			ctx:            ctx,
+			operation:      operation,
+			timeout:        timeout,
+		}
+
+		next(tw, r.WithContext(ctx))
+	}
+}
+
+type timeoutResponseWriter struct {
+	http.ResponseWriter
+	ctx       context.Context
+	operation string
+	timeout   time.Duration
+	timedOut  bool
+}
+
+func (w *timeoutResponseWriter) WriteHeader(statusCode int) {
+	if statusCode < http.StatusInternalServerError || !errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+
+	w.timedOut = true
+
+	errMsg := "Request timed out"
+	responseBodyErr := TimeoutErrorResponse{
+		ErrorMessage:  &errMsg,
+		ErrorCode:     "TIMEOUT",
+		Operation:     w.operation,
+		TimeoutMillis: w.timeout.Milliseconds(),
+	}
+
+	header := w.ResponseWriter.Header()
+	header.Del("Content-Disposition")
+	header.Del("Content-Length")
+	header.Set("Content-Type", "application/json")
+
+	w.ResponseWriter.WriteHeader(http.StatusGatewayTimeout)
+	json.NewEncoder(w.ResponseWriter).Encode(responseBodyErr)
+}
+
+// Write drops the body of the replaced server error.
+func (w *timeoutResponseWriter) Write(b []byte) (int, error) {
+	if w.timedOut {
+		return len(b), nil
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *timeoutResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/alphaloan/vehicle/datastore"
+	"github.com/alphaloan/vehicle/underwriting"
+)
+
+type UnderwritingHandler struct {
+	UnitOfWork        datastore.UnitOfWork
+	CustomerStore     datastore.CustomerRepository
+	SubmissionStore   datastore.SubmissionRepository
+	PartyStore        datastore.LoanSubmissionPartyStore
+	UnderwritingStore datastore.LoanUnderwritingStore
+	Underwriting      *underwriting.Engine
+}
+
+func NewUnderwritingHandler(
+	unitOfWork datastore.UnitOfWork,
+	customerStore datastore.CustomerRepository,
+	submissionStore datastore.SubmissionRepository,
+	partyStore datastore.LoanSubmissionPartyStore,
+	underwritingStore datastore.LoanUnderwritingStore,
+	underwritingEngine *underwriting.Engine) *UnderwritingHandler {
+	return &UnderwritingHandler{
+		UnitOfWork:        unitOfWork,
+		CustomerStore:     customerStore,
+		SubmissionStore:   submissionStore,
+		PartyStore:        partyStore,
+		UnderwritingStore: underwritingStore,
+		Underwriting:      underwritingEngine,
+	}
+}
+
+func (h *UnderwritingHandler) HandleEvaluateLoanSubmission(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	submissionID := r.PathValue("submission_id")
+	if !validateLoanSubmissionID(w, submissionID) {
+		return
+	}
+
+	loanSubmissionRow, err := h.SubmissionStore.GetLoanSubmissionByID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get loan submission"
+		responseBodyErr := EvaluateLoanSubmissionResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if loanSubmissionRow == nil {
+		errMsg := "Loan submission not found"
+		responseBodyErr := EvaluateLoanSubmissionResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	loanCustomerRow, err := h.CustomerStore.GetLoanCustomerRowByID(r.Context(), loanSubmissionRow.CustomerID)
+
+	if err != nil {
+		errMsg := "Failed to get loan customer"
+		responseBodyErr := EvaluateLoanSubmissionResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if loanCustomerRow == nil {
+		errMsg := "Customer not found"
+		responseBodyErr := EvaluateLoanSubmissionResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	partyRows, err := h.PartyStore.GetPartiesBySubmissionID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get loan submission parties"
+		responseBodyErr := EvaluateLoanSubmissionResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	application := convertUnderwritingApplication(loanCustomerRow, loanSubmissionRow)
+	application.CoApplicantIncome = coApplicantIncome(partyRows)
+
+	evaluation := h.Underwriting.Evaluate(h.Underwriting.Policy(), application)
+
+	// The recommendation and the rule results behind it are replaced together.
+	err = h.UnitOfWork.Do(r.Context(), func(tx datastore.Tx) error {
+		_, err := h.SubmissionStore.WithTx(tx).UpdateUnderwritingByID(r.Context(), submissionID, evaluation.PolicyVersion, evaluation.Recommendation, time.Now().Unix())
+		if err != nil {
+			return err
+		}
+
+		return h.UnderwritingStore.WithTx(tx).ReplaceUnderwritingResults(r.Context(), submissionID, convertUnderwritingResultRows(submissionID, evaluation))
+	})
+
+	if err != nil {
+		errMsg := "Failed to record underwriting evaluation"
+		status := http.StatusInternalServerError
+		if errors.Is(err, sql.ErrNoRows) {
+			errMsg = "Loan submission not found"
+			status = http.StatusNotFound
+		}
+
+		responseBodyErr := EvaluateLoanSubmissionResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	responseBody := EvaluateLoanSubmissionResponse{
+		Data: convertUnderwritingEvaluation(submissionID, evaluation),
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+// HandleGetLoanSubmissionUnderwriting returns the last evaluation of a
+// submission as it was recorded, without evaluating it again.
+func (h *UnderwritingHandler) HandleGetLoanSubmissionUnderwriting(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	submissionID := r.PathValue("submission_id")
+	if !validateLoanSubmissionID(w, submissionID) {
+		return
+	}
+
+	loanSubmissionRow, err := h.SubmissionStore.GetLoanSubmissionByID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get loan submission"
+		responseBodyErr := EvaluateLoanSubmissionResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if loanSubmissionRow == nil || !loanSubmissionRow.UnderwritingRecommendation.Valid {
+		errMsg := "Loan submission not found"
+		if loanSubmissionRow != nil {
+			errMsg = "Loan submission has not been evaluated"
+		}
+
+		responseBodyErr := EvaluateLoanSubmissionResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	resultRows, err := h.UnderwritingStore.GetUnderwritingResultsBySubmissionID(r.Context(), submissionID)
+
+	if err != nil {
+		errMsg := "Failed to get underwriting results"
+		responseBodyErr := EvaluateLoanSubmissionResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	responseBody := EvaluateLoanSubmissionResponse{
+		Data: convertStoredUnderwritingEvaluation(loanSubmissionRow, resultRows),
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
@@ -0,0 +1,404 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/alphaloan/vehicle/catalogue"
+	"github.com/alphaloan/vehicle/datastore"
+	"github.com/google/uuid"
+)
+
+type VehicleCatalogueHandler struct {
+	CatalogueStore datastore.VehicleCatalogueStore
+}
+
+func NewVehicleCatalogueHandler(catalogueStore datastore.VehicleCatalogueStore) *VehicleCatalogueHandler {
+	return &VehicleCatalogueHandler{
+		CatalogueStore: catalogueStore,
+	}
+}
+
+// matchVehicleCatalogue replaces the brand, model and type of the proposal with
+// their catalogue spelling. A *catalogue.MismatchError means the vehicle is
+// not in the catalogue.
+func matchVehicleCatalogue(ctx context.Context, catalogueStore datastore.VehicleCatalogueStore, proposal *LoanSubmission) error {
+	catalogueRows, err := catalogueStore.GetAllCatalogueEntries(ctx)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]catalogue.Entry, 0, len(catalogueRows))
+	for _, row := range catalogueRows {
+		entries = append(entries, catalogue.Entry{
+			VehicleBrand: row.VehicleBrand,
+			VehicleModel: row.VehicleModel,
+			VehicleType:  row.VehicleType,
+		})
+	}
+
+	entry, err := catalogue.Match(entries, proposal.VehicleBrand, proposal.VehicleModel, proposal.VehicleType)
+	if err != nil {
+		return err
+	}
+
+	proposal.VehicleBrand = entry.VehicleBrand
+	proposal.VehicleModel = entry.VehicleModel
+	proposal.VehicleType = entry.VehicleType
+
+	return nil
+}
+
+func validateVehicleCatalogueEntry(entry *VehicleCatalogueEntry) error {
+	entry.VehicleBrand = catalogue.Normalize(entry.VehicleBrand)
+	entry.VehicleModel = catalogue.Normalize(entry.VehicleModel)
+	entry.VehicleType = catalogue.NormalizeVehicleType(entry.VehicleType)
+
+	if entry.VehicleBrand == "" || entry.VehicleModel == "" || entry.VehicleType == "" {
+		return errors.New("vehicle_brand, vehicle_model and vehicle_type are required")
+	}
+
+	return nil
+}
+
+func validateCatalogueID(w http.ResponseWriter, catalogueID string) bool {
+	if catalogueID == "" {
+		errMsg := "Missing catalogue_id path variable"
+		responseBodyErr := GetVehicleCatalogueEntryResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return false
+	}
+
+	if !IsValidUUID(catalogueID) {
+		errMsg := "Invalid catalogue_id: " + catalogueID
+		responseBodyErr := GetVehicleCatalogueEntryResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return false
+	}
+
+	return true
+}
+
+func (h *VehicleCatalogueHandler) HandleGetAllCatalogueEntries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	catalogueRows, err := h.CatalogueStore.GetAllCatalogueEntries(r.Context())
+
+	if err != nil {
+		errMsg := "Failed to get vehicle catalogue"
+		responseBodyErr := GetAllVehicleCatalogueEntriesResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	entries := make([]VehicleCatalogueEntry, 0, len(catalogueRows))
+	for _, row := range catalogueRows {
+		entries = append(entries, convertVehicleCatalogueRow(row))
+	}
+
+	responseBody := GetAllVehicleCatalogueEntriesResponse{
+		Data: &entries,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+func (h *VehicleCatalogueHandler) HandleCreateCatalogueEntry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var request VehicleCatalogueEntry
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Bad request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateVehicleCatalogueEntry(&request); err != nil {
+		errMsg := "Invalid catalogue entry: " + err.Error()
+		responseBodyErr := CreateVehicleCatalogueEntryResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	existingRow, err := h.CatalogueStore.GetCatalogueEntryByBrandModel(r.Context(), request.VehicleBrand, request.VehicleModel)
+
+	if err != nil {
+		errMsg := "Failed to get vehicle catalogue entry"
+		responseBodyErr := CreateVehicleCatalogueEntryResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if existingRow != nil {
+		errMsg := "Vehicle " + existingRow.VehicleBrand + " " + existingRow.VehicleModel + " is already in the catalogue"
+		responseBodyErr := CreateVehicleCatalogueEntryResponse{
+			ErrorMessage: &errMsg,
+			CatalogueID:  &existingRow.CatalogueID,
+		}
+
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	now := time.Now().Unix()
+
+	createdCatalogueID, err := h.CatalogueStore.CreateCatalogueEntry(r.Context(), &datastore.VehicleCatalogueRow{
+		CatalogueID:  uuid.New().String(),
+		VehicleBrand: request.VehicleBrand,
+		VehicleModel: request.VehicleModel,
+		VehicleType:  request.VehicleType,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	})
+
+	if err != nil {
+		errMsg := "Failed to create vehicle catalogue entry"
+		responseBodyErr := CreateVehicleCatalogueEntryResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	responseBody := CreateVehicleCatalogueEntryResponse{
+		CatalogueID: &createdCatalogueID,
+		Created:     true,
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+func (h *VehicleCatalogueHandler) HandleGetCatalogueEntryInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	catalogueID := r.PathValue("catalogue_id")
+	if !validateCatalogueID(w, catalogueID) {
+		return
+	}
+
+	catalogueRow, err := h.CatalogueStore.GetCatalogueEntryByID(r.Context(), catalogueID)
+
+	if err != nil {
+		errMsg := "Failed to get vehicle catalogue entry"
+		responseBodyErr := GetVehicleCatalogueEntryResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if catalogueRow == nil {
+		errMsg := "Catalogue entry not found"
+		responseBodyErr := GetVehicleCatalogueEntryResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	entry := convertVehicleCatalogueRow(catalogueRow)
+
+	responseBody := GetVehicleCatalogueEntryResponse{
+		Data: &entry,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+func (h *VehicleCatalogueHandler) HandleUpdateCatalogueEntry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "Only PATCH method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	catalogueID := r.PathValue("catalogue_id")
+	if !validateCatalogueID(w, catalogueID) {
+		return
+	}
+
+	var request VehicleCatalogueEntry
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Bad request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := validateVehicleCatalogueEntry(&request); err != nil {
+		errMsg := "Invalid catalogue entry: " + err.Error()
+		responseBodyErr := UpdateVehicleCatalogueEntryResponse{
+			ErrorMessage: &errMsg,
+			CatalogueID:  &catalogueID,
+			Updated:      false,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	existingRow, err := h.CatalogueStore.GetCatalogueEntryByBrandModel(r.Context(), request.VehicleBrand, request.VehicleModel)
+
+	if err != nil {
+		errMsg := "Failed to update vehicle catalogue entry"
+		responseBodyErr := UpdateVehicleCatalogueEntryResponse{
+			ErrorMessage: &errMsg,
+			CatalogueID:  &catalogueID,
+			Updated:      false,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	if existingRow != nil && existingRow.CatalogueID != catalogueID {
+		errMsg := "Vehicle " + existingRow.VehicleBrand + " " + existingRow.VehicleModel + " is already in the catalogue"
+		responseBodyErr := UpdateVehicleCatalogueEntryResponse{
+			ErrorMessage: &errMsg,
+			CatalogueID:  &catalogueID,
+			Updated:      false,
+		}
+
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	updatedCatalogueID, err := h.CatalogueStore.UpdateCatalogueEntryByID(r.Context(), &datastore.VehicleCatalogueRow{
+		VehicleBrand: request.VehicleBrand,
+		VehicleModel: request.VehicleModel,
+		VehicleType:  request.VehicleType,
+		UpdatedAt:    time.Now().Unix(),
+	}, catalogueID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errMsg := "Catalogue ID not found"
+			responseBodyErr := UpdateVehicleCatalogueEntryResponse{
+				ErrorMessage: &errMsg,
+				CatalogueID:  &catalogueID,
+				Updated:      false,
+			}
+
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(responseBodyErr)
+		} else {
+			errMsg := "Failed to update vehicle catalogue entry"
+			responseBodyErr := UpdateVehicleCatalogueEntryResponse{
+				ErrorMessage: &errMsg,
+				CatalogueID:  &catalogueID,
+				Updated:      false,
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(responseBodyErr)
+		}
+		return
+	}
+
+	responseBody := UpdateVehicleCatalogueEntryResponse{
+		CatalogueID: &updatedCatalogueID,
+		Updated:     true,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+func (h *VehicleCatalogueHandler) HandleDeleteCatalogueEntry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Only DELETE method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	catalogueID := r.PathValue("catalogue_id")
+	if !validateCatalogueID(w, catalogueID) {
+		return
+	}
+
+	deletedCatalogueID, err := h.CatalogueStore.DeleteCatalogueEntryByID(r.Context(), catalogueID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			errMsg := "Catalogue ID not found"
+			responseBodyErr := DeleteVehicleCatalogueEntryResponse{
+				ErrorMessage: &errMsg,
+				CatalogueID:  &catalogueID,
+				Deleted:      false,
+			}
+
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(responseBodyErr)
+		} else {
+			errMsg := "Failed to delete vehicle catalogue entry"
+			responseBodyErr := DeleteVehicleCatalogueEntryResponse{
+				ErrorMessage: &errMsg,
+				CatalogueID:  &catalogueID,
+				Deleted:      false,
+			}
+
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(responseBodyErr)
+		}
+		return
+	}
+
+	responseBody := DeleteVehicleCatalogueEntryResponse{
+		CatalogueID: &deletedCatalogueID,
+		Deleted:     true,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/alphaloan/vehicle/datastore"
+	"github.com/alphaloan/vehicle/valuation"
+)
+
+const maxReferencePriceImportBytes = 10 << 20
+
+type VehicleValuationHandler struct {
+	ReferencePriceStore datastore.VehicleReferencePriceStore
+}
+
+func NewVehicleValuationHandler(referencePriceStore datastore.VehicleReferencePriceStore) *VehicleValuationHandler {
+	return &VehicleValuationHandler{
+		ReferencePriceStore: referencePriceStore,
+	}
+}
+
+// estimateCollateral values the vehicle of a proposal and stores the value and
+// the loan-to-value ratio on the submission row. Vehicles missing from the
+// reference price table are left unvalued and returned as nil.
+func estimateCollateral(
+	ctx context.Context,
+	referencePriceStore datastore.VehicleReferencePriceStore,
+	depreciation valuation.DepreciationModel,
+	loanSubmissionRow *datastore.LoanSubmissionRow,
+	asOf time.Time) (*valuation.Valuation, error) {
+	referencePriceRow, err := referencePriceStore.GetReferencePrice(ctx,
+		valuation.NormalizeKey(loanSubmissionRow.VehicleBrand),
+		valuation.NormalizeKey(loanSubmissionRow.VehicleModel),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if referencePriceRow == nil {
+		return nil, nil
+	}
+
+	estimate := depreciation.Estimate(
+		convertReferencePriceRow(referencePriceRow),
+		loanSubmissionRow.ManufacturingYear,
+		loanSubmissionRow.VehicleOdometer,
+		asOf,
+	)
+
+	loanSubmissionRow.CollateralValue.Float64 = estimate.EstimatedValue
+	loanSubmissionRow.CollateralValue.Valid = true
+	loanSubmissionRow.LoanToValue.Float64 = valuation.LoanToValue(loanSubmissionRow.ProposedLoanAmount, estimate.EstimatedValue)
+	loanSubmissionRow.LoanToValue.Valid = true
+
+	return &estimate, nil
+}
+
+func (h *VehicleValuationHandler) HandleGetAllReferencePrices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	referencePriceRows, err := h.ReferencePriceStore.GetAllReferencePrices(r.Context())
+
+	if err != nil {
+		errMsg := "Failed to get vehicle reference prices"
+		responseBodyErr := GetAllVehicleReferencePricesResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	referencePrices := make([]VehicleReferencePrice, 0, len(referencePriceRows))
+	for _, row := range referencePriceRows {
+		referencePrices = append(referencePrices, VehicleReferencePrice{
+			VehicleBrand: row.VehicleBrand,
+			VehicleModel: row.VehicleModel,
+			VehicleType:  row.VehicleType,
+			NewPrice:     row.NewPrice,
+			UpdatedAt:    row.UpdatedAt,
+		})
+	}
+
+	responseBody := GetAllVehicleReferencePricesResponse{
+		Data: &referencePrices,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
+
+// HandleImportReferencePrices takes a CSV price list as the request body and
+// adds or replaces the listed vehicles; vehicles not in the file are kept.
+func (h *VehicleValuationHandler) HandleImportReferencePrices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	referencePrices, err := valuation.ParseReferencePricesCSV(http.MaxBytesReader(w, r.Body, maxReferencePriceImportBytes))
+
+	if err != nil {
+		errMsg := "Invalid reference price csv: " + err.Error()
+		responseBodyErr := ImportVehicleReferencePricesResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	now := time.Now().Unix()
+
+	referencePriceRows := make([]*datastore.VehicleReferencePriceRow, 0, len(referencePrices))
+	for _, price := range referencePrices {
+		referencePriceRows = append(referencePriceRows, &datastore.VehicleReferencePriceRow{
+			VehicleBrand: price.VehicleBrand,
+			VehicleModel: price.VehicleModel,
+			VehicleType:  price.VehicleType,
+			NewPrice:     price.NewPrice,
+			UpdatedAt:    now,
+		})
+	}
+
+	if err := h.ReferencePriceStore.UpsertReferencePrices(r.Context(), referencePriceRows); err != nil {
+		errMsg := "Failed to import vehicle reference prices"
+		responseBodyErr := ImportVehicleReferencePricesResponse{
+			ErrorMessage: &errMsg,
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(responseBodyErr)
+		return
+	}
+
+	responseBody := ImportVehicleReferencePricesResponse{
+		Imported: len(referencePriceRows),
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(responseBody)
+}
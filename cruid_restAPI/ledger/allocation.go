@@ -0,0 +1,89 @@
+package ledger
+
+import "github.com/alphaloan/vehicle/amortization"
+
+type InstallmentBalance struct {
+	InstallmentNumber int
+	DueDate           int64
+	ScheduledInterest float64
+	FeeDue            float64
+	InterestDue       float64
+	PrincipalDue      float64
+}
+
+func (b InstallmentBalance) Outstanding() float64 {
+	return amortization.Round(b.FeeDue + b.InterestDue + b.PrincipalDue)
+}
+
+type InstallmentAllocation struct {
+	InstallmentNumber int
+	Fee               float64
+	Interest          float64
+	Principal         float64
+	WaivedInterest    float64
+}
+
+type Allocation struct {
+	Installments []InstallmentAllocation
+	Fee          float64
+	Interest     float64
+	Principal    float64
+	Unapplied    float64
+}
+
+// Apply spreads a payment over the installments in the order they are given,
+// settling the fees, then the interest, then the principal of one installment
+// before moving on to the next. Whatever is left once every installment is
+// settled is returned as Unapplied.
+func Apply(amount float64, installments []InstallmentBalance) Allocation {
+	remaining := amortization.Round(amount)
+	allocation := Allocation{}
+
+	for _, installment := range installments {
+		if remaining <= 0 {
+			break
+		}
+
+		if installment.Outstanding() <= 0 {
+			continue
+		}
+
+		installmentAllocation := InstallmentAllocation{
+			InstallmentNumber: installment.InstallmentNumber,
+		}
+
+		installmentAllocation.Fee, remaining = take(remaining, installment.FeeDue)
+		installmentAllocation.Interest, remaining = take(remaining, installment.InterestDue)
+		installmentAllocation.Principal, remaining = take(remaining, installment.PrincipalDue)
+
+		allocation.Installments = append(allocation.Installments, installmentAllocation)
+		allocation.Fee = amortization.Round(allocation.Fee + installmentAllocation.Fee)
+		allocation.Interest = amortization.Round(allocation.Interest + installmentAllocation.Interest)
+		allocation.Principal = amortization.Round(allocation.Principal + installmentAllocation.Principal)
+	}
+
+	allocation.Unapplied = remaining
+
+	return allocation
+}
+
+func take(remaining float64, due float64) (float64, float64) {
+	if due <= 0 || remaining <= 0 {
+		return 0, remaining
+	}
+
+	if remaining < due {
+		return remaining, 0
+	}
+
+	return amortization.Round(due), amortization.Round(remaining - due)
+}
+
+func Outstanding(installments []InstallmentBalance) float64 {
+	var outstanding float64
+	for _, installment := range installments {
+		outstanding = amortization.Round(outstanding + installment.Outstanding())
+	}
+
+	return outstanding
+}
@@ -0,0 +1,141 @@
+package ledger
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	installments := []InstallmentBalance{
+		{InstallmentNumber: 1, FeeDue: 10, InterestDue: 20, PrincipalDue: 100},
+		{InstallmentNumber: 2, FeeDue: 0, InterestDue: 18, PrincipalDue: 102},
+	}
+
+	tests := []struct {
+		name   string
+		amount float64
+		want   Allocation
+	}{
+		{
+			name:   "fee first",
+			amount: 5,
+			want: Allocation{
+				Installments: []InstallmentAllocation{{InstallmentNumber: 1, Fee: 5}},
+				Fee:          5,
+			},
+		},
+		{
+			name:   "fee then interest",
+			amount: 25,
+			want: Allocation{
+				Installments: []InstallmentAllocation{{InstallmentNumber: 1, Fee: 10, Interest: 15}},
+				Fee:          10,
+				Interest:     15,
+			},
+		},
+		{
+			name:   "fee then interest then principal",
+			amount: 80.5,
+			want: Allocation{
+				Installments: []InstallmentAllocation{{InstallmentNumber: 1, Fee: 10, Interest: 20, Principal: 50.5}},
+				Fee:          10,
+				Interest:     20,
+				Principal:    50.5,
+			},
+		},
+		{
+			name:   "settles one installment before the next",
+			amount: 150,
+			want: Allocation{
+				Installments: []InstallmentAllocation{
+					{InstallmentNumber: 1, Fee: 10, Interest: 20, Principal: 100},
+					{InstallmentNumber: 2, Interest: 18, Principal: 2},
+				},
+				Fee:       10,
+				Interest:  38,
+				Principal: 102,
+			},
+		},
+		{
+			name:   "exact payoff",
+			amount: 250,
+			want: Allocation{
+				Installments: []InstallmentAllocation{
+					{InstallmentNumber: 1, Fee: 10, Interest: 20, Principal: 100},
+					{InstallmentNumber: 2, Interest: 18, Principal: 102},
+				},
+				Fee:       10,
+				Interest:  38,
+				Principal: 202,
+			},
+		},
+		{
+			name:   "overpayment is left unapplied",
+			amount: 300.25,
+			want: Allocation{
+				Installments: []InstallmentAllocation{
+					{InstallmentNumber: 1, Fee: 10, Interest: 20, Principal: 100},
+					{InstallmentNumber: 2, Interest: 18, Principal: 102},
+				},
+				Fee:       10,
+				Interest:  38,
+				Principal: 202,
+				Unapplied: 50.25,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Apply(tt.amount, installments); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply(%v) = %+v, want %+v", tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplySkipsSettledInstallments(t *testing.T) {
+	installments := []InstallmentBalance{
+		{InstallmentNumber: 1},
+		{InstallmentNumber: 2, InterestDue: 0, PrincipalDue: 40},
+		{InstallmentNumber: 3, InterestDue: 5, PrincipalDue: 50},
+	}
+
+	want := Allocation{
+		Installments: []InstallmentAllocation{
+			{InstallmentNumber: 2, Principal: 40},
+			{InstallmentNumber: 3, Interest: 5, Principal: 5},
+		},
+		Interest:  5,
+		Principal: 45,
+	}
+
+	if got := Apply(50, installments); !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply(50) = %+v, want %+v", got, want)
+	}
+}
+
+func TestApplyToSettledLoan(t *testing.T) {
+	installments := []InstallmentBalance{
+		{InstallmentNumber: 1},
+		{InstallmentNumber: 2},
+	}
+
+	want := Allocation{Unapplied: 75}
+
+	if got := Apply(75, installments); !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply(75) = %+v, want %+v", got, want)
+	}
+}
+
+func TestApplyRoundsToCents(t *testing.T) {
+	installments := []InstallmentBalance{
+		{InstallmentNumber: 1, InterestDue: 33.33, PrincipalDue: 66.67},
+	}
+
+	got := Apply(100.004, installments)
+
+	if got.Interest != 33.33 || got.Principal != 66.67 || got.Unapplied != 0 {
+		t.Errorf("Apply(100.004) = %+v, want 33.33 interest, 66.67 principal and nothing unapplied", got)
+	}
+}
@@ -0,0 +1,94 @@
+package ledger
+
+import (
+	"time"
+
+	"github.com/alphaloan/vehicle/amortization"
+)
+
+type Payoff struct {
+	AsOf                 time.Time
+	OutstandingPrincipal float64
+	AccruedInterest      float64
+	WaivedInterest       float64
+	OutstandingFees      float64
+	EarlyTerminationFee  float64
+	CreditBalance        float64
+	PayoffAmount         float64
+	Installments         []InstallmentAllocation
+}
+
+// CalculatePayoff returns what it takes to settle a loan at asOf. Interest of
+// installments already due is owed in full, interest of the running period is
+// accrued pro rata by time, and interest of later periods is waived. Interest
+// paid beyond what has accrued is credited, so an installment can add negative
+// interest. The early termination fee is charged on the outstanding principal.
+func CalculatePayoff(installments []InstallmentBalance, asOf time.Time, earlyTerminationFeeRate float64, creditBalance float64) Payoff {
+	payoff := Payoff{
+		AsOf:          asOf,
+		CreditBalance: creditBalance,
+		Installments:  make([]InstallmentAllocation, 0, len(installments)),
+	}
+
+	var periodStart int64
+	for i, installment := range installments {
+		if i == 0 {
+			periodStart = time.Unix(installment.DueDate, 0).AddDate(0, -1, 0).Unix()
+		}
+
+		accruedInterest := accruedInterest(installment, periodStart, asOf.Unix())
+		periodStart = installment.DueDate
+
+		allocation := InstallmentAllocation{
+			InstallmentNumber: installment.InstallmentNumber,
+			Fee:               amortization.Round(installment.FeeDue),
+			Interest:          accruedInterest,
+			Principal:         amortization.Round(installment.PrincipalDue),
+			WaivedInterest:    amortization.Round(installment.InterestDue - accruedInterest),
+		}
+
+		if allocation.Fee <= 0 && allocation.Interest <= 0 && allocation.Principal <= 0 && allocation.WaivedInterest <= 0 {
+			continue
+		}
+
+		payoff.Installments = append(payoff.Installments, allocation)
+		payoff.OutstandingFees = amortization.Round(payoff.OutstandingFees + allocation.Fee)
+		payoff.AccruedInterest = amortization.Round(payoff.AccruedInterest + allocation.Interest)
+		payoff.OutstandingPrincipal = amortization.Round(payoff.OutstandingPrincipal + allocation.Principal)
+		payoff.WaivedInterest = amortization.Round(payoff.WaivedInterest + allocation.WaivedInterest)
+	}
+
+	payoff.EarlyTerminationFee = amortization.Round(payoff.OutstandingPrincipal * earlyTerminationFeeRate)
+	payoff.PayoffAmount = amortization.Round(payoff.Due() - creditBalance)
+
+	if payoff.PayoffAmount < 0 {
+		payoff.PayoffAmount = 0
+	}
+
+	return payoff
+}
+
+// Due returns what settling the loan costs before the credit balance is taken
+// off.
+func (p Payoff) Due() float64 {
+	return amortization.Round(p.OutstandingPrincipal + p.AccruedInterest + p.OutstandingFees + p.EarlyTerminationFee)
+}
+
+// accruedInterest returns the interest of installment owed at asOf less what
+// has already been paid of it. It is negative when interest was paid ahead of
+// time, and that prepaid interest is credited against the payoff.
+func accruedInterest(installment InstallmentBalance, periodStart int64, asOf int64) float64 {
+	if installment.DueDate <= asOf {
+		return amortization.Round(installment.InterestDue)
+	}
+
+	var accrued float64
+	if periodStart < asOf && installment.DueDate > periodStart {
+		elapsed := float64(asOf-periodStart) / float64(installment.DueDate-periodStart)
+		accrued = installment.ScheduledInterest * elapsed
+	}
+
+	paidInterest := installment.ScheduledInterest - installment.InterestDue
+
+	return amortization.Round(accrued - paidInterest)
+}
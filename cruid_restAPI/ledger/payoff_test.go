@@ -0,0 +1,142 @@
+package ledger
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// payoffSchedule returns three monthly installments of 1000 principal and 100
+// interest, due on the first of February, March and April 2025.
+func payoffSchedule() []InstallmentBalance {
+	var installments []InstallmentBalance
+	for i := 1; i <= 3; i++ {
+		installments = append(installments, InstallmentBalance{
+			InstallmentNumber: i,
+			DueDate:           time.Date(2025, time.Month(1+i), 1, 0, 0, 0, 0, time.UTC).Unix(),
+			ScheduledInterest: 100,
+			InterestDue:       100,
+			PrincipalDue:      1000,
+		})
+	}
+
+	return installments
+}
+
+// midFebruary is halfway through the period of the second installment.
+var midFebruary = time.Date(2025, time.February, 15, 0, 0, 0, 0, time.UTC)
+
+func TestCalculatePayoff(t *testing.T) {
+	payoff := CalculatePayoff(payoffSchedule(), midFebruary, 0.02, 0)
+
+	want := Payoff{
+		AsOf:                 midFebruary,
+		OutstandingPrincipal: 3000,
+		AccruedInterest:      150,
+		WaivedInterest:       150,
+		EarlyTerminationFee:  60,
+		PayoffAmount:         3210,
+		Installments: []InstallmentAllocation{
+			{InstallmentNumber: 1, Interest: 100, Principal: 1000},
+			{InstallmentNumber: 2, Interest: 50, Principal: 1000, WaivedInterest: 50},
+			{InstallmentNumber: 3, Principal: 1000, WaivedInterest: 100},
+		},
+	}
+
+	if !reflect.DeepEqual(payoff, want) {
+		t.Errorf("CalculatePayoff() = %+v, want %+v", payoff, want)
+	}
+}
+
+func TestCalculatePayoffCreditsPrepaidInterest(t *testing.T) {
+	installments := payoffSchedule()
+
+	// The first installment is paid, and so is the interest of the second
+	// one, of which only half has accrued by mid February.
+	installments[0].InterestDue, installments[0].PrincipalDue = 0, 0
+	installments[1].InterestDue, installments[1].PrincipalDue = 0, 500
+
+	payoff := CalculatePayoff(installments, midFebruary, 0, 0)
+
+	wantInstallments := []InstallmentAllocation{
+		{InstallmentNumber: 2, Interest: -50, Principal: 500, WaivedInterest: 50},
+		{InstallmentNumber: 3, Principal: 1000, WaivedInterest: 100},
+	}
+
+	if !reflect.DeepEqual(payoff.Installments, wantInstallments) {
+		t.Errorf("installments = %+v, want %+v", payoff.Installments, wantInstallments)
+	}
+
+	if payoff.AccruedInterest != -50 || payoff.PayoffAmount != 1450 {
+		t.Errorf("accrued interest %v and payoff %v, want -50 and 1450", payoff.AccruedInterest, payoff.PayoffAmount)
+	}
+}
+
+func TestCalculatePayoffCreditsInterestPaidForLaterPeriods(t *testing.T) {
+	installments := payoffSchedule()
+	installments[2].InterestDue = 40
+
+	payoff := CalculatePayoff(installments, midFebruary, 0, 0)
+
+	want := InstallmentAllocation{InstallmentNumber: 3, Interest: -60, Principal: 1000, WaivedInterest: 100}
+	if got := payoff.Installments[2]; got != want {
+		t.Errorf("third installment = %+v, want %+v", got, want)
+	}
+
+	if payoff.AccruedInterest != 90 || payoff.PayoffAmount != 3090 {
+		t.Errorf("accrued interest %v and payoff %v, want 90 and 3090", payoff.AccruedInterest, payoff.PayoffAmount)
+	}
+}
+
+func TestCalculatePayoffWithLateFees(t *testing.T) {
+	installments := payoffSchedule()
+	installments[0].FeeDue = 25
+
+	payoff := CalculatePayoff(installments, midFebruary, 0, 0)
+
+	if payoff.OutstandingFees != 25 || payoff.Installments[0].Fee != 25 || payoff.PayoffAmount != 3175 {
+		t.Errorf("fees %v, first installment fee %v and payoff %v, want 25, 25 and 3175",
+			payoff.OutstandingFees, payoff.Installments[0].Fee, payoff.PayoffAmount)
+	}
+}
+
+func TestCalculatePayoffCreditBalance(t *testing.T) {
+	tests := []struct {
+		name          string
+		creditBalance float64
+		wantPayoff    float64
+	}{
+		{"smaller than due", 210, 3000},
+		{"covers the payoff", 5000, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payoff := CalculatePayoff(payoffSchedule(), midFebruary, 0.02, tt.creditBalance)
+
+			if payoff.PayoffAmount != tt.wantPayoff {
+				t.Errorf("payoff = %v, want %v", payoff.PayoffAmount, tt.wantPayoff)
+			}
+
+			if payoff.Due() != 3210 {
+				t.Errorf("due = %v, want 3210 whatever the credit balance", payoff.Due())
+			}
+		})
+	}
+}
+
+func TestCalculatePayoffOfSettledLoan(t *testing.T) {
+	installments := payoffSchedule()
+	for i := range installments {
+		installments[i].InterestDue, installments[i].PrincipalDue = 0, 0
+	}
+
+	// Every installment is paid before any of it is due, so the interest of
+	// the periods that have not started yet is credited.
+	payoff := CalculatePayoff(installments, midFebruary, 0.02, 0)
+
+	if payoff.OutstandingPrincipal != 0 || payoff.AccruedInterest != -150 || payoff.PayoffAmount != 0 {
+		t.Errorf("principal %v, accrued interest %v and payoff %v, want 0, -150 and 0",
+			payoff.OutstandingPrincipal, payoff.AccruedInterest, payoff.PayoffAmount)
+	}
+}
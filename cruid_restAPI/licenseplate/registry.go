@@ -0,0 +1,135 @@
+package licenseplate
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+type UnknownRegionError struct {
+	RegionCode string
+}
+
+func (e *UnknownRegionError) Error() string {
+	return fmt.Sprintf("unknown vehicle region code %q", e.RegionCode)
+}
+
+type FormatError struct {
+	RegionCode string
+	Plate      string
+	Reason     string
+}
+
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("license number %s is not valid in region %s: %s", e.Plate, e.RegionCode, e.Reason)
+}
+
+// Registry holds the validators of every region. Regions are normally loaded
+// from a file, but Register lets code plug in validators of its own.
+type Registry struct {
+	defaultRegionCode string
+	regions           map[string][]Validator
+}
+
+func NewRegistry(defaultRegionCode string) *Registry {
+	return &Registry{
+		defaultRegionCode: defaultRegionCode,
+		regions:           make(map[string][]Validator),
+	}
+}
+
+func (r *Registry) Register(regionCode string, validators ...Validator) {
+	r.regions[regionCode] = append(r.regions[regionCode], validators...)
+}
+
+func (r *Registry) RegionCodes() []string {
+	regionCodes := make([]string, 0, len(r.regions))
+	for regionCode := range r.regions {
+		regionCodes = append(regionCodes, regionCode)
+	}
+	sort.Strings(regionCodes)
+	return regionCodes
+}
+
+// Validate normalizes the plate and checks it against every validator of the
+// region, falling back to the default region when regionCode is empty. It
+// returns the normalized plate and the region it was validated for.
+func (r *Registry) Validate(regionCode string, plate string) (string, string, error) {
+	if regionCode == "" {
+		regionCode = r.defaultRegionCode
+	}
+
+	validators, ok := r.regions[regionCode]
+	if !ok {
+		return "", "", &UnknownRegionError{RegionCode: regionCode}
+	}
+
+	plate = Normalize(plate)
+	if plate == "" {
+		return "", "", &FormatError{RegionCode: regionCode, Plate: plate, Reason: "license number is required"}
+	}
+
+	for _, validator := range validators {
+		if err := validator.Validate(plate); err != nil {
+			return "", "", &FormatError{RegionCode: regionCode, Plate: plate, Reason: err.Error()}
+		}
+	}
+
+	return plate, regionCode, nil
+}
+
+type regionFormat struct {
+	Name     string   `yaml:"name"`
+	Prefixes []string `yaml:"prefixes"`
+	Pattern  string   `yaml:"pattern"`
+}
+
+type registryFile struct {
+	DefaultRegionCode string                  `yaml:"default_region_code"`
+	Regions           map[string]regionFormat `yaml:"regions"`
+}
+
+func LoadRegistry(path string) (*Registry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file registryFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse license plate formats %s: %w", path, err)
+	}
+
+	registry := NewRegistry(file.DefaultRegionCode)
+
+	for regionCode, format := range file.Regions {
+		if format.Pattern == "" && len(format.Prefixes) == 0 {
+			return nil, fmt.Errorf("invalid license plate formats %s: region %s needs a pattern or prefixes", path, regionCode)
+		}
+
+		if format.Pattern != "" {
+			pattern, err := regexp.Compile(format.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid license plate formats %s: region %s: %w", path, regionCode, err)
+			}
+			registry.Register(regionCode, PatternValidator{Pattern: pattern})
+		}
+
+		if len(format.Prefixes) > 0 {
+			prefixes := make([]string, 0, len(format.Prefixes))
+			for _, prefix := range format.Prefixes {
+				prefixes = append(prefixes, Normalize(prefix))
+			}
+			registry.Register(regionCode, PrefixValidator{Prefixes: prefixes})
+		}
+	}
+
+	if _, ok := registry.regions[registry.defaultRegionCode]; registry.defaultRegionCode != "" && !ok {
+		return nil, fmt.Errorf("invalid license plate formats %s: default region %s is not configured", path, registry.defaultRegionCode)
+	}
+
+	return registry, nil
+}
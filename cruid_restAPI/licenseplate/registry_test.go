@@ -0,0 +1,144 @@
+package licenseplate
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRegistryValidate(t *testing.T) {
+	registry := NewRegistry("JKT")
+	registry.Register("JKT", PrefixValidator{Prefixes: []string{"B"}})
+	registry.Register("DPS", PrefixValidator{Prefixes: []string{"DK"}}, PatternValidator{Pattern: regexp.MustCompile(`^[A-Z]{1,2}[0-9]{1,4}[A-Z]{0,3}$`)})
+
+	tests := []struct {
+		name       string
+		regionCode string
+		plate      string
+		wantPlate  string
+		wantRegion string
+		wantErr    error
+	}{
+		{"normalized before it is checked", "JKT", " b 1234 xyz ", "B1234XYZ", "JKT", nil},
+		{"default region", "", "B1234XYZ", "B1234XYZ", "JKT", nil},
+		{"every validator of the region", "DPS", "dk 1234 ab", "DK1234AB", "DPS", nil},
+		{"unknown region", "XXX", "B1234XYZ", "", "", &UnknownRegionError{}},
+		{"region codes are not normalized", "jkt", "B1234XYZ", "", "", &UnknownRegionError{}},
+		{"plate of another region", "JKT", "DK1234AB", "", "", &FormatError{}},
+		{"failing the second validator", "DPS", "DK12345AB", "", "", &FormatError{}},
+		{"only whitespace", "JKT", " \t ", "", "", &FormatError{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plate, regionCode, err := registry.Validate(tt.regionCode, tt.plate)
+
+			if plate != tt.wantPlate || regionCode != tt.wantRegion {
+				t.Errorf("Validate() = %q, %q, want %q, %q", plate, regionCode, tt.wantPlate, tt.wantRegion)
+			}
+
+			switch tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+			case *UnknownRegionError:
+				var unknownRegionErr *UnknownRegionError
+				if !errors.As(err, &unknownRegionErr) || unknownRegionErr.RegionCode != tt.regionCode {
+					t.Errorf("Validate() error = %v, want an UnknownRegionError for %q", err, tt.regionCode)
+				}
+			case *FormatError:
+				var formatErr *FormatError
+				if !errors.As(err, &formatErr) || formatErr.RegionCode != tt.regionCode || formatErr.Reason == "" {
+					t.Errorf("Validate() error = %v, want a FormatError for %q with a reason", err, tt.regionCode)
+				}
+			}
+		})
+	}
+}
+
+func writeRegistryFile(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "license_plate_formats.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write license plate formats: %v", err)
+	}
+
+	return path
+}
+
+func TestLoadRegistry(t *testing.T) {
+	path := writeRegistryFile(t, `
+default_region_code: JKT
+regions:
+  JKT:
+    prefixes: [b]
+  DPS:
+    prefixes: [" dk "]
+    pattern: '^[A-Z]{1,2}[0-9]{1,4}[A-Z]{0,3}$'
+  CUSTOM:
+    pattern: '^X[0-9]+$'
+`)
+
+	registry, err := LoadRegistry(path)
+	if err != nil {
+		t.Fatalf("LoadRegistry() = %v", err)
+	}
+
+	if got, want := registry.RegionCodes(), []string{"CUSTOM", "DPS", "JKT"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("RegionCodes() = %v, want %v", got, want)
+	}
+
+	// Prefixes are normalized like the plates they are compared with.
+	valid := [][2]string{{"", "b 1234 xyz"}, {"DPS", "DK1234AB"}, {"CUSTOM", "x 42"}}
+	for _, tt := range valid {
+		if _, _, err := registry.Validate(tt[0], tt[1]); err != nil {
+			t.Errorf("Validate(%q, %q) = %v, want nil", tt[0], tt[1], err)
+		}
+	}
+
+	invalid := [][2]string{{"DPS", "DK12345AB"}, {"DPS", "B1234XYZ"}, {"CUSTOM", "Y42"}}
+	for _, tt := range invalid {
+		if _, _, err := registry.Validate(tt[0], tt[1]); err == nil {
+			t.Errorf("Validate(%q, %q) = nil, want an error", tt[0], tt[1])
+		}
+	}
+}
+
+func TestLoadRegistryErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"bad regex", "regions:\n  JKT:\n    pattern: '^[A-Z'\n", "region JKT"},
+		{"region without a format", "regions:\n  JKT:\n    name: Jakarta\n", "region JKT needs a pattern or prefixes"},
+		{"default region not configured", "default_region_code: BDG\nregions:\n  JKT:\n    prefixes: [B]\n", "default region BDG is not configured"},
+		{"not yaml", "regions: [", "failed to parse license plate formats"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadRegistry(writeRegistryFile(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadRegistry() = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadRegistryOfThePolicyFile(t *testing.T) {
+	registry, err := LoadRegistry(filepath.Join("..", "policy", "license_plate_formats.yaml"))
+	if err != nil {
+		t.Fatalf("LoadRegistry() = %v", err)
+	}
+
+	if plate, regionCode, err := registry.Validate("", "b 1234 cd"); err != nil || plate != "B1234CD" || regionCode != "JKT" {
+		t.Errorf("Validate() = %q, %q, %v, want B1234CD in JKT", plate, regionCode, err)
+	}
+}
@@ -0,0 +1,53 @@
+package licenseplate
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type Validator interface {
+	Validate(plate string) error
+}
+
+// Normalize upper-cases a plate and strips all whitespace, so "b 1234 xyz"
+// and "B1234XYZ" are the same vehicle.
+func Normalize(plate string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, plate)
+}
+
+type PatternValidator struct {
+	Pattern *regexp.Regexp
+}
+
+func (v PatternValidator) Validate(plate string) error {
+	if !v.Pattern.MatchString(plate) {
+		return fmt.Errorf("does not match the format %s", v.Pattern.String())
+	}
+	return nil
+}
+
+// PrefixValidator checks the letters a plate starts with against the region's
+// codes. The whole leading run of letters must match, so the prefix B does not
+// accept the plate BA1234.
+type PrefixValidator struct {
+	Prefixes []string
+}
+
+func (v PrefixValidator) Validate(plate string) error {
+	prefix := plate[:len(plate)-len(strings.TrimLeftFunc(plate, unicode.IsLetter))]
+
+	for _, allowed := range v.Prefixes {
+		if prefix == allowed {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("must start with %s", strings.Join(v.Prefixes, " or "))
+}
@@ -0,0 +1,59 @@
+package licenseplate
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		plate string
+		want  string
+	}{
+		{"B1234XYZ", "B1234XYZ"},
+		{"b 1234 xyz", "B1234XYZ"},
+		{" b\t1234\nxyz ", "B1234XYZ"},
+		{"b\u00a01234\u3000xyz", "B1234XYZ"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.plate); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.plate, got, tt.want)
+		}
+	}
+}
+
+func TestPatternValidator(t *testing.T) {
+	validator := PatternValidator{Pattern: regexp.MustCompile(`^[A-Z]{1,2}[0-9]{1,4}[A-Z]{0,3}$`)}
+
+	for _, plate := range []string{"B1", "B1234XYZ", "DK1234AB"} {
+		if err := validator.Validate(plate); err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", plate, err)
+		}
+	}
+
+	for _, plate := range []string{"1234XYZ", "B12345XYZ", "B1234WXYZ", "ABC1234"} {
+		if err := validator.Validate(plate); err == nil {
+			t.Errorf("Validate(%q) = nil, want an error", plate)
+		}
+	}
+}
+
+func TestPrefixValidator(t *testing.T) {
+	validator := PrefixValidator{Prefixes: []string{"B", "DK"}}
+
+	for _, plate := range []string{"B1234XYZ", "DK1234AB"} {
+		if err := validator.Validate(plate); err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", plate, err)
+		}
+	}
+
+	// The whole leading run of letters is the prefix, so B does not accept BA
+	// and DK does not accept D.
+	for _, plate := range []string{"BA1234XYZ", "D1234AB", "1234"} {
+		if err := validator.Validate(plate); err == nil {
+			t.Errorf("Validate(%q) = nil, want an error", plate)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package loanparty
+
+const (
+	Primary     = "PRIMARY"
+	CoApplicant = "CO_APPLICANT"
+	Guarantor   = "GUARANTOR"
+)
+
+func IsValidRole(role string) bool {
+	return role == Primary || role == CoApplicant || role == Guarantor
+}
+
+// ContributesIncome reports whether the party's income counts towards the
+// household income used for affordability. A guarantor only steps in when the
+// borrowers default, so their income is not counted.
+func ContributesIncome(role string) bool {
+	return role == Primary || role == CoApplicant
+}
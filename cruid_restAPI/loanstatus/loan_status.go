@@ -0,0 +1,87 @@
+package loanstatus
+
+import "fmt"
+
+const (
+	New         = "NEW"
+	UnderReview = "UNDER_REVIEW"
+	Approved    = "APPROVED"
+	Rejected    = "REJECTED"
+	Disbursed   = "DISBURSED"
+	Closed      = "CLOSED"
+	Cancelled   = "CANCELLED"
+)
+
+var transitions = map[string][]string{
+	New:         {UnderReview, Cancelled},
+	UnderReview: {Approved, Rejected, Cancelled},
+	Approved:    {Disbursed, Cancelled},
+	Rejected:    {Closed},
+	Disbursed:   {Closed},
+	Closed:      {},
+	Cancelled:   {},
+}
+
+type TransitionError struct {
+	From    string
+	To      string
+	Allowed []string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("illegal loan status transition from %s to %s", e.From, e.To)
+}
+
+func IsValid(status string) bool {
+	_, ok := transitions[status]
+	return ok
+}
+
+func AllowedTransitions(from string) []string {
+	allowed := make([]string, 0, len(transitions[from]))
+	return append(allowed, transitions[from]...)
+}
+
+func ValidateTransition(from, to string) error {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+
+	return &TransitionError{
+		From:    from,
+		To:      to,
+		Allowed: AllowedTransitions(from),
+	}
+}
+
+// ReleasesCollateral reports whether a loan in this status no longer secures
+// its vehicle. A rejected loan can only be closed, so its vehicle is released
+// straight away rather than waiting for the clean-up transition.
+func ReleasesCollateral(status string) bool {
+	return status == Rejected || status == Closed || status == Cancelled
+}
+
+// GeneratesSchedule reports whether a loan entering this status gets its
+// installment schedule generated. The schedule of an approved loan is only a
+// preview: it is generated again at disbursement, so the installments fall
+// due a month after the money was paid out rather than after the approval.
+func GeneratesSchedule(status string) bool {
+	return status == Approved || status == Disbursed
+}
+
+// ClosedBySettlement reports whether the transition closes a disbursed loan.
+// Only a settlement may do that, as it checks the payoff and records the final
+// payment before the collateral is released; a plain status change would
+// leave the outstanding balance unpaid and drop the loan from collections.
+func ClosedBySettlement(from, to string) bool {
+	return from == Disbursed && to == Closed
+}
+
+// DocumentsDeletable reports whether the documents of a loan in this status
+// may still be deleted. Past UNDER_REVIEW the documents are the record the
+// loan was decided on, and the checklist that gated its approval.
+func DocumentsDeletable(status string) bool {
+	return status == New || status == UnderReview
+}
@@ -0,0 +1,137 @@
+package loanstatus
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+var statuses = []string{New, UnderReview, Approved, Rejected, Disbursed, Closed, Cancelled}
+
+func TestValidateTransition(t *testing.T) {
+	legal := map[[2]string]bool{
+		{New, UnderReview}:       true,
+		{New, Cancelled}:         true,
+		{UnderReview, Approved}:  true,
+		{UnderReview, Rejected}:  true,
+		{UnderReview, Cancelled}: true,
+		{Approved, Disbursed}:    true,
+		{Approved, Cancelled}:    true,
+		{Rejected, Closed}:       true,
+		{Disbursed, Closed}:      true,
+	}
+
+	// Every pair of statuses, a status to itself and the terminal CLOSED and
+	// CANCELLED included, is legal exactly when it is an edge above.
+	for _, from := range statuses {
+		for _, to := range statuses {
+			err := ValidateTransition(from, to)
+
+			if legal[[2]string{from, to}] {
+				if err != nil {
+					t.Errorf("ValidateTransition(%s, %s) = %v, want nil", from, to, err)
+				}
+				continue
+			}
+
+			var transitionErr *TransitionError
+			if !errors.As(err, &transitionErr) {
+				t.Errorf("ValidateTransition(%s, %s) = %v, want a *TransitionError", from, to, err)
+				continue
+			}
+
+			if transitionErr.From != from || transitionErr.To != to || !slices.Equal(transitionErr.Allowed, AllowedTransitions(from)) {
+				t.Errorf("ValidateTransition(%s, %s) = %+v, want the statuses and the allowed transitions", from, to, transitionErr)
+			}
+		}
+	}
+}
+
+func TestValidateTransitionOfUnknownStatus(t *testing.T) {
+	if err := ValidateTransition("PENDING", New); err == nil {
+		t.Error("ValidateTransition(PENDING, NEW) = nil, want an error")
+	}
+
+	if err := ValidateTransition(New, "PENDING"); err == nil {
+		t.Error("ValidateTransition(NEW, PENDING) = nil, want an error")
+	}
+}
+
+func TestCancelledIsTerminal(t *testing.T) {
+	if allowed := AllowedTransitions(Cancelled); len(allowed) != 0 {
+		t.Errorf("AllowedTransitions(CANCELLED) = %v, want none", allowed)
+	}
+
+	for _, from := range []string{New, UnderReview, Approved} {
+		if err := ValidateTransition(from, Cancelled); err != nil {
+			t.Errorf("ValidateTransition(%s, CANCELLED) = %v, want nil", from, err)
+		}
+	}
+
+	for _, from := range []string{Rejected, Disbursed, Closed} {
+		if err := ValidateTransition(from, Cancelled); err == nil {
+			t.Errorf("ValidateTransition(%s, CANCELLED) = nil, want an error", from)
+		}
+	}
+}
+
+func TestAllowedTransitionsReturnsACopy(t *testing.T) {
+	allowed := AllowedTransitions(New)
+	allowed[0] = Closed
+
+	if err := ValidateTransition(New, UnderReview); err != nil {
+		t.Errorf("changing the result of AllowedTransitions changed the table: %v", err)
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	for _, status := range statuses {
+		if !IsValid(status) {
+			t.Errorf("IsValid(%s) = false, want true", status)
+		}
+	}
+
+	for _, status := range []string{"", "new", "PENDING"} {
+		if IsValid(status) {
+			t.Errorf("IsValid(%q) = true, want false", status)
+		}
+	}
+}
+
+func TestReleasesCollateral(t *testing.T) {
+	for _, status := range statuses {
+		want := status == Rejected || status == Closed || status == Cancelled
+		if got := ReleasesCollateral(status); got != want {
+			t.Errorf("ReleasesCollateral(%s) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestGeneratesSchedule(t *testing.T) {
+	for _, status := range statuses {
+		want := status == Approved || status == Disbursed
+		if got := GeneratesSchedule(status); got != want {
+			t.Errorf("GeneratesSchedule(%s) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestDocumentsDeletable(t *testing.T) {
+	for _, status := range statuses {
+		want := status == New || status == UnderReview
+		if got := DocumentsDeletable(status); got != want {
+			t.Errorf("DocumentsDeletable(%s) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestClosedBySettlement(t *testing.T) {
+	for _, from := range statuses {
+		for _, to := range statuses {
+			want := from == Disbursed && to == Closed
+			if got := ClosedBySettlement(from, to); got != want {
+				t.Errorf("ClosedBySettlement(%s, %s) = %v, want %v", from, to, got, want)
+			}
+		}
+	}
+}
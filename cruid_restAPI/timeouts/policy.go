@@ -0,0 +1,48 @@
+package timeouts
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Policy holds how long a request may spend on an operation before it is
+// cancelled. Operations without their own entry use the default.
+type Policy struct {
+	Default    time.Duration            `yaml:"default"`
+	Operations map[string]time.Duration `yaml:"operations"`
+}
+
+func LoadPolicy(path string) (Policy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	var policy Policy
+	if err := yaml.Unmarshal(content, &policy); err != nil {
+		return Policy{}, fmt.Errorf("failed to parse timeout policy %s: %w", path, err)
+	}
+
+	if policy.Default <= 0 {
+		return Policy{}, fmt.Errorf("invalid timeout policy %s: default must be greater than zero", path)
+	}
+
+	for operation, timeout := range policy.Operations {
+		if timeout <= 0 {
+			return Policy{}, fmt.Errorf("invalid timeout policy %s: timeout of %s must be greater than zero", path, operation)
+		}
+	}
+
+	return policy, nil
+}
+
+func (p Policy) For(operation string) time.Duration {
+	if timeout, ok := p.Operations[operation]; ok {
+		return timeout
+	}
+
+	return p.Default
+}
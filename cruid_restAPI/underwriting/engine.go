@@ -0,0 +1,98 @@
+package underwriting
+
+import "time"
+
+const (
+	Approve = "APPROVE"
+	Refer   = "REFER"
+	Decline = "DECLINE"
+)
+
+const (
+	Pass = "PASS"
+)
+
+type Application struct {
+	MonthlyIncome       float64
+	CoApplicantIncome   float64
+	ProposedLoanAmount  int
+	ProposedLoanTenure  int
+	AnnualInterestRate  float64
+	InterestMethod      string
+	ManufacturingYear   int
+	VehicleOdometer     int
+	VehicleType         string
+	VehicleBrand        string
+	IsCommercialVehicle bool
+	CollateralValue     float64
+	LoanToValue         float64
+	EvaluatedAt         time.Time
+}
+
+// HouseholdIncome is the income affordability is assessed on: the primary
+// applicant's plus that of any co-applicants.
+func (a Application) HouseholdIncome() float64 {
+	return a.MonthlyIncome + a.CoApplicantIncome
+}
+
+type RuleResult struct {
+	Rule    string
+	Outcome string
+	Reason  string
+}
+
+type Evaluation struct {
+	PolicyVersion  int
+	Recommendation string
+	Results        []RuleResult
+}
+
+type Engine struct {
+	policies PolicySource
+	rules    []Rule
+}
+
+func NewEngine(policies PolicySource, rules ...Rule) *Engine {
+	return &Engine{
+		policies: policies,
+		rules:    rules,
+	}
+}
+
+// Policy returns the current policy. A caller that also needs the policy for
+// something other than Evaluate, such as the defaults of a submission, takes
+// it once and passes the same one to Evaluate, so a reload in between cannot
+// mix two versions.
+func (e *Engine) Policy() Policy {
+	return e.policies.Current()
+}
+
+// Evaluate runs the rules of e against application under policy.
+func (e *Engine) Evaluate(policy Policy, application Application) Evaluation {
+	if application.EvaluatedAt.IsZero() {
+		application.EvaluatedAt = time.Now()
+	}
+
+	results := make([]RuleResult, 0, len(e.rules))
+	recommendation := Approve
+
+	for _, rule := range e.rules {
+		result := rule.Evaluate(policy, application)
+		results = append(results, result)
+
+		switch result.Outcome {
+		case Decline:
+			recommendation = Decline
+		case Refer:
+			if recommendation == Approve {
+				recommendation = Refer
+			}
+		}
+	}
+
+	return Evaluation{
+		PolicyVersion:  policy.Version,
+		Recommendation: recommendation,
+		Results:        results,
+	}
+}
@@ -0,0 +1,134 @@
+package underwriting
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alphaloan/vehicle/amortization"
+	"gopkg.in/yaml.v3"
+)
+
+type Policy struct {
+	Version                        int            `json:"version" yaml:"version"`
+	ReferInstallmentToIncomeRatio  float64        `json:"refer_installment_to_income_ratio" yaml:"refer_installment_to_income_ratio"`
+	MaxInstallmentToIncomeRatio    float64        `json:"max_installment_to_income_ratio" yaml:"max_installment_to_income_ratio"`
+	MaxVehicleAgeYears             int            `json:"max_vehicle_age_years" yaml:"max_vehicle_age_years"`
+	ReferOdometer                  int            `json:"refer_odometer" yaml:"refer_odometer"`
+	MaxOdometer                    int            `json:"max_odometer" yaml:"max_odometer"`
+	MaxCommercialLoanAmount        int            `json:"max_commercial_loan_amount" yaml:"max_commercial_loan_amount"`
+	MaxCommercialTenureMonths      int            `json:"max_commercial_tenure_months" yaml:"max_commercial_tenure_months"`
+	CommercialInstallmentSurcharge float64        `json:"commercial_installment_surcharge" yaml:"commercial_installment_surcharge"`
+	MaxTenureMonthsByVehicleType   map[string]int `json:"max_tenure_months_by_vehicle_type" yaml:"max_tenure_months_by_vehicle_type"`
+	MaxLoanAmountByBrand           map[string]int `json:"max_loan_amount_by_brand" yaml:"max_loan_amount_by_brand"`
+	DefaultAnnualInterestRate      float64        `json:"default_annual_interest_rate" yaml:"default_annual_interest_rate"`
+	DefaultInterestMethod          string         `json:"default_interest_method" yaml:"default_interest_method"`
+	ReferLoanToValue               float64        `json:"refer_loan_to_value" yaml:"refer_loan_to_value"`
+	MaxLoanToValue                 float64        `json:"max_loan_to_value" yaml:"max_loan_to_value"`
+}
+
+func LoadPolicy(path string) (Policy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, err
+	}
+
+	var policy Policy
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &policy)
+	case ".json":
+		err = json.Unmarshal(content, &policy)
+	default:
+		return Policy{}, fmt.Errorf("unsupported policy file format: %s", path)
+	}
+
+	if err != nil {
+		return Policy{}, fmt.Errorf("failed to parse policy file %s: %w", path, err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return Policy{}, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+
+	return policy, nil
+}
+
+func (p Policy) Validate() error {
+	if p.Version <= 0 {
+		return fmt.Errorf("version must be greater than zero")
+	}
+
+	if p.MaxInstallmentToIncomeRatio <= 0 {
+		return fmt.Errorf("max_installment_to_income_ratio must be greater than zero")
+	}
+
+	if p.ReferInstallmentToIncomeRatio <= 0 || p.ReferInstallmentToIncomeRatio > p.MaxInstallmentToIncomeRatio {
+		return fmt.Errorf("refer_installment_to_income_ratio must be between zero and max_installment_to_income_ratio")
+	}
+
+	if p.MaxLoanToValue <= 0 {
+		return fmt.Errorf("max_loan_to_value must be greater than zero")
+	}
+
+	if p.ReferLoanToValue <= 0 || p.ReferLoanToValue > p.MaxLoanToValue {
+		return fmt.Errorf("refer_loan_to_value must be between zero and max_loan_to_value")
+	}
+
+	if p.MaxVehicleAgeYears <= 0 {
+		return fmt.Errorf("max_vehicle_age_years must be greater than zero")
+	}
+
+	if p.MaxOdometer <= 0 {
+		return fmt.Errorf("max_odometer must be greater than zero")
+	}
+
+	if p.ReferOdometer > p.MaxOdometer {
+		return fmt.Errorf("refer_odometer must not exceed max_odometer")
+	}
+
+	if p.MaxCommercialLoanAmount <= 0 {
+		return fmt.Errorf("max_commercial_loan_amount must be greater than zero")
+	}
+
+	if p.MaxCommercialTenureMonths <= 0 {
+		return fmt.Errorf("max_commercial_tenure_months must be greater than zero")
+	}
+
+	if p.CommercialInstallmentSurcharge < 0 {
+		return fmt.Errorf("commercial_installment_surcharge must not be negative")
+	}
+
+	if p.DefaultAnnualInterestRate < 0 {
+		return fmt.Errorf("default_annual_interest_rate must not be negative")
+	}
+
+	if !amortization.IsValidMethod(p.DefaultInterestMethod) {
+		return fmt.Errorf("default_interest_method must be %s or %s", amortization.Flat, amortization.Effective)
+	}
+
+	return nil
+}
+
+func (p Policy) MaxTenureMonthsFor(vehicleType string) (int, bool) {
+	for policyVehicleType, maxTenure := range p.MaxTenureMonthsByVehicleType {
+		if strings.EqualFold(policyVehicleType, vehicleType) {
+			return maxTenure, true
+		}
+	}
+
+	return 0, false
+}
+
+func (p Policy) MaxLoanAmountFor(vehicleBrand string) (int, bool) {
+	for policyVehicleBrand, maxAmount := range p.MaxLoanAmountByBrand {
+		if strings.EqualFold(policyVehicleBrand, vehicleBrand) {
+			return maxAmount, true
+		}
+	}
+
+	return 0, false
+}
@@ -0,0 +1,37 @@
+package underwriting
+
+import "testing"
+
+func TestPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Policy)
+		wantErr bool
+	}{
+		{"valid policy", func(p *Policy) {}, false},
+		{"no version", func(p *Policy) { p.Version = 0 }, true},
+		{"no maximum installment to income ratio", func(p *Policy) { p.MaxInstallmentToIncomeRatio = 0 }, true},
+		{"refer ratio above the maximum", func(p *Policy) { p.ReferInstallmentToIncomeRatio = 0.5 }, true},
+		{"no maximum loan to value", func(p *Policy) { p.MaxLoanToValue = 0 }, true},
+		{"refer loan to value above the maximum", func(p *Policy) { p.ReferLoanToValue = 1.1 }, true},
+		{"no maximum vehicle age", func(p *Policy) { p.MaxVehicleAgeYears = 0 }, true},
+		{"no maximum odometer", func(p *Policy) { p.ReferOdometer, p.MaxOdometer = 0, 0 }, true},
+		{"refer odometer above the maximum", func(p *Policy) { p.ReferOdometer = 150001 }, true},
+		{"no maximum commercial loan amount", func(p *Policy) { p.MaxCommercialLoanAmount = 0 }, true},
+		{"no maximum commercial tenure", func(p *Policy) { p.MaxCommercialTenureMonths = 0 }, true},
+		{"negative commercial surcharge", func(p *Policy) { p.CommercialInstallmentSurcharge = -0.1 }, true},
+		{"negative default interest rate", func(p *Policy) { p.DefaultAnnualInterestRate = -1 }, true},
+		{"unknown default interest method", func(p *Policy) { p.DefaultInterestMethod = "BALLOON" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := testPolicy()
+			tt.modify(&policy)
+
+			if err := policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want an error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
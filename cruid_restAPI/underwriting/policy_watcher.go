@@ -0,0 +1,116 @@
+package underwriting
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+type PolicySource interface {
+	Current() Policy
+}
+
+type StaticPolicy Policy
+
+func (p StaticPolicy) Current() Policy {
+	return Policy(p)
+}
+
+type PolicyWatcher struct {
+	path          string
+	mu            sync.RWMutex
+	policy        Policy
+	modTime       time.Time
+	failedModTime time.Time
+}
+
+func NewPolicyWatcher(path string) (*PolicyWatcher, error) {
+	watcher := &PolicyWatcher{
+		path: path,
+	}
+
+	if err := watcher.Reload(); err != nil {
+		return nil, err
+	}
+
+	return watcher, nil
+}
+
+func (w *PolicyWatcher) Current() Policy {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.policy
+}
+
+// Reload loads the policy file. Once a policy is loaded, a new one must carry
+// a higher version, as the version is what records which policy evaluated a
+// submission. A file that fails to load is remembered by its modification
+// time, so it is not retried until it changes again.
+func (w *PolicyWatcher) Reload() error {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return err
+	}
+
+	policy, err := LoadPolicy(w.path)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err == nil && w.policy.Version != 0 && policy.Version <= w.policy.Version {
+		err = fmt.Errorf("policy file %s has version %d, which must be greater than the loaded version %d", w.path, policy.Version, w.policy.Version)
+	}
+
+	if err != nil {
+		w.failedModTime = info.ModTime()
+		return err
+	}
+
+	w.policy = policy
+	w.modTime = info.ModTime()
+
+	return nil
+}
+
+// changed reports whether a policy file modified at modTime is neither the
+// one loaded nor one that already failed to load.
+func (w *PolicyWatcher) changed(modTime time.Time) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return !modTime.Equal(w.modTime) && !modTime.Equal(w.failedModTime)
+}
+
+// Watch polls the policy file and reloads it whenever its modification time
+// changes. A file that fails to load keeps the previous policy in place.
+func (w *PolicyWatcher) Watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			info, err := os.Stat(w.path)
+			if err != nil {
+				log.Printf("Failed to stat underwriting policy %s: %v", w.path, err)
+				continue
+			}
+
+			if !w.changed(info.ModTime()) {
+				continue
+			}
+
+			if err := w.Reload(); err != nil {
+				log.Printf("Failed to reload underwriting policy %s: %v", w.path, err)
+				continue
+			}
+
+			log.Printf("Underwriting policy version %d loaded from %s", w.Current().Version, w.path)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package underwriting
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// writePolicyFile writes policy to path and sets its modification time, so
+// every write is seen as a change however fast the test runs.
+func writePolicyFile(t *testing.T, path string, policy Policy, modTime time.Time) {
+	t.Helper()
+
+	content, err := yaml.Marshal(policy)
+	if err != nil {
+		t.Fatalf("failed to marshal policy: %v", err)
+	}
+
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatalf("failed to write policy file: %v", err)
+	}
+
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("failed to set the modification time: %v", err)
+	}
+}
+
+func TestPolicyWatcherReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "underwriting_policy.yaml")
+	start := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	writePolicyFile(t, path, testPolicy(), start)
+
+	watcher, err := NewPolicyWatcher(path)
+	if err != nil {
+		t.Fatalf("NewPolicyWatcher: %v", err)
+	}
+
+	// The same version with other thresholds is rejected and keeps the
+	// loaded policy, and is not retried until the file changes again.
+	sameVersion := testPolicy()
+	sameVersion.MaxOdometer = 200000
+	failedModTime := start.Add(time.Minute)
+	writePolicyFile(t, path, sameVersion, failedModTime)
+
+	if !watcher.changed(failedModTime) {
+		t.Fatal("changed() = false for a modified file")
+	}
+
+	if err := watcher.Reload(); err == nil {
+		t.Fatal("Reload() of a policy with the same version = nil, want an error")
+	}
+
+	if got := watcher.Current(); got.MaxOdometer != testPolicy().MaxOdometer {
+		t.Errorf("Current().MaxOdometer = %d after a failed reload, want %d", got.MaxOdometer, testPolicy().MaxOdometer)
+	}
+
+	if watcher.changed(failedModTime) {
+		t.Error("changed() = true for the file that failed to load, want it skipped until it changes")
+	}
+
+	invalid := testPolicy()
+	invalid.Version, invalid.MaxOdometer = 4, 0
+	writePolicyFile(t, path, invalid, start.Add(2*time.Minute))
+
+	if err := watcher.Reload(); err == nil {
+		t.Fatal("Reload() of an invalid policy = nil, want an error")
+	}
+
+	newVersion := testPolicy()
+	newVersion.Version, newVersion.MaxOdometer = 4, 200000
+	loadedModTime := start.Add(3 * time.Minute)
+	writePolicyFile(t, path, newVersion, loadedModTime)
+
+	if !watcher.changed(loadedModTime) {
+		t.Fatal("changed() = false for a file changed after a failed reload")
+	}
+
+	if err := watcher.Reload(); err != nil {
+		t.Fatalf("Reload() of a higher version: %v", err)
+	}
+
+	if got := watcher.Current(); got.Version != 4 || got.MaxOdometer != 200000 {
+		t.Errorf("Current() = version %d, max odometer %d, want version 4, max odometer 200000", got.Version, got.MaxOdometer)
+	}
+
+	if watcher.changed(loadedModTime) {
+		t.Error("changed() = true for the loaded file")
+	}
+}
@@ -0,0 +1,295 @@
+package underwriting
+
+import (
+	"fmt"
+
+	"github.com/alphaloan/vehicle/amortization"
+)
+
+type Rule interface {
+	Name() string
+	Evaluate(policy Policy, application Application) RuleResult
+}
+
+func DefaultRules() []Rule {
+	return []Rule{
+		InstallmentToIncomeRule{},
+		VehicleAgeRule{},
+		OdometerRule{},
+		CommercialVehicleRule{},
+		TenureByVehicleTypeRule{},
+		LoanAmountByBrandRule{},
+		LoanToValueRule{},
+	}
+}
+
+func MonthlyInstallment(application Application) float64 {
+	if application.ProposedLoanTenure <= 0 {
+		return 0
+	}
+
+	payment, err := amortization.MonthlyPayment(
+		float64(application.ProposedLoanAmount),
+		application.AnnualInterestRate,
+		application.ProposedLoanTenure,
+		application.InterestMethod,
+	)
+	if err != nil {
+		return float64(application.ProposedLoanAmount) / float64(application.ProposedLoanTenure)
+	}
+
+	return payment
+}
+
+type InstallmentToIncomeRule struct{}
+
+func (InstallmentToIncomeRule) Name() string {
+	return "installment_to_income"
+}
+
+func (r InstallmentToIncomeRule) Evaluate(policy Policy, application Application) RuleResult {
+	if application.ProposedLoanTenure <= 0 {
+		return RuleResult{
+			Rule:    r.Name(),
+			Outcome: Decline,
+			Reason:  "proposed loan tenure must be greater than zero",
+		}
+	}
+
+	if application.HouseholdIncome() <= 0 {
+		return RuleResult{
+			Rule:    r.Name(),
+			Outcome: Refer,
+			Reason:  "household income is unknown and affordability needs manual review",
+		}
+	}
+
+	installment := MonthlyInstallment(application)
+	if application.IsCommercialVehicle {
+		installment += installment * policy.CommercialInstallmentSurcharge
+	}
+
+	ratio := installment / application.HouseholdIncome()
+
+	if ratio > policy.MaxInstallmentToIncomeRatio {
+		return RuleResult{
+			Rule:    r.Name(),
+			Outcome: Decline,
+			Reason:  fmt.Sprintf("installment to household income ratio %.2f exceeds maximum %.2f", ratio, policy.MaxInstallmentToIncomeRatio),
+		}
+	}
+
+	if ratio > policy.ReferInstallmentToIncomeRatio {
+		return RuleResult{
+			Rule:    r.Name(),
+			Outcome: Refer,
+			Reason:  fmt.Sprintf("installment to household income ratio %.2f exceeds %.2f and needs manual review", ratio, policy.ReferInstallmentToIncomeRatio),
+		}
+	}
+
+	return RuleResult{
+		Rule:    r.Name(),
+		Outcome: Pass,
+		Reason:  fmt.Sprintf("installment to household income ratio %.2f is within policy", ratio),
+	}
+}
+
+type VehicleAgeRule struct{}
+
+func (VehicleAgeRule) Name() string {
+	return "vehicle_age"
+}
+
+func (r VehicleAgeRule) Evaluate(policy Policy, application Application) RuleResult {
+	age := application.EvaluatedAt.Year() - application.ManufacturingYear
+
+	if age < 0 {
+		return RuleResult{
+			Rule:    r.Name(),
+			Outcome: Decline,
+			Reason:  fmt.Sprintf("manufacturing year %d is in the future", application.ManufacturingYear),
+		}
+	}
+
+	if age > policy.MaxVehicleAgeYears {
+		return RuleResult{
+			Rule:    r.Name(),
+			Outcome: Decline,
+			Reason:  fmt.Sprintf("vehicle age %d years exceeds maximum %d years", age, policy.MaxVehicleAgeYears),
+		}
+	}
+
+	return RuleResult{
+		Rule:    r.Name(),
+		Outcome: Pass,
+		Reason:  fmt.Sprintf("vehicle age %d years is within policy", age),
+	}
+}
+
+type OdometerRule struct{}
+
+func (OdometerRule) Name() string {
+	return "vehicle_odometer"
+}
+
+func (r OdometerRule) Evaluate(policy Policy, application Application) RuleResult {
+	if application.VehicleOdometer > policy.MaxOdometer {
+		return RuleResult{
+			Rule:    r.Name(),
+			Outcome: Decline,
+			Reason:  fmt.Sprintf("odometer %d exceeds maximum %d", application.VehicleOdometer, policy.MaxOdometer),
+		}
+	}
+
+	if application.VehicleOdometer > policy.ReferOdometer {
+		return RuleResult{
+			Rule:    r.Name(),
+			Outcome: Refer,
+			Reason:  fmt.Sprintf("odometer %d exceeds %d and needs manual review", application.VehicleOdometer, policy.ReferOdometer),
+		}
+	}
+
+	return RuleResult{
+		Rule:    r.Name(),
+		Outcome: Pass,
+		Reason:  fmt.Sprintf("odometer %d is within policy", application.VehicleOdometer),
+	}
+}
+
+type CommercialVehicleRule struct{}
+
+func (CommercialVehicleRule) Name() string {
+	return "commercial_vehicle"
+}
+
+func (r CommercialVehicleRule) Evaluate(policy Policy, application Application) RuleResult {
+	if !application.IsCommercialVehicle {
+		return RuleResult{
+			Rule:    r.Name(),
+			Outcome: Pass,
+			Reason:  "not a commercial vehicle",
+		}
+	}
+
+	if application.ProposedLoanAmount > policy.MaxCommercialLoanAmount {
+		return RuleResult{
+			Rule:    r.Name(),
+			Outcome: Decline,
+			Reason:  fmt.Sprintf("commercial loan amount %d exceeds maximum %d", application.ProposedLoanAmount, policy.MaxCommercialLoanAmount),
+		}
+	}
+
+	if application.ProposedLoanTenure > policy.MaxCommercialTenureMonths {
+		return RuleResult{
+			Rule:    r.Name(),
+			Outcome: Decline,
+			Reason:  fmt.Sprintf("commercial loan tenure %d months exceeds maximum %d months", application.ProposedLoanTenure, policy.MaxCommercialTenureMonths),
+		}
+	}
+
+	return RuleResult{
+		Rule:    r.Name(),
+		Outcome: Pass,
+		Reason:  "commercial vehicle limits are within policy",
+	}
+}
+
+type TenureByVehicleTypeRule struct{}
+
+func (TenureByVehicleTypeRule) Name() string {
+	return "tenure_by_vehicle_type"
+}
+
+func (r TenureByVehicleTypeRule) Evaluate(policy Policy, application Application) RuleResult {
+	maxTenure, ok := policy.MaxTenureMonthsFor(application.VehicleType)
+	if !ok {
+		return RuleResult{
+			Rule:    r.Name(),
+			Outcome: Refer,
+			Reason:  fmt.Sprintf("no maximum tenure configured for vehicle type %q", application.VehicleType),
+		}
+	}
+
+	if application.ProposedLoanTenure > maxTenure {
+		return RuleResult{
+			Rule:    r.Name(),
+			Outcome: Decline,
+			Reason:  fmt.Sprintf("loan tenure %d months exceeds maximum %d months for vehicle type %q", application.ProposedLoanTenure, maxTenure, application.VehicleType),
+		}
+	}
+
+	return RuleResult{
+		Rule:    r.Name(),
+		Outcome: Pass,
+		Reason:  fmt.Sprintf("loan tenure %d months is within policy for vehicle type %q", application.ProposedLoanTenure, application.VehicleType),
+	}
+}
+
+type LoanAmountByBrandRule struct{}
+
+func (LoanAmountByBrandRule) Name() string {
+	return "loan_amount_by_brand"
+}
+
+func (r LoanAmountByBrandRule) Evaluate(policy Policy, application Application) RuleResult {
+	maxAmount, ok := policy.MaxLoanAmountFor(application.VehicleBrand)
+	if !ok {
+		return RuleResult{
+			Rule:    r.Name(),
+			Outcome: Pass,
+			Reason:  fmt.Sprintf("no loan amount limit configured for brand %q", application.VehicleBrand),
+		}
+	}
+
+	if application.ProposedLoanAmount > maxAmount {
+		return RuleResult{
+			Rule:    r.Name(),
+			Outcome: Decline,
+			Reason:  fmt.Sprintf("loan amount %d exceeds maximum %d for brand %q", application.ProposedLoanAmount, maxAmount, application.VehicleBrand),
+		}
+	}
+
+	return RuleResult{
+		Rule:    r.Name(),
+		Outcome: Pass,
+		Reason:  fmt.Sprintf("loan amount %d is within policy for brand %q", application.ProposedLoanAmount, application.VehicleBrand),
+	}
+}
+
+type LoanToValueRule struct{}
+
+func (LoanToValueRule) Name() string {
+	return "loan_to_value"
+}
+
+func (r LoanToValueRule) Evaluate(policy Policy, application Application) RuleResult {
+	if application.CollateralValue <= 0 {
+		return RuleResult{
+			Rule:    r.Name(),
+			Outcome: Refer,
+			Reason:  "collateral value is unknown and needs a manual appraisal",
+		}
+	}
+
+	if application.LoanToValue > policy.MaxLoanToValue {
+		return RuleResult{
+			Rule:    r.Name(),
+			Outcome: Decline,
+			Reason:  fmt.Sprintf("loan to value %.2f exceeds maximum %.2f", application.LoanToValue, policy.MaxLoanToValue),
+		}
+	}
+
+	if application.LoanToValue > policy.ReferLoanToValue {
+		return RuleResult{
+			Rule:    r.Name(),
+			Outcome: Refer,
+			Reason:  fmt.Sprintf("loan to value %.2f exceeds %.2f and needs manual review", application.LoanToValue, policy.ReferLoanToValue),
+		}
+	}
+
+	return RuleResult{
+		Rule:    r.Name(),
+		Outcome: Pass,
+		Reason:  fmt.Sprintf("loan to value %.2f is within policy", application.LoanToValue),
+	}
+}
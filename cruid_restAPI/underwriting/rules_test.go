@@ -0,0 +1,173 @@
+package underwriting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alphaloan/vehicle/amortization"
+)
+
+func testPolicy() Policy {
+	return Policy{
+		Version:                        3,
+		ReferInstallmentToIncomeRatio:  0.30,
+		MaxInstallmentToIncomeRatio:    0.40,
+		MaxVehicleAgeYears:             10,
+		ReferOdometer:                  100000,
+		MaxOdometer:                    150000,
+		MaxCommercialLoanAmount:        500000000,
+		MaxCommercialTenureMonths:      48,
+		CommercialInstallmentSurcharge: 0.10,
+		MaxTenureMonthsByVehicleType:   map[string]int{"car": 60, "motorcycle": 36},
+		MaxLoanAmountByBrand:           map[string]int{"yamaha": 60000000},
+		DefaultInterestMethod:          amortization.Flat,
+		ReferLoanToValue:               0.80,
+		MaxLoanToValue:                 1.00,
+	}
+}
+
+// testApplication passes every rule of testPolicy. Its installment is 1000 a
+// month, a tenth of the income.
+func testApplication() Application {
+	return Application{
+		MonthlyIncome:      10000,
+		ProposedLoanAmount: 12000,
+		ProposedLoanTenure: 12,
+		InterestMethod:     amortization.Flat,
+		ManufacturingYear:  2020,
+		VehicleOdometer:    50000,
+		VehicleType:        "car",
+		VehicleBrand:       "Toyota",
+		CollateralValue:    20000,
+		LoanToValue:        0.6,
+		EvaluatedAt:        time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+type ruleTest struct {
+	name   string
+	modify func(*Application)
+	want   string
+}
+
+func testRule(t *testing.T, rule Rule, tests []ruleTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			application := testApplication()
+			tt.modify(&application)
+
+			result := rule.Evaluate(testPolicy(), application)
+			if result.Rule != rule.Name() || result.Outcome != tt.want || result.Reason == "" {
+				t.Errorf("Evaluate() = %+v, want %s from %s with a reason", result, tt.want, rule.Name())
+			}
+		})
+	}
+}
+
+func TestInstallmentToIncomeRule(t *testing.T) {
+	testRule(t, InstallmentToIncomeRule{}, []ruleTest{
+		{"within policy", func(a *Application) {}, Pass},
+		{"above the refer ratio", func(a *Application) { a.MonthlyIncome = 3000 }, Refer},
+		{"above the maximum ratio", func(a *Application) { a.MonthlyIncome = 2000 }, Decline},
+		{"co-applicant income counts", func(a *Application) { a.MonthlyIncome, a.CoApplicantIncome = 2000, 8000 }, Pass},
+		{"commercial surcharge", func(a *Application) { a.MonthlyIncome, a.IsCommercialVehicle = 2600, true }, Decline},
+		{"no surcharge without a commercial vehicle", func(a *Application) { a.MonthlyIncome = 2600 }, Refer},
+		{"unknown income", func(a *Application) { a.MonthlyIncome = 0 }, Refer},
+		{"no tenure", func(a *Application) { a.ProposedLoanTenure = 0 }, Decline},
+	})
+}
+
+func TestVehicleAgeRule(t *testing.T) {
+	testRule(t, VehicleAgeRule{}, []ruleTest{
+		{"within policy", func(a *Application) {}, Pass},
+		{"at the maximum age", func(a *Application) { a.ManufacturingYear = 2015 }, Pass},
+		{"older than the maximum", func(a *Application) { a.ManufacturingYear = 2014 }, Decline},
+		{"built in the future", func(a *Application) { a.ManufacturingYear = 2026 }, Decline},
+	})
+}
+
+func TestOdometerRule(t *testing.T) {
+	testRule(t, OdometerRule{}, []ruleTest{
+		{"within policy", func(a *Application) {}, Pass},
+		{"at the refer threshold", func(a *Application) { a.VehicleOdometer = 100000 }, Pass},
+		{"above the refer threshold", func(a *Application) { a.VehicleOdometer = 100001 }, Refer},
+		{"above the maximum", func(a *Application) { a.VehicleOdometer = 150001 }, Decline},
+	})
+}
+
+func TestCommercialVehicleRule(t *testing.T) {
+	testRule(t, CommercialVehicleRule{}, []ruleTest{
+		{"not commercial", func(a *Application) { a.ProposedLoanAmount = 900000000 }, Pass},
+		{"within the commercial limits", func(a *Application) { a.IsCommercialVehicle = true }, Pass},
+		{"commercial amount above the maximum", func(a *Application) {
+			a.IsCommercialVehicle, a.ProposedLoanAmount = true, 500000001
+		}, Decline},
+		{"commercial tenure above the maximum", func(a *Application) {
+			a.IsCommercialVehicle, a.ProposedLoanTenure = true, 60
+		}, Decline},
+	})
+}
+
+func TestTenureByVehicleTypeRule(t *testing.T) {
+	testRule(t, TenureByVehicleTypeRule{}, []ruleTest{
+		{"within policy", func(a *Application) {}, Pass},
+		{"vehicle type in another case", func(a *Application) { a.VehicleType, a.ProposedLoanTenure = "Motorcycle", 36 }, Pass},
+		{"above the maximum for the type", func(a *Application) { a.VehicleType, a.ProposedLoanTenure = "motorcycle", 48 }, Decline},
+		{"type without a maximum", func(a *Application) { a.VehicleType = "truck" }, Refer},
+	})
+}
+
+func TestLoanAmountByBrandRule(t *testing.T) {
+	testRule(t, LoanAmountByBrandRule{}, []ruleTest{
+		{"brand without a limit", func(a *Application) { a.ProposedLoanAmount = 900000000 }, Pass},
+		{"within the brand limit", func(a *Application) { a.VehicleBrand = "YAMAHA" }, Pass},
+		{"above the brand limit", func(a *Application) { a.VehicleBrand, a.ProposedLoanAmount = "Yamaha", 60000001 }, Decline},
+	})
+}
+
+func TestLoanToValueRule(t *testing.T) {
+	testRule(t, LoanToValueRule{}, []ruleTest{
+		{"within policy", func(a *Application) {}, Pass},
+		{"above the refer ratio", func(a *Application) { a.LoanToValue = 0.9 }, Refer},
+		{"above the maximum", func(a *Application) { a.LoanToValue = 1.2 }, Decline},
+		{"unknown collateral value", func(a *Application) { a.CollateralValue = 0 }, Refer},
+	})
+}
+
+func TestEngineEvaluate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Application)
+		want   string
+	}{
+		{"every rule passes", func(a *Application) {}, Approve},
+		{"a rule refers", func(a *Application) { a.VehicleOdometer = 120000 }, Refer},
+		{"a decline outweighs a referral", func(a *Application) { a.VehicleOdometer, a.LoanToValue = 120000, 1.2 }, Decline},
+	}
+
+	engine := NewEngine(StaticPolicy(Policy{Version: 1}), DefaultRules()...)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			application := testApplication()
+			tt.modify(&application)
+
+			evaluation := engine.Evaluate(testPolicy(), application)
+
+			if evaluation.Recommendation != tt.want {
+				t.Errorf("recommendation = %s, want %s: %+v", evaluation.Recommendation, tt.want, evaluation.Results)
+			}
+
+			// The policy passed in is used, not the current one of the engine.
+			if evaluation.PolicyVersion != 3 {
+				t.Errorf("policy version = %d, want 3", evaluation.PolicyVersion)
+			}
+
+			if len(evaluation.Results) != len(DefaultRules()) {
+				t.Errorf("got %d rule results, want one per rule", len(evaluation.Results))
+			}
+		})
+	}
+}
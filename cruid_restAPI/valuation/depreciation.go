@@ -0,0 +1,96 @@
+package valuation
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/alphaloan/vehicle/amortization"
+	"gopkg.in/yaml.v3"
+)
+
+// DepreciationModel values a used vehicle from its price when new. Age is
+// depreciated on a declining balance, then the value is adjusted for how far
+// the odometer is above or below the mileage expected for that age.
+type DepreciationModel struct {
+	AnnualDepreciationRate      float64 `yaml:"annual_depreciation_rate"`
+	AnnualMileageAllowanceKm    int     `yaml:"annual_mileage_allowance_km"`
+	MileageAdjustmentPer10000Km float64 `yaml:"mileage_adjustment_per_10000_km"`
+	MaxMileageAdjustment        float64 `yaml:"max_mileage_adjustment"`
+	ResidualValueFloor          float64 `yaml:"residual_value_floor"`
+}
+
+type Valuation struct {
+	NewPrice       float64
+	AgeYears       int
+	AgeFactor      float64
+	MileageFactor  float64
+	EstimatedValue float64
+}
+
+func LoadDepreciationModel(path string) (DepreciationModel, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return DepreciationModel{}, err
+	}
+
+	var model DepreciationModel
+	if err := yaml.Unmarshal(content, &model); err != nil {
+		return DepreciationModel{}, fmt.Errorf("failed to parse depreciation model %s: %w", path, err)
+	}
+
+	if model.AnnualDepreciationRate < 0 || model.AnnualDepreciationRate >= 1 {
+		return DepreciationModel{}, fmt.Errorf("invalid depreciation model %s: annual_depreciation_rate must be between 0 and 1", path)
+	}
+
+	if model.AnnualMileageAllowanceKm <= 0 {
+		return DepreciationModel{}, fmt.Errorf("invalid depreciation model %s: annual_mileage_allowance_km must be greater than zero", path)
+	}
+
+	if model.MileageAdjustmentPer10000Km < 0 || model.MaxMileageAdjustment < 0 || model.MaxMileageAdjustment >= 1 {
+		return DepreciationModel{}, fmt.Errorf("invalid depreciation model %s: mileage adjustments must be between 0 and 1", path)
+	}
+
+	if model.ResidualValueFloor < 0 || model.ResidualValueFloor > 1 {
+		return DepreciationModel{}, fmt.Errorf("invalid depreciation model %s: residual_value_floor must be between 0 and 1", path)
+	}
+
+	return model, nil
+}
+
+func (m DepreciationModel) Estimate(price ReferencePrice, manufacturingYear int, odometer int, asOf time.Time) Valuation {
+	ageYears := asOf.Year() - manufacturingYear
+	if ageYears < 0 {
+		ageYears = 0
+	}
+
+	ageFactor := math.Pow(1-m.AnnualDepreciationRate, float64(ageYears))
+
+	// A vehicle in its first year is still expected to have some mileage.
+	expectedOdometer := m.AnnualMileageAllowanceKm * max(ageYears, 1)
+	excessKm := float64(odometer - expectedOdometer)
+
+	mileageAdjustment := -excessKm / 10000 * m.MileageAdjustmentPer10000Km
+	mileageAdjustment = math.Max(-m.MaxMileageAdjustment, math.Min(m.MaxMileageAdjustment, mileageAdjustment))
+	mileageFactor := 1 + mileageAdjustment
+
+	estimatedValue := price.NewPrice * ageFactor * mileageFactor
+	estimatedValue = math.Max(estimatedValue, price.NewPrice*m.ResidualValueFloor)
+
+	return Valuation{
+		NewPrice:       price.NewPrice,
+		AgeYears:       ageYears,
+		AgeFactor:      ageFactor,
+		MileageFactor:  mileageFactor,
+		EstimatedValue: amortization.Round(estimatedValue),
+	}
+}
+
+func LoanToValue(loanAmount int, collateralValue float64) float64 {
+	if collateralValue <= 0 {
+		return 0
+	}
+
+	return math.Round(float64(loanAmount)/collateralValue*10000) / 10000
+}
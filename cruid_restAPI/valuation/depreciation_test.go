@@ -0,0 +1,67 @@
+package valuation
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func testModel() DepreciationModel {
+	return DepreciationModel{
+		AnnualDepreciationRate:      0.15,
+		AnnualMileageAllowanceKm:    15000,
+		MileageAdjustmentPer10000Km: 0.05,
+		MaxMileageAdjustment:        0.10,
+		ResidualValueFloor:          0.20,
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	price := ReferencePrice{VehicleBrand: "toyota", VehicleModel: "avanza", VehicleType: "car", NewPrice: 100000}
+	asOf := time.Date(2025, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name              string
+		manufacturingYear int
+		odometer          int
+		wantAgeYears      int
+		wantMileageFactor float64
+		wantValue         float64
+	}{
+		{"new vehicle with the expected mileage", 2025, 15000, 0, 1, 100000},
+		{"expected mileage for its age", 2022, 45000, 3, 1, 61412.5},
+		{"mileage above the allowance", 2022, 55000, 3, 0.95, 58341.87},
+		{"mileage adjustment capped below", 2022, 145000, 3, 0.9, 55271.25},
+		{"mileage adjustment capped above", 2022, 0, 3, 1.1, 67553.75},
+		{"residual value floor", 2005, 300000, 20, 1, 20000},
+		{"manufactured in a future year", 2027, 15000, 0, 1, 100000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valuation := testModel().Estimate(price, tt.manufacturingYear, tt.odometer, asOf)
+
+			if valuation.NewPrice != price.NewPrice || valuation.AgeYears != tt.wantAgeYears {
+				t.Errorf("Estimate() = new price %v, age %d, want %v, %d", valuation.NewPrice, valuation.AgeYears, price.NewPrice, tt.wantAgeYears)
+			}
+
+			if math.Abs(valuation.MileageFactor-tt.wantMileageFactor) > 1e-9 {
+				t.Errorf("mileage factor = %v, want %v", valuation.MileageFactor, tt.wantMileageFactor)
+			}
+
+			if valuation.EstimatedValue != tt.wantValue {
+				t.Errorf("estimated value = %v, want %v", valuation.EstimatedValue, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestLoanToValue(t *testing.T) {
+	if got := LoanToValue(80000, 100000); got != 0.8 {
+		t.Errorf("LoanToValue(80000, 100000) = %v, want 0.8", got)
+	}
+
+	if got := LoanToValue(80000, 0); got != 0 {
+		t.Errorf("LoanToValue without a collateral value = %v, want 0", got)
+	}
+}
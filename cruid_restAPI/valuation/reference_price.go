@@ -0,0 +1,84 @@
+package valuation
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type ReferencePrice struct {
+	VehicleBrand string
+	VehicleModel string
+	VehicleType  string
+	NewPrice     float64
+}
+
+var referencePriceColumns = []string{"vehicle_brand", "vehicle_model", "vehicle_type", "new_price"}
+
+// NormalizeKey is how brands and models are compared against the reference
+// price table, so "Toyota" and " toyota" find the same row.
+func NormalizeKey(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// ParseReferencePricesCSV reads a price list with the header
+// vehicle_brand,vehicle_model,vehicle_type,new_price. Errors carry the line
+// number so a bad row in a large import is easy to find.
+func ParseReferencePricesCSV(r io.Reader) ([]ReferencePrice, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("reference price csv is empty")
+		}
+		return nil, err
+	}
+
+	if len(header) != len(referencePriceColumns) {
+		return nil, fmt.Errorf("reference price csv header must be %s", strings.Join(referencePriceColumns, ","))
+	}
+
+	for i, column := range referencePriceColumns {
+		if NormalizeKey(header[i]) != column {
+			return nil, fmt.Errorf("reference price csv header must be %s", strings.Join(referencePriceColumns, ","))
+		}
+	}
+
+	var prices []ReferencePrice
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		newPrice, err := strconv.ParseFloat(strings.TrimSpace(record[3]), 64)
+		if err != nil || newPrice <= 0 {
+			return nil, fmt.Errorf("line %d: new_price must be a positive number", line)
+		}
+
+		price := ReferencePrice{
+			VehicleBrand: NormalizeKey(record[0]),
+			VehicleModel: NormalizeKey(record[1]),
+			VehicleType:  NormalizeKey(record[2]),
+			NewPrice:     newPrice,
+		}
+
+		if price.VehicleBrand == "" || price.VehicleModel == "" || price.VehicleType == "" {
+			return nil, fmt.Errorf("line %d: vehicle_brand, vehicle_model and vehicle_type are required", line)
+		}
+
+		prices = append(prices, price)
+	}
+
+	return prices, nil
+}
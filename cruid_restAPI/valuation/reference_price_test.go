@@ -0,0 +1,55 @@
+package valuation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseReferencePricesCSV(t *testing.T) {
+	content := "Vehicle_Brand, vehicle_model, vehicle_type, new_price\n" +
+		" Toyota , Avanza, Car, 250000000\n" +
+		"Honda,\"Vario\n125\",motorcycle,25000000.50\n"
+
+	prices, err := ParseReferencePricesCSV(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("ParseReferencePricesCSV() = %v", err)
+	}
+
+	want := []ReferencePrice{
+		{VehicleBrand: "toyota", VehicleModel: "avanza", VehicleType: "car", NewPrice: 250000000},
+		{VehicleBrand: "honda", VehicleModel: "vario\n125", VehicleType: "motorcycle", NewPrice: 25000000.50},
+	}
+	if !reflect.DeepEqual(prices, want) {
+		t.Errorf("ParseReferencePricesCSV() = %+v, want %+v", prices, want)
+	}
+}
+
+func TestParseReferencePricesCSVErrors(t *testing.T) {
+	header := "vehicle_brand,vehicle_model,vehicle_type,new_price\n"
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"empty file", "", "reference price csv is empty"},
+		{"missing column", "vehicle_brand,vehicle_model,new_price\n", "header must be vehicle_brand,vehicle_model,vehicle_type,new_price"},
+		{"columns out of order", "vehicle_model,vehicle_brand,vehicle_type,new_price\n", "header must be"},
+		{"price not a number", header + "toyota,avanza,car,250000000\nhonda,vario,motorcycle,cheap\n", "line 3: new_price must be a positive number"},
+		{"price not positive", header + "toyota,avanza,car,0\n", "line 2: new_price must be a positive number"},
+		{"missing brand", header + "toyota,avanza,car,250000000\n,vario,motorcycle,25000000\n", "line 3: vehicle_brand, vehicle_model and vehicle_type are required"},
+		// The quoted model spans two lines, so the bad row is on line 4.
+		{"line after a multi-line field", header + "honda,\"vario\n125\",motorcycle,25000000\nyamaha,nmax,motorcycle,-1\n", "line 4:"},
+		{"wrong number of fields", header + "toyota,avanza,250000000\n", "wrong number of fields"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseReferencePricesCSV(strings.NewReader(tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseReferencePricesCSV() = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}